@@ -1,9 +1,12 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/rokuosan/github-issue-cms/cmd/cli/subcommand"
 	"github.com/spf13/cobra"
@@ -38,6 +41,8 @@ with frontmatter and downloads attached images.`,
 
 	// Register subcommands.
 	rootCmd.AddCommand(subcommand.NewGenerateCommand())
+	rootCmd.AddCommand(subcommand.NewFetchCommand())
+	rootCmd.AddCommand(subcommand.NewServeCommand())
 	rootCmd.AddCommand(subcommand.NewInitCommand())
 	rootCmd.AddCommand(subcommand.NewMigrateCommand())
 	rootCmd.AddCommand(subcommand.NewVersionCommand(&Version))
@@ -46,10 +51,15 @@ with frontmatter and downloads attached images.`,
 	return rootCmd
 }
 
-// Execute runs the CLI application.
+// Execute runs the CLI application. Interrupt and termination signals cancel
+// the command's context so that long-running commands shut down cleanly.
 func Execute() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	rootCmd := NewRootCommand()
-	if err := rootCmd.Execute(); err != nil {
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
+		stop()
 		_, _ = fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
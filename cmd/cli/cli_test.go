@@ -18,13 +18,17 @@ func TestNewRootCommand(t *testing.T) {
 
 	// Ensure subcommands are registered.
 	commands := cmd.Commands()
-	assert.GreaterOrEqual(t, len(commands), 5, "Should have at least 5 subcommands")
+	assert.GreaterOrEqual(t, len(commands), 7, "Should have at least 7 subcommands")
 
-	var hasGenerate, hasInit, hasMigrate, hasVersion, hasOGImage bool
+	var hasGenerate, hasFetch, hasServe, hasInit, hasMigrate, hasVersion, hasOGImage bool
 	for _, subCmd := range commands {
 		switch subCmd.Use {
 		case "generate":
 			hasGenerate = true
+		case "fetch":
+			hasFetch = true
+		case "serve":
+			hasServe = true
 		case "init":
 			hasInit = true
 		case "migrate":
@@ -37,6 +41,8 @@ func TestNewRootCommand(t *testing.T) {
 	}
 
 	assert.True(t, hasGenerate, "Should have 'generate' subcommand")
+	assert.True(t, hasFetch, "Should have 'fetch' subcommand")
+	assert.True(t, hasServe, "Should have 'serve' subcommand")
 	assert.True(t, hasInit, "Should have 'init' subcommand")
 	assert.True(t, hasMigrate, "Should have 'migrate' subcommand")
 	assert.True(t, hasVersion, "Should have 'version' subcommand")
@@ -0,0 +1,118 @@
+package subcommand
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/rokuosan/github-issue-cms/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+// fetchOptions holds the flag values of the fetch subcommand.
+type fetchOptions struct {
+	githubToken     string
+	attachmentToken string
+	output          string
+}
+
+// NewFetchCommand creates the fetch subcommand.
+func NewFetchCommand() *cobra.Command {
+	var opts fetchOptions
+
+	cmd := &cobra.Command{
+		Use:     "fetch",
+		Aliases: []string{"snapshot"},
+		Short:   "Record issues and images into an offline snapshot",
+		Long: `Record issues and images into an offline snapshot.
+
+This command reads every issue a full generate run would read, together with
+their comments, rendered HTML, events, author permissions and images, and
+writes them into a snapshot directory instead of generating articles.
+
+Pass the directory to "generate --snapshot" to reproduce the articles without
+a token or network access, for example to debug output differences or to
+write regression tests for site content. Generate from a snapshot with the
+configuration it was recorded with: issues and images that the configuration
+did not select at recording time are not in the snapshot.
+
+The snapshot directory must not exist or be empty.
+
+Examples:
+  # Record a snapshot
+  github-issue-cms fetch --token YOUR_GITHUB_TOKEN --output snapshot
+
+  # Generate offline from it
+  github-issue-cms generate --snapshot snapshot --full`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFetch(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.githubToken, "token", "t", "", "GitHub API Token (defaults to a GitHub App, GIC_GITHUB_TOKEN, GITHUB_TOKEN, github.tokenFile or the gh CLI)")
+	cmd.Flags().StringVar(&opts.attachmentToken, "attachment-token", "", "Token used only to download images, e.g. a classic PAT for private attachments")
+	cmd.Flags().StringVarP(&opts.output, "output", "o", "", "Directory to write the snapshot to")
+	_ = cmd.MarkFlagRequired("output")
+
+	return cmd
+}
+
+func runFetch(cmd *cobra.Command, opts fetchOptions) error {
+	if err := ensureEmptyDirectory(opts.output); err != nil {
+		return err
+	}
+
+	conf, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !conf.GitHub.HasSources() && (conf.GitHub.Username == "" || conf.GitHub.Repository == "") {
+		return fmt.Errorf("please set username and repository in gic.config.yaml; run 'github-issue-cms init' to create a config file")
+	}
+
+	tokens, attachmentTokens, err := newTokenSources(conf, cmd.ErrOrStderr(), opts.githubToken, opts.attachmentToken)
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
+	}
+	snapshot := core.NewSnapshot(opts.output)
+	recorder, err := core.NewSnapshotRecorder(conf, tokens, attachmentTokens, snapshot, slog.Default())
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
+	}
+
+	slog.Info("Recording snapshot...")
+	_, err = generateAllSources(cmd, recorder, conf)
+	// Save what was recorded even after a partial failure.
+	if saveErr := snapshot.Save(); saveErr != nil {
+		err = errors.Join(err, saveErr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to record snapshot: %w", err)
+	}
+
+	issues := 0
+	for _, repo := range snapshot.Repositories {
+		issues += len(repo.Issues)
+	}
+	_, err = fmt.Fprintf(cmd.OutOrStdout(), "Recorded %d issues and %d images in %s\n", issues, len(snapshot.Assets), opts.output)
+	return err
+}
+
+// ensureEmptyDirectory fails when path exists and is not an empty directory,
+// so that a snapshot never mixes with files of an earlier one.
+func ensureEmptyDirectory(path string) error {
+	entries, err := os.ReadDir(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("snapshot directory %s: %w", path, err)
+	}
+	if len(entries) > 0 {
+		return fmt.Errorf("snapshot directory %s is not empty", path)
+	}
+	return nil
+}
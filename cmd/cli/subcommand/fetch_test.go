@@ -0,0 +1,45 @@
+package subcommand
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFetchCommand(t *testing.T) {
+	cmd := NewFetchCommand()
+
+	assert.Equal(t, "fetch", cmd.Use)
+	assert.Contains(t, cmd.Aliases, "snapshot")
+
+	tokenFlag := cmd.Flags().Lookup("token")
+	require.NotNil(t, tokenFlag)
+	assert.Equal(t, "t", tokenFlag.Shorthand)
+	assert.NotNil(t, cmd.Flags().Lookup("attachment-token"), "--attachment-token flag should exist")
+
+	outputFlag := cmd.Flags().Lookup("output")
+	require.NotNil(t, outputFlag)
+	assert.Equal(t, "o", outputFlag.Shorthand)
+	assert.Contains(t, outputFlag.Annotations, "cobra_annotation_bash_completion_one_required_flag")
+}
+
+func TestGenerateCommand_SnapshotExcludesToken(t *testing.T) {
+	cmd := NewGenerateCommand()
+	assert.NotNil(t, cmd.Flags().Lookup("snapshot"), "--snapshot flag should exist")
+
+	cmd.SetArgs([]string{"--token", "test-token", "--snapshot", "snapshot"})
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "none of the others can be")
+}
+
+func TestEnsureEmptyDirectory(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, ensureEmptyDirectory(filepath.Join(dir, "missing")))
+	assert.NoError(t, ensureEmptyDirectory(dir))
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "snapshot.json"), []byte("{}"), 0o644))
+	assert.ErrorContains(t, ensureEmptyDirectory(dir), "is not empty")
+}
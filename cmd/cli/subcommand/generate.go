@@ -1,7 +1,9 @@
 package subcommand
 
 import (
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
 	"os"
 	"path/filepath"
@@ -14,12 +16,25 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// generateOptions holds the flag values of the generate subcommand.
+type generateOptions struct {
+	githubToken     string
+	attachmentToken string
+	withOGImage     bool
+	full            bool
+	prune           bool
+	dryRun          bool
+	issue           int
+	repository      string
+	eventPath       string
+	snapshot        string
+	watch           bool
+	interval        time.Duration
+}
+
 // NewGenerateCommand creates the generate subcommand.
 func NewGenerateCommand() *cobra.Command {
-	var (
-		githubToken string
-		withOGImage bool
-	)
+	var opts generateOptions
 
 	cmd := &cobra.Command{
 		Use:   "generate",
@@ -30,10 +45,57 @@ This command will get issues from GitHub and create articles from them.
 The articles will be saved in the configured output directory structure
 specified in gic.config.yaml.
 
+Runs are incremental: the sync state stored in the state directory
+(".gic" by default) records which issue versions were already written, and
+only issues updated since the last successful run are fetched and saved.
+Use --full to ignore the recorded state and rebuild every article.
+
+Every run also updates a manifest in the state directory that maps each
+issue to the files generated for it. With --prune, files whose issue was
+deleted, transferred, or no longer matches the configured labels are removed
+after generation. Combine --prune with --dry-run to only list them.
+
+Without --token, the command authenticates as a GitHub App configured in
+github.app or the GIC_APP_ID and GIC_APP_PRIVATE_KEY environment variables,
+minting and refreshing installation tokens automatically. Without an app, the
+token is read from the GIC_GITHUB_TOKEN or GITHUB_TOKEN environment variable
+or the file in github.tokenFile, and finally from the gh CLI. The source that
+was used is printed to stderr.
+Installation tokens cannot download images attached to issues in private
+repositories; pass a classic personal access token with --attachment-token
+to use it for image downloads only.
+
+With --issue or --from-event, only a single issue is fetched and written.
+--from-event reads a GitHub Actions "issues" event payload (the file in
+$GITHUB_EVENT_PATH). When the issue was deleted, transferred, or no longer
+has the configured labels, its generated files are removed instead.
+When github.sources lists several repositories, select the repository of
+--issue with --repository; --from-event uses the repository of the event.
+
+With --snapshot, issues and images are read from a snapshot directory
+written by the fetch command instead of GitHub, so no token or network
+access is needed. Generating from a snapshot with the configuration it was
+recorded with reproduces the same articles. publishDate and expiryDate are
+compared with the time the snapshot was recorded, not the current time.
+
+With --watch, the command keeps running after the first run and checks for
+changed issues every --interval. Each check is a conditional request that
+does not count against the rate limit when nothing changed; when something
+did, an incremental run regenerates the changed articles. Every issue of the
+repository is checked, so an issue losing a configured label counts as a
+change; combine --watch with --prune to remove the articles of such issues.
+Deleting an issue does not change any other issue, so a deletion is only
+noticed together with the next change. Use the serve command to remove the
+articles of deleted issues right away. Press Ctrl+C to stop watching.
+
 Examples:
   # Generate articles with GitHub token
   github-issue-cms generate --token YOUR_GITHUB_TOKEN
 
+  # Generate articles with the token in GIC_GITHUB_TOKEN, GITHUB_TOKEN,
+  # github.tokenFile or the gh CLI
+  github-issue-cms generate
+
   # Generate with info logging
   github-issue-cms -v generate --token YOUR_GITHUB_TOKEN
 
@@ -41,72 +103,254 @@ Examples:
   github-issue-cms -vv generate --token YOUR_GITHUB_TOKEN
 
   # Generate articles with OGP images
-  github-issue-cms generate --token YOUR_GITHUB_TOKEN --with-ogimage`,
+  github-issue-cms generate --token YOUR_GITHUB_TOKEN --with-ogimage
+
+  # Rebuild every article regardless of the recorded sync state
+  github-issue-cms generate --token YOUR_GITHUB_TOKEN --full
+
+  # List the files --prune would remove without touching anything
+  github-issue-cms generate --token YOUR_GITHUB_TOKEN --prune --dry-run
+
+  # Generate articles and remove outputs of vanished issues
+  github-issue-cms generate --token YOUR_GITHUB_TOKEN --prune
+
+  # Authenticate as a GitHub App configured in gic.config.yaml
+  GIC_APP_PRIVATE_KEY="$(cat app.pem)" github-issue-cms generate
+
+  # Regenerate a single issue
+  github-issue-cms generate --token YOUR_GITHUB_TOKEN --issue 42
+
+  # Regenerate the issue that triggered a GitHub Actions workflow
+  github-issue-cms generate --token YOUR_GITHUB_TOKEN --from-event "$GITHUB_EVENT_PATH"
+
+  # Generate offline from a snapshot written by the fetch command
+  github-issue-cms generate --snapshot snapshot --full
+
+  # Keep running and regenerate changed articles every 5 minutes
+  github-issue-cms generate --token YOUR_GITHUB_TOKEN --watch --interval 5m`,
 
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runGenerate(cmd, githubToken, withOGImage)
+			if cmd.Flags().Changed("interval") && !opts.watch {
+				return fmt.Errorf("--interval can only be used together with --watch")
+			}
+			return runGenerate(cmd, opts)
 		},
 	}
 
 	// Define flags.
-	cmd.Flags().StringVarP(&githubToken, "token", "t", "", "GitHub API Token (required)")
-	cmd.Flags().BoolVar(&withOGImage, "with-ogimage", false, "Generate OGP images alongside articles")
-	_ = cmd.MarkFlagRequired("token")
+	cmd.Flags().StringVarP(&opts.githubToken, "token", "t", "", "GitHub API Token (defaults to a GitHub App, GIC_GITHUB_TOKEN, GITHUB_TOKEN, github.tokenFile or the gh CLI)")
+	cmd.Flags().StringVar(&opts.attachmentToken, "attachment-token", "", "Token used only to download images, e.g. a classic PAT for private attachments")
+	cmd.Flags().BoolVar(&opts.withOGImage, "with-ogimage", false, "Generate OGP images alongside articles")
+	cmd.Flags().BoolVar(&opts.full, "full", false, "Ignore the sync state and regenerate every article")
+	cmd.Flags().BoolVar(&opts.prune, "prune", false, "Remove generated files that are no longer backed by a matching issue")
+	cmd.Flags().BoolVar(&opts.dryRun, "dry-run", false, "With --prune, only list the files that would be removed")
+	cmd.Flags().IntVar(&opts.issue, "issue", 0, "Only generate (or remove) the article of this issue number")
+	cmd.Flags().StringVar(&opts.repository, "repository", "", "With --issue, the github.sources repository (owner/name) the issue belongs to")
+	cmd.Flags().StringVar(&opts.eventPath, "from-event", "", "Only process the issue in this GitHub issues event payload")
+	cmd.Flags().StringVar(&opts.snapshot, "snapshot", "", "Read issues and images from a snapshot directory written by the fetch command instead of GitHub")
+	cmd.Flags().BoolVar(&opts.watch, "watch", false, "Keep running and regenerate articles whose issues changed")
+	cmd.Flags().DurationVar(&opts.interval, "interval", 5*time.Minute, "With --watch, how often to check for changed issues")
+	cmd.MarkFlagsMutuallyExclusive("issue", "from-event")
+	cmd.MarkFlagsMutuallyExclusive("issue", "prune")
+	cmd.MarkFlagsMutuallyExclusive("from-event", "prune")
+	cmd.MarkFlagsMutuallyExclusive("snapshot", "token")
+	cmd.MarkFlagsMutuallyExclusive("watch", "issue")
+	cmd.MarkFlagsMutuallyExclusive("watch", "from-event")
+	cmd.MarkFlagsMutuallyExclusive("watch", "dry-run")
+	cmd.MarkFlagsMutuallyExclusive("watch", "snapshot")
 
 	return cmd
 }
 
-func runGenerate(cmd *cobra.Command, githubToken string, withOGImage bool) error {
+// newGenerator creates the article generator. With --snapshot, it reads from
+// the snapshot without network access.
+func newGenerator(conf config.Config, output io.Writer, opts generateOptions) (*core.ArticleGenerator, error) {
+	if opts.snapshot != "" {
+		snapshot, err := core.LoadSnapshot(opts.snapshot)
+		if err != nil {
+			return nil, err
+		}
+		slog.Info("Generating from snapshot", "path", opts.snapshot, "recorded", snapshot.CreatedAt)
+		return core.NewArticleGeneratorFromSnapshot(conf, snapshot, slog.Default()), nil
+	}
+
+	tokens, attachmentTokens, err := newTokenSources(conf, output, opts.githubToken, opts.attachmentToken)
+	if err != nil {
+		return nil, err
+	}
+	return core.NewArticleGeneratorWithTokenSource(conf, tokens, attachmentTokens, slog.Default())
+}
+
+// newTokenSources returns the tokens to call the API with and, when
+// attachmentToken is set, the tokens to download images with. The API token
+// is taken from, in order: githubToken, the configured GitHub App, the
+// GIC_GITHUB_TOKEN and GITHUB_TOKEN environment variables, github.tokenFile,
+// and the gh CLI configuration. A configured app comes before the environment
+// so that an ambient GITHUB_TOKEN, as in GitHub Actions, does not replace it.
+// The chosen source is written to output; the token itself never is.
+func newTokenSources(conf config.Config, output io.Writer, githubToken, attachmentToken string) (core.TokenSource, core.TokenSource, error) {
+	var attachmentTokens core.TokenSource
+	if attachmentToken != "" {
+		attachmentTokens = core.StaticTokenSource(attachmentToken)
+	}
+
+	if githubToken != "" {
+		return core.StaticTokenSource(githubToken), attachmentTokens, reportTokenSource(output, "the --token flag")
+	}
+
+	creds, err := core.LoadGitHubAppCredentials(conf, os.Getenv)
+	if err != nil {
+		return nil, nil, err
+	}
+	if creds != nil {
+		tokens, err := core.NewGitHubAppTokenSource(*creds, conf, slog.Default())
+		if err != nil {
+			return nil, nil, err
+		}
+		return tokens, attachmentTokens, reportTokenSource(output, fmt.Sprintf("GitHub App %d", creds.AppID))
+	}
+
+	token, source, err := core.LoadGitHubToken(conf, os.Getenv)
+	if err != nil {
+		return nil, nil, err
+	}
+	if token == "" {
+		token, source, err = core.LoadGitHubCLIToken(conf, os.Getenv)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+	if token == "" {
+		return nil, nil, fmt.Errorf("a GitHub token is required; pass --token, configure a GitHub App in github.app, set %s or %s, set github.tokenFile, or log in with 'gh auth login'", core.EnvGICGitHubToken, core.EnvGitHubToken)
+	}
+	return core.StaticTokenSource(token), attachmentTokens, reportTokenSource(output, source)
+}
+
+// reportTokenSource tells the user where the GitHub credentials came from.
+func reportTokenSource(output io.Writer, source string) error {
+	_, err := fmt.Fprintf(output, "Authenticating with %s\n", source)
+	return err
+}
+
+func runGenerate(cmd *cobra.Command, opts generateOptions) error {
+	if opts.dryRun && !opts.prune {
+		return fmt.Errorf("--dry-run can only be used together with --prune")
+	}
+	if opts.watch && opts.interval <= 0 {
+		return fmt.Errorf("--interval must be positive")
+	}
+
 	// Load configuration.
 	conf, err := config.Get()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
 	}
-	if conf.GitHub.Username == "" || conf.GitHub.Repository == "" {
+	if !conf.GitHub.HasSources() && (conf.GitHub.Username == "" || conf.GitHub.Repository == "") {
 		return fmt.Errorf("please set username and repository in gic.config.yaml; run 'github-issue-cms init' to create a config file")
 	}
+	if opts.repository != "" && opts.issue == 0 {
+		return fmt.Errorf("--repository can only be used together with --issue")
+	}
 
-	url := conf.GitHub.RepositoryURL()
-	slog.Info("Target Repository: " + url)
+	for _, source := range conf.GitHub.SourceList() {
+		sourceConf := conf.ForSource(source)
+		slog.Info("Target Repository: " + sourceConf.GitHub.RepositoryURL())
+	}
 
 	// Create the article generator.
-	generator, err := core.NewArticleGeneratorWithLogger(conf, githubToken, slog.Default())
+	generator, err := newGenerator(conf, cmd.ErrOrStderr(), opts)
 	if err != nil {
 		return fmt.Errorf("failed to create generator: %w", err)
 	}
 
+	// Load the manifest of previously generated files.
+	manifestPath := core.ManifestPath(conf)
+	manifest, err := core.LoadManifest(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+	generator.SetManifest(manifest)
+
+	if opts.dryRun {
+		plan, err := generator.Prune(cmd.Context(), true)
+		if err != nil {
+			return fmt.Errorf("failed to plan pruning: %w", err)
+		}
+		return writePrunePlan(cmd.OutOrStdout(), plan)
+	}
+
+	// Load the sync state unless a full rebuild was requested.
+	statePath := core.SyncStatePath(conf)
+	state := core.NewSyncState()
+	if opts.full {
+		slog.Info("Full sync requested (--full)")
+	} else {
+		state, err = core.LoadSyncState(statePath)
+		if err != nil {
+			return fmt.Errorf("failed to load sync state: %w", err)
+		}
+	}
+	generator.SetSyncState(state)
+
 	// Set up OGP image generation hook if requested.
 	var ogpOK, ogpFail int
-	if withOGImage {
+	if opts.withOGImage {
 		renderer, err := ogimage.NewRenderer("")
 		if err != nil {
 			return fmt.Errorf("failed to create OGP renderer: %w", err)
 		}
 		defer renderer.Close()
-		generator.SetOnArticleSaved(func(article *core.Article) error {
+		generator.SetOnArticleSaved(func(article *core.Article, output *core.ArticleOutput) error {
 			if err := renderer.Open(cmd.Context()); err != nil {
 				ogpFail++
 				return fmt.Errorf("open OGP renderer: %w", err)
 			}
-			err := generateOGPForArticle(cmd, conf, renderer, article)
+			path, err := generateOGPForArticle(cmd, articleConfig(conf, article), renderer, article, output.ArticlePath)
 			if err != nil {
 				ogpFail++
 				return err
 			}
+			output.OGPPath = path
 			ogpOK++
 			return nil
 		})
 		slog.Info("OGP image generation enabled (--with-ogimage)")
 	}
 
+	if opts.watch {
+		return watchIssues(cmd, generator, conf, opts, func() error {
+			return errors.Join(state.Save(statePath), manifest.Save(manifestPath))
+		})
+	}
+
 	// Generate articles.
-	slog.Info("Generating articles...")
-	count, err := generator.Generate(cmd.Context(), conf.GitHub.Username, conf.GitHub.Repository)
+	var count int
+	if opts.issue != 0 || opts.eventPath != "" {
+		count, err = generateSingleIssue(cmd, generator, conf, opts)
+	} else {
+		slog.Info("Generating articles...")
+		count, err = generateAllSources(cmd, generator, conf)
+	}
+	if err == nil && opts.prune {
+		var plan core.PrunePlan
+		plan, err = generator.Prune(cmd.Context(), false)
+		if err == nil {
+			slog.Info(fmt.Sprintf("Pruned %d files of %d vanished issues", len(plan.Files), len(plan.Keys)))
+		}
+	}
+	// Persist progress even after a partial failure so that articles which
+	// were saved are not regenerated on the next run.
+	if saveErr := state.Save(statePath); saveErr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to save sync state: %w", saveErr))
+	}
+	if saveErr := manifest.Save(manifestPath); saveErr != nil {
+		err = errors.Join(err, fmt.Errorf("failed to save manifest: %w", saveErr))
+	}
 	if err != nil {
 		return fmt.Errorf("failed to generate articles: %w", err)
 	}
 
-	if withOGImage {
+	if opts.withOGImage {
 		summary := fmt.Sprintf("Complete: %d articles generated, %d OGP images (%d failed)", count, ogpOK, ogpFail)
 		if ogpFail > 0 {
 			// Log at Error level so the failure summary is visible even at
@@ -121,16 +365,197 @@ func runGenerate(cmd *cobra.Command, githubToken string, withOGImage bool) error
 	return nil
 }
 
-// generateOGPForArticle renders an OGP image for the given article and saves
-// it alongside the article markdown file.
-func generateOGPForArticle(cmd *cobra.Command, conf config.Config, renderer *ogimage.Renderer, article *core.Article) error {
+// generateAllSources generates the articles of every configured repository
+// into one content tree and returns the number of articles written. A failing
+// source does not stop the remaining ones.
+func generateAllSources(cmd *cobra.Command, generator *core.ArticleGenerator, conf config.Config) (int, error) {
+	if !conf.GitHub.HasSources() {
+		return generator.Generate(cmd.Context(), conf.GitHub.Username, conf.GitHub.Repository)
+	}
+	return generateSources(cmd, generator, conf.GitHub.SourceList())
+}
+
+// generateSources generates the articles of the given repositories and
+// returns the number of articles written. A failing source does not stop the
+// remaining ones.
+func generateSources(cmd *cobra.Command, generator *core.ArticleGenerator, sources []config.GitHubSourceConfig) (int, error) {
+	var (
+		total   int
+		joinErr error
+	)
+	for _, source := range sources {
+		slog.Info("Generating articles from " + source.FullName())
+		count, err := generator.Generate(cmd.Context(), source.Username, source.Repository)
+		total += count
+		if err != nil {
+			if ctxErr := cmd.Context().Err(); ctxErr != nil {
+				return total, ctxErr
+			}
+			joinErr = errors.Join(joinErr, fmt.Errorf("%s: %w", source.FullName(), err))
+		}
+	}
+	return total, joinErr
+}
+
+// watchIssues generates the articles of every configured repository, then
+// checks for changed issues every opts.interval and regenerates the
+// repositories that changed until the context is cancelled. Failed runs are
+// logged and retried at the next check. persist saves the sync state and
+// manifest after every run.
+func watchIssues(cmd *cobra.Command, generator *core.ArticleGenerator, conf config.Config, opts generateOptions, persist func() error) error {
+	ctx := cmd.Context()
+	ticker := time.NewTicker(opts.interval)
+	defer ticker.Stop()
+
+	slog.Info(fmt.Sprintf("Watching for changed issues every %s", opts.interval))
+	for first := true; ; first = false {
+		var changed []config.GitHubSourceConfig
+		for _, source := range conf.GitHub.SourceList() {
+			// Check before the first run too, so that changes made during it
+			// are picked up by the next check.
+			ok, err := generator.IssuesChanged(ctx, source.Username, source.Repository)
+			if err != nil && ctx.Err() == nil {
+				slog.Error("Failed to check for changed issues", "repository", source.FullName(), "error", err)
+			}
+			if ok || first || err != nil {
+				changed = append(changed, source)
+			}
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if len(changed) == 0 {
+			slog.Debug("No issues changed")
+		} else {
+			count, err := generateSources(cmd, generator, changed)
+			if err == nil && opts.prune {
+				var plan core.PrunePlan
+				plan, err = generator.Prune(ctx, false)
+				if err == nil {
+					slog.Info(fmt.Sprintf("Pruned %d files of %d vanished issues", len(plan.Files), len(plan.Keys)))
+				}
+			}
+			if saveErr := persist(); saveErr != nil {
+				err = errors.Join(err, fmt.Errorf("failed to save generation state: %w", saveErr))
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			if err != nil {
+				slog.Error("Failed to generate articles", "error", err)
+			} else {
+				slog.Info(fmt.Sprintf("Complete: %d articles generated", count))
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// singleIssueSource returns the repository the issue of --issue belongs to.
+func singleIssueSource(conf config.Config, repository string) (config.GitHubSourceConfig, error) {
+	sources := conf.GitHub.SourceList()
+	if repository == "" {
+		if len(sources) != 1 {
+			return config.GitHubSourceConfig{}, fmt.Errorf("--repository is required with --issue when github.sources lists several repositories")
+		}
+		return sources[0], nil
+	}
+	owner, name, ok := strings.Cut(repository, "/")
+	if !ok {
+		return config.GitHubSourceConfig{}, fmt.Errorf("--repository must be in owner/name form")
+	}
+	source, ok := conf.GitHub.Source(owner, name)
+	if !ok {
+		return config.GitHubSourceConfig{}, fmt.Errorf("repository %s is not configured in gic.config.yaml", repository)
+	}
+	return source, nil
+}
+
+// generateSingleIssue processes the issue selected by --issue or --from-event
+// and returns the number of articles written.
+func generateSingleIssue(cmd *cobra.Command, generator *core.ArticleGenerator, conf config.Config, opts generateOptions) (int, error) {
+	var (
+		outcome core.IssueOutcome
+		number  = opts.issue
+		err     error
+	)
+	if opts.eventPath != "" {
+		event, loadErr := core.LoadIssuesEvent(opts.eventPath)
+		if loadErr != nil {
+			return 0, loadErr
+		}
+		number = event.GetIssue().GetNumber()
+		username, repository := conf.GitHub.Username, conf.GitHub.Repository
+		if owner, name, ok := strings.Cut(event.GetRepo().GetFullName(), "/"); ok && conf.GitHub.HasSources() {
+			username, repository = owner, name
+		}
+		slog.Info(fmt.Sprintf("Processing issues event %q for issue #%d", event.GetAction(), number))
+		outcome, err = generator.HandleIssuesEvent(cmd.Context(), username, repository, event)
+	} else {
+		source, sourceErr := singleIssueSource(conf, opts.repository)
+		if sourceErr != nil {
+			return 0, sourceErr
+		}
+		slog.Info(fmt.Sprintf("Generating article for issue #%d...", number))
+		outcome, err = generator.GenerateIssue(cmd.Context(), source.Username, source.Repository, number)
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	slog.Info(fmt.Sprintf("Issue #%d: %s", number, outcome))
+	if outcome == core.IssueGenerated {
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// writePrunePlan prints the files a prune would remove.
+func writePrunePlan(output io.Writer, plan core.PrunePlan) error {
+	if len(plan.Files) == 0 {
+		_, err := fmt.Fprintln(output, "Nothing to prune")
+		return err
+	}
+	for _, file := range plan.Files {
+		if _, err := fmt.Fprintln(output, "would remove "+file); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(output, "%d files of %d vanished issues would be removed\n", len(plan.Files), len(plan.Keys))
+	return err
+}
+
+// articleConfig returns the configuration the article was generated with,
+// which differs from conf for articles of a github.sources entry.
+func articleConfig(conf config.Config, article *core.Article) config.Config {
+	if article == nil || article.Source == "" {
+		return conf
+	}
+	owner, name, _ := strings.Cut(article.Source, "/")
+	source, ok := conf.GitHub.Source(owner, name)
+	if !ok {
+		return conf
+	}
+	return conf.ForSource(source)
+}
+
+// generateOGPForArticle renders an OGP image for the given article, saves it
+// alongside the article markdown file at articlePath and returns the written
+// path. An empty articlePath is resolved from the configuration.
+func generateOGPForArticle(cmd *cobra.Command, conf config.Config, renderer *ogimage.Renderer, article *core.Article, articlePath string) (string, error) {
 	if article == nil {
-		return fmt.Errorf("article is nil")
+		return "", fmt.Errorf("article is nil")
 	}
 
 	// Check context before expensive render.
 	if err := cmd.Context().Err(); err != nil {
-		return fmt.Errorf("context cancelled before OGP render: %w", err)
+		return "", fmt.Errorf("context cancelled before OGP render: %w", err)
 	}
 
 	// Apply frontmatter overrides so the OGP image reflects the final
@@ -141,24 +566,27 @@ func generateOGPForArticle(cmd *cobra.Command, conf config.Config, renderer *ogi
 
 	jpeg, err := renderer.Render(cmd.Context(), data)
 	if err != nil {
-		return fmt.Errorf("render OGP: %w", err)
+		return "", fmt.Errorf("render OGP: %w", err)
 	}
 
-	outputPath, err := resolveOGPArticlePath(conf, rendered)
-	if err != nil {
-		return fmt.Errorf("resolve OGP path: %w", err)
+	outputPath := ogpPathForArticle(articlePath)
+	if articlePath == "" {
+		outputPath, err = resolveOGPArticlePath(conf, rendered)
+		if err != nil {
+			return "", fmt.Errorf("resolve OGP path: %w", err)
+		}
 	}
 
 	if err := os.MkdirAll(filepath.Dir(outputPath), 0o755); err != nil {
-		return fmt.Errorf("create output directory: %w", err)
+		return "", fmt.Errorf("create output directory: %w", err)
 	}
 
 	if err := os.WriteFile(outputPath, jpeg, 0o644); err != nil {
-		return fmt.Errorf("write OGP image: %w", err)
+		return "", fmt.Errorf("write OGP image: %w", err)
 	}
 
 	slog.Debug("OGP image generated: " + outputPath)
-	return nil
+	return outputPath, nil
 }
 
 // resolveOGPArticlePath returns the path where the OGP image should be saved
@@ -172,7 +600,11 @@ func resolveOGPArticlePath(conf config.Config, article *core.Article) (string, e
 		return "", fmt.Errorf("output articles config is not set")
 	}
 
-	datetime, err := article.ParseDateTime()
+	location, err := conf.Output.Location()
+	if err != nil {
+		return "", err
+	}
+	datetime, err := article.ParseDateTimeIn(location)
 	if err != nil {
 		datetime = time.Now()
 	}
@@ -181,7 +613,10 @@ func resolveOGPArticlePath(conf config.Config, article *core.Article) (string, e
 	if articleDir == "" {
 		return "", fmt.Errorf("output articles directory is not configured")
 	}
-	articleDir = config.CompileTimeTemplate(datetime, articleDir)
+	articleDir, err = core.ExpandPathTemplate(articleDir, article, datetime)
+	if err != nil {
+		return "", err
+	}
 
 	// If the article is saved as a page bundle (index.md), the directory
 	// already uniquely identifies the article — place ogp.jpeg there.
@@ -190,9 +625,19 @@ func resolveOGPArticlePath(conf config.Config, article *core.Article) (string, e
 	// after stripping ONLY a known markdown extension (.md/.markdown); for
 	// any other extension we append to the full filename so the image always
 	// stays adjacent to the markdown (e.g. "my.post" → "my.post.ogp.jpeg").
-	articleFilename := config.CompileTimeTemplate(datetime, conf.Output.Articles.Filename)
+	articleFilename, err := core.ExpandPathTemplate(conf.Output.Articles.Filename, article, datetime)
+	if err != nil {
+		return "", err
+	}
+	return ogpPathForArticle(filepath.Join(articleDir, articleFilename)), nil
+}
+
+// ogpPathForArticle returns the OGP image path for the markdown file at
+// articlePath; see resolveOGPArticlePath.
+func ogpPathForArticle(articlePath string) string {
+	articleDir, articleFilename := filepath.Split(articlePath)
 	if articleFilename == "index.md" {
-		return filepath.Clean(filepath.Join(articleDir, "ogp.jpeg")), nil
+		return filepath.Clean(filepath.Join(articleDir, "ogp.jpeg"))
 	}
 
 	base := articleFilename
@@ -200,5 +645,5 @@ func resolveOGPArticlePath(conf config.Config, article *core.Article) (string, e
 		base = strings.TrimSuffix(articleFilename, ext)
 	}
 	ogpName := base + ".ogp.jpeg"
-	return filepath.Clean(filepath.Join(articleDir, ogpName)), nil
+	return filepath.Clean(filepath.Join(articleDir, ogpName))
 }
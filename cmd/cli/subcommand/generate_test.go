@@ -1,7 +1,16 @@
 package subcommand
 
 import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"io"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/rokuosan/github-issue-cms/pkg/config"
 	"github.com/rokuosan/github-issue-cms/pkg/core"
@@ -25,8 +34,9 @@ func TestNewGenerateCommand(t *testing.T) {
 	ogimageFlag := cmd.Flags().Lookup("with-ogimage")
 	assert.NotNil(t, ogimageFlag, "--with-ogimage flag should exist")
 
-	// Ensure the token flag is marked as required.
-	assert.Contains(t, cmd.Flags().Lookup("token").Annotations, "cobra_annotation_bash_completion_one_required_flag")
+	// The token flag is optional because a GitHub App can be used instead.
+	assert.NotContains(t, cmd.Flags().Lookup("token").Annotations, "cobra_annotation_bash_completion_one_required_flag")
+	assert.NotNil(t, cmd.Flags().Lookup("attachment-token"), "--attachment-token flag should exist")
 }
 
 func TestGenerateCommand_Flags(t *testing.T) {
@@ -40,6 +50,132 @@ func TestGenerateCommand_Flags(t *testing.T) {
 	// Test the --with-ogimage flag.
 	ogimageFlag := cmd.Flags().Lookup("with-ogimage")
 	assert.NotNil(t, ogimageFlag, "--with-ogimage flag should exist")
+
+	// Test the --full flag.
+	fullFlag := cmd.Flags().Lookup("full")
+	assert.NotNil(t, fullFlag, "--full flag should exist")
+	assert.Equal(t, "false", fullFlag.DefValue)
+
+	// Test the pruning flags.
+	assert.NotNil(t, cmd.Flags().Lookup("prune"), "--prune flag should exist")
+	assert.NotNil(t, cmd.Flags().Lookup("dry-run"), "--dry-run flag should exist")
+}
+
+func TestGenerateCommand_SingleIssueFlags(t *testing.T) {
+	cmd := NewGenerateCommand()
+	assert.NotNil(t, cmd.Flags().Lookup("issue"), "--issue flag should exist")
+	assert.NotNil(t, cmd.Flags().Lookup("from-event"), "--from-event flag should exist")
+
+	cmd.SetArgs([]string{"--token", "test-token", "--issue", "1", "--from-event", "event.json"})
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "none of the others can be")
+}
+
+func TestGenerateCommand_WatchFlags(t *testing.T) {
+	cmd := NewGenerateCommand()
+	require.NotNil(t, cmd.Flags().Lookup("watch"), "--watch flag should exist")
+	assert.Equal(t, "5m0s", cmd.Flags().Lookup("interval").DefValue)
+
+	cmd.SetArgs([]string{"--token", "test-token", "--interval", "1m"})
+	assert.ErrorContains(t, cmd.Execute(), "--interval can only be used together with --watch")
+
+	cmd = NewGenerateCommand()
+	cmd.SetArgs([]string{"--token", "test-token", "--watch", "--issue", "1"})
+	assert.ErrorContains(t, cmd.Execute(), "none of the others can be")
+}
+
+func TestWatchIssues_RegeneratesUntilCancelled(t *testing.T) {
+	dir := t.TempDir()
+	handler, manifest := newWebhookTestHandler(t, dir)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	cmd := NewGenerateCommand()
+	cmd.SetContext(ctx)
+
+	// The snapshot store cannot tell whether issues changed, so every check
+	// runs an incremental generation. Stop after the second one.
+	runs := 0
+	persist := func() error {
+		runs++
+		if runs == 2 {
+			cancel()
+		}
+		return nil
+	}
+	err := watchIssues(cmd, handler.generator, handler.conf, generateOptions{watch: true, interval: time.Millisecond}, persist)
+
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 2, runs)
+	assert.FileExists(t, filepath.Join(dir, "content", "2024-01-01.md"))
+	assert.Contains(t, manifest.Articles, "1")
+}
+
+func TestNewTokenSources(t *testing.T) {
+	// Isolate the test from tokens of the environment it runs in.
+	t.Setenv(core.EnvGICGitHubToken, "")
+	t.Setenv(core.EnvGitHubToken, "")
+	t.Setenv(core.EnvGitHubAppID, "")
+	t.Setenv(core.EnvGitHubAppPrivateKey, "")
+	t.Setenv("GH_CONFIG_DIR", t.TempDir())
+	conf := *config.NewConfig()
+
+	token := func(t *testing.T, githubToken string) (string, string) {
+		t.Helper()
+		var output bytes.Buffer
+		tokens, _, err := newTokenSources(conf, &output, githubToken, "")
+		require.NoError(t, err)
+		value, err := tokens.Token(context.Background())
+		require.NoError(t, err)
+		return value, output.String()
+	}
+
+	_, _, err := newTokenSources(conf, io.Discard, "", "")
+	assert.ErrorContains(t, err, "a GitHub token is required")
+
+	t.Setenv(core.EnvGitHubToken, "env-token")
+	value, output := token(t, "")
+	assert.Equal(t, "env-token", value)
+	assert.Equal(t, "Authenticating with environment variable GITHUB_TOKEN\n", output)
+	value, output = token(t, "flag-token")
+	assert.Equal(t, "flag-token", value, "the flag takes precedence")
+	assert.Equal(t, "Authenticating with the --token flag\n", output)
+
+	// A configured GitHub App takes precedence over the ambient GITHUB_TOKEN
+	// of GitHub Actions.
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	t.Setenv(core.EnvGitHubAppPrivateKey, string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})))
+	conf.GitHub.App = &config.GitHubAppConfig{ID: 123}
+	var buf bytes.Buffer
+	tokens, _, err := newTokenSources(conf, &buf, "", "")
+	require.NoError(t, err)
+	assert.IsType(t, &core.GitHubAppTokenSource{}, tokens)
+	assert.Equal(t, "Authenticating with GitHub App 123\n", buf.String())
+}
+
+func TestGenerateCommand_DryRunRequiresPrune(t *testing.T) {
+	cmd := NewGenerateCommand()
+	cmd.SetArgs([]string{"--token", "test-token", "--dry-run"})
+	err := cmd.Execute()
+	assert.ErrorContains(t, err, "--dry-run can only be used together with --prune")
+}
+
+func TestWritePrunePlan(t *testing.T) {
+	t.Run("lists files", func(t *testing.T) {
+		var out bytes.Buffer
+		err := writePrunePlan(&out, core.PrunePlan{
+			Keys:  []string{"2"},
+			Files: []string{"content/posts/a.md", "static/images/a/0.png"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "would remove content/posts/a.md\nwould remove static/images/a/0.png\n2 files of 1 vanished issues would be removed\n", out.String())
+	})
+
+	t.Run("empty plan", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, writePrunePlan(&out, core.PrunePlan{}))
+		assert.Equal(t, "Nothing to prune\n", out.String())
+	})
 }
 
 func TestGenerateCommand_WithOGImageFlag(t *testing.T) {
@@ -142,3 +278,43 @@ func TestResolveOGPArticlePath(t *testing.T) {
 		assert.Error(t, err)
 	})
 }
+
+func TestOGPPathForArticle(t *testing.T) {
+	assert.Equal(t, filepath.Join("content", "2024-01-01-8", "ogp.jpeg"), ogpPathForArticle(filepath.Join("content", "2024-01-01-8", "index.md")))
+	assert.Equal(t, filepath.Join("content", "post-8.ogp.jpeg"), ogpPathForArticle(filepath.Join("content", "post-8.md")))
+}
+
+func TestSingleIssueSource(t *testing.T) {
+	single := config.Config{GitHub: &config.GitHubConfig{Username: "octo", Repository: "blog"}}
+	source, err := singleIssueSource(single, "")
+	require.NoError(t, err)
+	assert.Equal(t, "octo/blog", source.FullName())
+
+	multi := config.Config{GitHub: &config.GitHubConfig{
+		Username: "octo",
+		Sources:  []config.GitHubSourceConfig{{Repository: "engineering"}, {Repository: "design"}},
+	}}
+	_, err = singleIssueSource(multi, "")
+	assert.ErrorContains(t, err, "--repository is required")
+
+	source, err = singleIssueSource(multi, "octo/design")
+	require.NoError(t, err)
+	assert.Equal(t, "octo/design", source.FullName())
+
+	_, err = singleIssueSource(multi, "octo/unknown")
+	assert.ErrorContains(t, err, "is not configured")
+	_, err = singleIssueSource(multi, "design")
+	assert.ErrorContains(t, err, "owner/name")
+}
+
+func TestArticleConfig_UsesSourceOutput(t *testing.T) {
+	conf := testConfig(t.TempDir() + "/articles")
+	conf.GitHub = &config.GitHubConfig{
+		Username: "octo",
+		Sources:  []config.GitHubSourceConfig{{Repository: "design", Output: &config.OutputConfig{Articles: &config.OutputArticlesConfig{Directory: "content/[:repository]"}}}},
+	}
+
+	assert.Equal(t, conf.Output.Articles.Directory, articleConfig(conf, &core.Article{}).Output.Articles.Directory)
+	got := articleConfig(conf, &core.Article{Source: "octo/design"})
+	assert.Equal(t, "content/design", got.Output.Articles.Directory)
+}
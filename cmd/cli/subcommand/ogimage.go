@@ -118,26 +118,24 @@ func articleToOGPData(article *core.Article, conf config.Config) ogimage.OGPData
 	}
 	article = article.Clone()
 	core.FilterArticleTags(article, conf)
+	// The article was saved with the same zone, so it is known here.
+	location, _ := conf.Output.Location()
 	return ogimage.OGPData{
 		Title:    article.Title,
 		Author:   article.Author,
-		Date:     formatDateForOGP(article.Date),
+		Date:     formatDateForOGP(article.Date, location),
 		Category: article.Category,
 		Tags:     article.Tags,
 	}
 }
 
-// formatDateForOGP formats a date string for display in the OGP image.
-func formatDateForOGP(dateStr string) string {
-	formats := []string{
-		time.RFC3339,
-		"2006-01-02T15:04:05Z",
-		"2006-01-02",
-	}
-	for _, layout := range formats {
-		if t, err := time.Parse(layout, dateStr); err == nil {
-			return t.Format("2006-01-02")
-		}
+// formatDateForOGP formats a date string for display in the OGP image, on the
+// day it falls on in loc. With a nil loc, the day written in the date is
+// kept.
+func formatDateForOGP(dateStr string, loc *time.Location) string {
+	article := &core.Article{Date: dateStr}
+	if t, err := article.ParseDateTimeIn(loc); err == nil {
+		return t.Format("2006-01-02")
 	}
 	// If we can't parse it, return as-is.
 	return dateStr
@@ -153,7 +151,11 @@ func resolveOGPOutputPath(conf config.Config, article *core.Article) (string, er
 		return "", fmt.Errorf("output images config is not set")
 	}
 
-	datetime, err := article.ParseDateTime()
+	location, err := conf.Output.Location()
+	if err != nil {
+		return "", err
+	}
+	datetime, err := article.ParseDateTimeIn(location)
 	if err != nil {
 		// Fall back to current time if we can't parse the article date.
 		datetime = time.Now()
@@ -163,7 +165,10 @@ func resolveOGPOutputPath(conf config.Config, article *core.Article) (string, er
 	if imageDir == "" {
 		return "", fmt.Errorf("output images directory is not configured")
 	}
-	imageDir = config.CompileTimeTemplate(datetime, imageDir)
+	imageDir, err = core.ExpandPathTemplate(imageDir, article, datetime)
+	if err != nil {
+		return "", err
+	}
 
 	return filepath.Clean(filepath.Join(imageDir, "ogp.jpeg")), nil
 }
@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/rokuosan/github-issue-cms/pkg/config"
 	"github.com/rokuosan/github-issue-cms/pkg/core"
@@ -113,12 +114,24 @@ func TestFormatDateForOGP(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.input, func(t *testing.T) {
-			result := formatDateForOGP(tt.input)
+			result := formatDateForOGP(tt.input, nil)
 			assert.Equal(t, tt.expected, result)
 		})
 	}
 }
 
+func TestFormatDateForOGP_Timezone(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	require.NoError(t, err)
+
+	assert.Equal(t, "2024-01-15", formatDateForOGP("2024-01-14T23:30:00Z", tokyo))
+	assert.Equal(t, "2024-01-14", formatDateForOGP("2024-01-14T14:59:59Z", tokyo))
+	assert.Equal(t, "2024-01-14", formatDateForOGP("2024-01-15T03:00:00Z", losAngeles))
+	assert.Equal(t, "2024-01-15", formatDateForOGP("2024-01-15", tokyo))
+}
+
 func TestResolveOGPOutputPath(t *testing.T) {
 	dir := t.TempDir()
 
@@ -0,0 +1,374 @@
+package subcommand
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/rokuosan/github-issue-cms/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// webhookSecretEnv is the environment variable holding the secret that
+	// webhook deliveries are signed with.
+	webhookSecretEnv = "GIC_WEBHOOK_SECRET"
+	// maxWebhookPayload is the largest payload GitHub delivers.
+	maxWebhookPayload = 25 << 20
+	// webhookQueueSize is the number of deliveries that can wait for the
+	// worker before new ones are rejected.
+	webhookQueueSize = 100
+)
+
+// webhookActions are the "issues" event actions that can change the article
+// of an issue. Other actions, such as assigned or pinned, are ignored.
+var webhookActions = []string{
+	"opened", "edited", "closed", "reopened", "labeled", "unlabeled",
+	"milestoned", "demilestoned", "deleted", "transferred",
+}
+
+// serveOptions holds the flag values of the serve subcommand.
+type serveOptions struct {
+	githubToken     string
+	attachmentToken string
+	host            string
+	port            int
+	exec            string
+}
+
+// NewServeCommand creates the serve subcommand.
+func NewServeCommand() *cobra.Command {
+	var opts serveOptions
+
+	cmd := &cobra.Command{
+		Use:   "serve",
+		Short: "Regenerate articles on GitHub webhook deliveries",
+		Long: `Listen for GitHub webhook deliveries and regenerate articles.
+
+Configure a webhook for the "Issues" event with the content type
+application/json, pointing to /webhook on this server, and set the same
+secret in the GIC_WEBHOOK_SECRET environment variable. Deliveries without a
+valid X-Hub-Signature-256 signature are rejected.
+
+When an issue is opened, edited, closed, reopened, labeled, unlabeled,
+milestoned or demilestoned, its article is regenerated like
+"generate --issue" would. Deleted and transferred issues, and issues that no
+longer match the configuration, have their generated files removed. Events
+of repositories that are not configured are ignored.
+
+Verified deliveries are acknowledged with 202 Accepted right away and
+processed one at a time in the background, so that long generations and
+rebuilds do not run into GitHub's delivery timeout. Failures are logged. The
+manifest and sync state are saved after every delivery.
+
+With --exec, the given shell command runs after every delivery that
+generated or removed files, for example to rebuild the site. It receives
+the repository, issue number and outcome in the GIC_REPOSITORY,
+GIC_ISSUE_NUMBER and GIC_ISSUE_OUTCOME environment variables.
+
+GET /healthz reports whether the server is up.
+
+Examples:
+  # Listen on all interfaces and rebuild the site after each change
+  GIC_WEBHOOK_SECRET=YOUR_SECRET github-issue-cms serve --token YOUR_GITHUB_TOKEN --host 0.0.0.0 --exec "hugo --minify"`,
+
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runServe(cmd, opts)
+		},
+	}
+
+	cmd.Flags().StringVarP(&opts.githubToken, "token", "t", "", "GitHub API Token (defaults to a GitHub App, GIC_GITHUB_TOKEN, GITHUB_TOKEN, github.tokenFile or the gh CLI)")
+	cmd.Flags().StringVar(&opts.attachmentToken, "attachment-token", "", "Token used only to download images, e.g. a classic PAT for private attachments")
+	cmd.Flags().StringVar(&opts.host, "host", "localhost", "Host to bind the webhook server to")
+	cmd.Flags().IntVarP(&opts.port, "port", "p", 8080, "Port to bind the webhook server to")
+	cmd.Flags().StringVar(&opts.exec, "exec", "", "Shell command to run after a delivery generated or removed files")
+
+	return cmd
+}
+
+func runServe(cmd *cobra.Command, opts serveOptions) error {
+	if opts.port < 0 || opts.port > 65535 {
+		return fmt.Errorf("invalid port %d: must be between 0 and 65535", opts.port)
+	}
+	secret := os.Getenv(webhookSecretEnv)
+	if secret == "" {
+		return fmt.Errorf("a webhook secret is required; set %s", webhookSecretEnv)
+	}
+
+	conf, err := config.Get()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if !conf.GitHub.HasSources() && (conf.GitHub.Username == "" || conf.GitHub.Repository == "") {
+		return fmt.Errorf("please set username and repository in gic.config.yaml; run 'github-issue-cms init' to create a config file")
+	}
+
+	generator, err := newGenerator(conf, cmd.ErrOrStderr(), generateOptions{githubToken: opts.githubToken, attachmentToken: opts.attachmentToken})
+	if err != nil {
+		return fmt.Errorf("failed to create generator: %w", err)
+	}
+	manifestPath := core.ManifestPath(conf)
+	manifest, err := core.LoadManifest(manifestPath)
+	if err != nil {
+		return fmt.Errorf("failed to load manifest: %w", err)
+	}
+	generator.SetManifest(manifest)
+	statePath := core.SyncStatePath(conf)
+	state, err := core.LoadSyncState(statePath)
+	if err != nil {
+		return fmt.Errorf("failed to load sync state: %w", err)
+	}
+	generator.SetSyncState(state)
+
+	handler := &webhookHandler{
+		generator: generator,
+		conf:      conf,
+		secret:    []byte(secret),
+		persist: func() error {
+			return errors.Join(state.Save(statePath), manifest.Save(manifestPath))
+		},
+		exec:       opts.exec,
+		output:     cmd.ErrOrStderr(),
+		deliveries: make(chan webhookDelivery, webhookQueueSize),
+	}
+
+	ctx := cmd.Context()
+	workerCtx, stopWorker := context.WithCancel(ctx)
+	workerDone := make(chan struct{})
+	go func() {
+		defer close(workerDone)
+		handler.run(workerCtx)
+	}()
+	defer func() {
+		stopWorker()
+		<-workerDone
+	}()
+
+	listener, err := net.Listen("tcp", net.JoinHostPort(opts.host, strconv.Itoa(opts.port)))
+	if err != nil {
+		return fmt.Errorf("listen for webhook server: %w", err)
+	}
+	server := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	serverErrors := make(chan error, 1)
+	go func() {
+		serverErrors <- server.Serve(listener)
+	}()
+
+	if _, err := fmt.Fprintf(cmd.OutOrStdout(), "Webhook server: http://%s/webhook\n", listener.Addr()); err != nil {
+		_ = server.Close()
+		return fmt.Errorf("write webhook server address: %w", err)
+	}
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.Shutdown(shutdownCtx); err != nil {
+			return fmt.Errorf("shutdown webhook server: %w", err)
+		}
+		return ctx.Err()
+	case err := <-serverErrors:
+		if err == http.ErrServerClosed {
+			return nil
+		}
+		return fmt.Errorf("webhook server: %w", err)
+	}
+}
+
+// webhookHandler serves GitHub webhook deliveries and a health endpoint.
+// Verified deliveries are queued and processed one at a time by run, because
+// the generator, manifest and sync state are shared.
+type webhookHandler struct {
+	generator *core.ArticleGenerator
+	conf      config.Config
+	secret    []byte
+	// persist saves the manifest and sync state after a delivery.
+	persist func() error
+	// exec is the shell command run after files changed.
+	exec string
+	// output receives the output of exec.
+	output io.Writer
+	// deliveries queues verified deliveries for run.
+	deliveries chan webhookDelivery
+}
+
+// webhookDelivery is an "issues" event waiting to be processed.
+type webhookDelivery struct {
+	id     string
+	source config.GitHubSourceConfig
+	event  *github.IssuesEvent
+}
+
+func (h *webhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.URL.Path {
+	case "/healthz":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = w.Write([]byte("ok\n"))
+	case "/webhook":
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		h.serveDelivery(w, r)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func (h *webhookHandler) serveDelivery(w http.ResponseWriter, r *http.Request) {
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType != "application/json" {
+		http.Error(w, "webhook content type must be application/json", http.StatusUnsupportedMediaType)
+		return
+	}
+	payload, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxWebhookPayload))
+	if err != nil {
+		http.Error(w, "failed to read payload", http.StatusBadRequest)
+		return
+	}
+	if !validWebhookSignature(h.secret, payload, r.Header.Get("X-Hub-Signature-256")) {
+		slog.Warn("Rejected webhook delivery with an invalid signature", "delivery", r.Header.Get("X-GitHub-Delivery"))
+		http.Error(w, "invalid signature", http.StatusUnauthorized)
+		return
+	}
+
+	switch eventType := r.Header.Get("X-GitHub-Event"); eventType {
+	case "ping":
+		_, _ = w.Write([]byte("pong\n"))
+		return
+	case "issues":
+	default:
+		writeIgnored(w, "event "+eventType+" is not handled")
+		return
+	}
+
+	event, err := core.ParseIssuesEvent(payload)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !slices.Contains(webhookActions, event.GetAction()) {
+		writeIgnored(w, "action "+event.GetAction()+" is not handled")
+		return
+	}
+	owner, name, _ := strings.Cut(event.GetRepo().GetFullName(), "/")
+	source, ok := h.conf.GitHub.Source(owner, name)
+	if !ok {
+		writeIgnored(w, "repository "+event.GetRepo().GetFullName()+" is not configured")
+		return
+	}
+
+	number := event.GetIssue().GetNumber()
+	select {
+	case h.deliveries <- webhookDelivery{id: r.Header.Get("X-GitHub-Delivery"), source: source, event: event}:
+	default:
+		slog.Error("Rejected webhook delivery because the queue is full", "issue", number, "delivery", r.Header.Get("X-GitHub-Delivery"))
+		http.Error(w, "too many deliveries are waiting; redeliver later", http.StatusServiceUnavailable)
+		return
+	}
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintf(w, "queued: issue #%d\n", number)
+}
+
+// run processes queued deliveries one at a time until ctx is done.
+func (h *webhookHandler) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			if pending := len(h.deliveries); pending > 0 {
+				slog.Warn("Dropping queued webhook deliveries on shutdown; redeliver them from the webhook settings", "count", pending)
+			}
+			return
+		case delivery := <-h.deliveries:
+			h.process(ctx, delivery)
+		}
+	}
+}
+
+// process regenerates or removes the article of a delivery, saves the
+// manifest and sync state, and runs exec when files changed. Errors are
+// logged because the delivery was already acknowledged.
+func (h *webhookHandler) process(ctx context.Context, delivery webhookDelivery) {
+	source, event := delivery.source, delivery.event
+	number := event.GetIssue().GetNumber()
+	slog.Info(fmt.Sprintf("Processing issues event %q for issue #%d", event.GetAction(), number), "repository", source.FullName(), "delivery", delivery.id)
+
+	outcome, err := h.generator.HandleIssuesEvent(ctx, source.Username, source.Repository, event)
+	if h.persist != nil {
+		if saveErr := h.persist(); saveErr != nil {
+			err = errors.Join(err, fmt.Errorf("failed to save generation state: %w", saveErr))
+		}
+	}
+	if err != nil {
+		slog.Error("Failed to process webhook delivery", "issue", number, "delivery", delivery.id, "error", err)
+		return
+	}
+	slog.Info(fmt.Sprintf("Issue #%d: %s", number, outcome))
+
+	if h.exec == "" || outcome == core.IssueSkipped {
+		return
+	}
+	if err := h.runExec(ctx, source.FullName(), number, outcome); err != nil {
+		slog.Error("Post-generation command failed", "issue", number, "delivery", delivery.id, "error", err)
+	}
+}
+
+// runExec runs the post-generation command through the platform shell.
+func (h *webhookHandler) runExec(ctx context.Context, repository string, number int, outcome core.IssueOutcome) error {
+	var command *exec.Cmd
+	if runtime.GOOS == "windows" {
+		command = exec.CommandContext(ctx, "cmd", "/C", h.exec)
+	} else {
+		command = exec.CommandContext(ctx, "sh", "-c", h.exec)
+	}
+	command.Env = append(os.Environ(),
+		"GIC_REPOSITORY="+repository,
+		"GIC_ISSUE_NUMBER="+strconv.Itoa(number),
+		"GIC_ISSUE_OUTCOME="+string(outcome),
+	)
+	command.Stdout = h.output
+	command.Stderr = h.output
+	return command.Run()
+}
+
+// validWebhookSignature reports whether signature is the X-Hub-Signature-256
+// header GitHub sends for payload signed with secret.
+func validWebhookSignature(secret, payload []byte, signature string) bool {
+	digest, ok := strings.CutPrefix(signature, "sha256=")
+	if !ok {
+		return false
+	}
+	got, err := hex.DecodeString(digest)
+	if err != nil {
+		return false
+	}
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(payload)
+	return hmac.Equal(got, mac.Sum(nil))
+}
+
+// writeIgnored acknowledges a delivery that does not affect any article.
+func writeIgnored(w http.ResponseWriter, reason string) {
+	w.WriteHeader(http.StatusAccepted)
+	_, _ = fmt.Fprintf(w, "ignored: %s\n", reason)
+}
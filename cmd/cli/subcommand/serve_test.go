@@ -0,0 +1,263 @@
+package subcommand
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/rokuosan/github-issue-cms/pkg/core"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const webhookTestSecret = "It's a Secret to Everybody"
+
+func issuesEventPayload(action string, number int, repository string) string {
+	return fmt.Sprintf(`{"action": %q, "issue": {"number": %d}, "repository": {"full_name": %q}}`, action, number, repository)
+}
+
+func signWebhookPayload(secret, payload string) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(payload))
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// newWebhookTestHandler serves issue #1 of testuser/testrepo from an
+// in-memory snapshot and writes articles below dir.
+func newWebhookTestHandler(t *testing.T, dir string) (*webhookHandler, *core.Manifest) {
+	t.Helper()
+	conf := *config.NewConfig()
+	conf.GitHub.Username = "testuser"
+	conf.GitHub.Repository = "testrepo"
+	conf.Output.Articles.Directory = filepath.Join(dir, "content")
+	conf.Output.Articles.Filename = "%Y-%m-%d.md"
+	conf.Output.Images.Directory = filepath.Join(dir, "images")
+
+	snapshot := core.NewSnapshot(t.TempDir())
+	createdAt := github.Timestamp{Time: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)}
+	snapshot.Repositories["testuser/testrepo"] = &core.SnapshotRepository{
+		Issues: []*github.Issue{{
+			Number: github.Ptr(1), Title: github.Ptr("Hello"), Body: github.Ptr("World"), State: github.Ptr("closed"),
+			User: &github.User{Login: github.Ptr("testuser")}, CreatedAt: &createdAt, UpdatedAt: &createdAt,
+		}},
+	}
+	generator := core.NewArticleGeneratorFromSnapshot(conf, snapshot, slog.Default())
+	manifest := core.NewManifest()
+	generator.SetManifest(manifest)
+
+	return &webhookHandler{
+		generator:  generator,
+		conf:       conf,
+		secret:     []byte(webhookTestSecret),
+		persist:    func() error { return nil },
+		deliveries: make(chan webhookDelivery, webhookQueueSize),
+	}, manifest
+}
+
+// processQueued processes the next delivery the handler queued, like run
+// would.
+func processQueued(t *testing.T, handler *webhookHandler) {
+	t.Helper()
+	select {
+	case delivery := <-handler.deliveries:
+		handler.process(context.Background(), delivery)
+	default:
+		t.Fatal("no delivery was queued")
+	}
+}
+
+func deliver(t *testing.T, server *httptest.Server, event, payload, signature string) (int, string) {
+	t.Helper()
+	request, err := http.NewRequest(http.MethodPost, server.URL+"/webhook", strings.NewReader(payload))
+	require.NoError(t, err)
+	request.Header.Set("Content-Type", "application/json")
+	request.Header.Set("X-GitHub-Event", event)
+	request.Header.Set("X-Hub-Signature-256", signature)
+	response, err := http.DefaultClient.Do(request)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = response.Body.Close() })
+	return response.StatusCode, readResponseBody(t, response)
+}
+
+func TestNewServeCommand(t *testing.T) {
+	cmd := NewServeCommand()
+
+	assert.Equal(t, "serve", cmd.Use)
+	assert.Equal(t, "localhost", cmd.Flag("host").DefValue)
+	assert.Equal(t, "8080", cmd.Flag("port").DefValue)
+	assert.NotNil(t, cmd.Flags().Lookup("token"), "--token flag should exist")
+	assert.NotNil(t, cmd.Flags().Lookup("exec"), "--exec flag should exist")
+}
+
+func TestServeCommand_RequiresSecret(t *testing.T) {
+	t.Setenv(webhookSecretEnv, "")
+	cmd := NewServeCommand()
+	cmd.SetArgs([]string{"--token", "test-token"})
+	assert.ErrorContains(t, cmd.Execute(), webhookSecretEnv)
+}
+
+func TestWebhookHandler_Health(t *testing.T) {
+	handler, _ := newWebhookTestHandler(t, t.TempDir())
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "ok\n", recorder.Body.String())
+}
+
+func TestWebhookHandler_RegeneratesAndRemovesArticles(t *testing.T) {
+	dir := t.TempDir()
+	handler, manifest := newWebhookTestHandler(t, dir)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	articlePath := filepath.Join(dir, "content", "2024-01-01.md")
+
+	payload := issuesEventPayload("edited", 1, "TestUser/testrepo")
+	status, body := deliver(t, server, "issues", payload, signWebhookPayload(webhookTestSecret, payload))
+	assert.Equal(t, http.StatusAccepted, status)
+	assert.Equal(t, "queued: issue #1\n", body)
+	assert.NoFileExists(t, articlePath)
+	processQueued(t, handler)
+	assert.FileExists(t, articlePath)
+	assert.Contains(t, manifest.Articles, "1")
+
+	payload = issuesEventPayload("deleted", 1, "testuser/testrepo")
+	status, _ = deliver(t, server, "issues", payload, signWebhookPayload(webhookTestSecret, payload))
+	assert.Equal(t, http.StatusAccepted, status)
+	processQueued(t, handler)
+	assert.NoFileExists(t, articlePath)
+	assert.NotContains(t, manifest.Articles, "1")
+}
+
+func TestWebhookHandler_RejectsDeliveriesWhenTheQueueIsFull(t *testing.T) {
+	handler, _ := newWebhookTestHandler(t, t.TempDir())
+	handler.deliveries = make(chan webhookDelivery, 1)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	payload := issuesEventPayload("edited", 1, "testuser/testrepo")
+	status, _ := deliver(t, server, "issues", payload, signWebhookPayload(webhookTestSecret, payload))
+	assert.Equal(t, http.StatusAccepted, status)
+	status, _ = deliver(t, server, "issues", payload, signWebhookPayload(webhookTestSecret, payload))
+	assert.Equal(t, http.StatusServiceUnavailable, status)
+}
+
+func TestWebhookHandler_RunStopsWithTheContext(t *testing.T) {
+	handler, _ := newWebhookTestHandler(t, t.TempDir())
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		handler.run(ctx)
+	}()
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("run did not return after the context was cancelled")
+	}
+}
+
+func TestWebhookHandler_RejectsInvalidSignatures(t *testing.T) {
+	dir := t.TempDir()
+	handler, _ := newWebhookTestHandler(t, dir)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	payload := issuesEventPayload("opened", 1, "testuser/testrepo")
+
+	for name, signature := range map[string]string{
+		"missing":      "",
+		"sha1":         "sha1=" + strings.Repeat("0", 40),
+		"wrong secret": signWebhookPayload("another secret", payload),
+		"not hex":      "sha256=zz",
+	} {
+		t.Run(name, func(t *testing.T) {
+			status, _ := deliver(t, server, "issues", payload, signature)
+			assert.Equal(t, http.StatusUnauthorized, status)
+		})
+	}
+	assert.NoDirExists(t, filepath.Join(dir, "content"))
+}
+
+func TestWebhookHandler_IgnoresUnrelatedDeliveries(t *testing.T) {
+	dir := t.TempDir()
+	handler, _ := newWebhookTestHandler(t, dir)
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	tests := []struct {
+		name    string
+		event   string
+		payload string
+		status  int
+		body    string
+	}{
+		{"ping", "ping", `{"zen": "Keep it logically awesome."}`, http.StatusOK, "pong\n"},
+		{"other events", "push", `{"ref": "refs/heads/main"}`, http.StatusAccepted, "ignored: event push is not handled\n"},
+		{"other actions", "issues", issuesEventPayload("assigned", 1, "testuser/testrepo"), http.StatusAccepted, "ignored: action assigned is not handled\n"},
+		{"other repositories", "issues", issuesEventPayload("opened", 1, "someone/else"), http.StatusAccepted, "ignored: repository someone/else is not configured\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, body := deliver(t, server, tt.event, tt.payload, signWebhookPayload(webhookTestSecret, tt.payload))
+			assert.Equal(t, tt.status, status)
+			assert.Equal(t, tt.body, body)
+		})
+	}
+	assert.Empty(t, handler.deliveries)
+	assert.NoDirExists(t, filepath.Join(dir, "content"))
+}
+
+func TestWebhookHandler_RunsExecAfterChanges(t *testing.T) {
+	dir := t.TempDir()
+	handler, _ := newWebhookTestHandler(t, dir)
+	hookOutput := filepath.Join(dir, "hook.txt")
+	handler.exec = `echo "$GIC_REPOSITORY $GIC_ISSUE_NUMBER $GIC_ISSUE_OUTCOME" > "` + hookOutput + `"`
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	payload := issuesEventPayload("opened", 1, "testuser/testrepo")
+	status, _ := deliver(t, server, "issues", payload, signWebhookPayload(webhookTestSecret, payload))
+	require.Equal(t, http.StatusAccepted, status)
+	processQueued(t, handler)
+	data, err := os.ReadFile(hookOutput)
+	require.NoError(t, err)
+	assert.Equal(t, "testuser/testrepo 1 generated\n", string(data))
+
+	payload = issuesEventPayload("deleted", 1, "testuser/testrepo")
+	status, _ = deliver(t, server, "issues", payload, signWebhookPayload(webhookTestSecret, payload))
+	require.Equal(t, http.StatusAccepted, status)
+	processQueued(t, handler)
+	data, err = os.ReadFile(hookOutput)
+	require.NoError(t, err)
+	assert.Equal(t, "testuser/testrepo 1 removed\n", string(data))
+
+	// Nothing is left to remove the second time, so the command does not run.
+	require.NoError(t, os.Remove(hookOutput))
+	status, _ = deliver(t, server, "issues", payload, signWebhookPayload(webhookTestSecret, payload))
+	require.Equal(t, http.StatusAccepted, status)
+	processQueued(t, handler)
+	assert.NoFileExists(t, hookOutput)
+}
+
+func TestWebhookHandler_RejectsNonJSONDeliveries(t *testing.T) {
+	handler, _ := newWebhookTestHandler(t, t.TempDir())
+	request := httptest.NewRequest(http.MethodPost, "/webhook", strings.NewReader("payload=%7B%7D"))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+
+	assert.Equal(t, http.StatusUnsupportedMediaType, recorder.Code)
+}
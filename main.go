@@ -4,6 +4,9 @@ import (
 	"fmt"
 	"log/slog"
 	"time"
+	// Embed the time zone database for output.timezone, since minimal
+	// images such as the Alpine one ship without it.
+	_ "time/tzdata"
 
 	"github.com/rokuosan/github-issue-cms/cmd/cli"
 )
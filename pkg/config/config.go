@@ -2,13 +2,15 @@ package config
 
 import (
 	"os"
+	"reflect"
+	"time"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 	"gopkg.in/yaml.v3"
 )
 
 var config Config
-var GitHubToken string
 
 // viperInitialize initializes viper.
 func viperInitialize() {
@@ -25,7 +27,11 @@ func load() error {
 		return err
 	}
 
-	if err := viper.Unmarshal(&config); err != nil {
+	if err := viper.Unmarshal(&config, viper.DecodeHook(mapstructure.ComposeDecodeHookFunc(
+		mapstructure.StringToTimeDurationHookFunc(),
+		mapstructure.StringToSliceHookFunc(","),
+		timestampToStringHook,
+	))); err != nil {
 		return err
 	}
 
@@ -33,6 +39,19 @@ func load() error {
 	return config.validate()
 }
 
+// timestampToStringHook turns unquoted YAML timestamps such as 2024-01-01
+// back into strings for string fields like github.filter.createdAfter.
+func timestampToStringHook(from, to reflect.Type, data any) (any, error) {
+	t, ok := data.(time.Time)
+	if !ok || to.Kind() != reflect.String {
+		return data, nil
+	}
+	if t.Equal(t.Truncate(24*time.Hour)) && t.Location() == time.UTC {
+		return t.Format(time.DateOnly), nil
+	}
+	return t.Format(time.RFC3339), nil
+}
+
 // Reload clears the cached config and reloads it from disk.
 func Reload() (Config, error) {
 	config = Config{}
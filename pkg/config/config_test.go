@@ -68,3 +68,35 @@ func TestWriteAndReload_PreservesExplicitEmptyImageTargets(t *testing.T) {
 		t.Fatalf("target urls = %#v", reloaded.Output.Images.TargetURLs())
 	}
 }
+
+func TestReload_ParsesUnquotedFilterDates(t *testing.T) {
+	tempDir := t.TempDir()
+
+	originalWd, err := os.Getwd()
+	if err != nil {
+		t.Fatalf("getwd: %v", err)
+	}
+	t.Cleanup(func() {
+		if err := os.Chdir(originalWd); err != nil {
+			t.Fatalf("restore wd: %v", err)
+		}
+		config = Config{}
+		viper.Reset()
+	})
+	if err := os.Chdir(tempDir); err != nil {
+		t.Fatalf("chdir: %v", err)
+	}
+
+	data := "github:\n  username: octo\n  repository: blog\n  filter:\n    state: closed\n    createdAfter: 2024-01-01\n"
+	if err := os.WriteFile(GetConfigPath(), []byte(data), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+
+	reloaded, err := Reload()
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if got := reloaded.GitHub.Filter.CreatedAfter; got != "2024-01-01" {
+		t.Fatalf("created after = %q", got)
+	}
+}
@@ -0,0 +1,119 @@
+package config
+
+import "strings"
+
+const (
+	// PlaceholderOwner is replaced with the owner of a github.sources entry
+	// in output paths and URLs.
+	PlaceholderOwner = "[:owner]"
+	// PlaceholderRepository is replaced with the repository name of a
+	// github.sources entry in output paths and URLs.
+	PlaceholderRepository = "[:repository]"
+)
+
+// ForSource returns the configuration the articles of one source are
+// generated with. github.username, github.repository, github.labels and
+// github.filter are taken from the source, the source's output settings
+// override the shared ones field by field, and the [:owner] and
+// [:repository] placeholders of the output paths are expanded.
+func (c Config) ForSource(source GitHubSourceConfig) Config {
+	conf := c
+	github := GitHubConfig{}
+	if c.GitHub != nil {
+		github = *c.GitHub
+	}
+	github.Username = source.Username
+	github.Repository = source.Repository
+	github.Labels = source.Labels
+	github.Filter = source.Filter
+	github.Sources = nil
+	conf.GitHub = &github
+
+	conf.Output = mergeOutputConfig(c.Output, source.Output)
+	replacer := strings.NewReplacer(PlaceholderOwner, source.Username, PlaceholderRepository, source.Repository)
+	conf.Output.Articles.Directory = replacer.Replace(conf.Output.Articles.Directory)
+	conf.Output.Articles.Filename = replacer.Replace(conf.Output.Articles.Filename)
+	conf.Output.Images.Directory = replacer.Replace(conf.Output.Images.Directory)
+	conf.Output.Images.Filename = replacer.Replace(conf.Output.Images.Filename)
+	if conf.Output.Images.BaseURL != nil {
+		url := replacer.Replace(*conf.Output.Images.BaseURL)
+		conf.Output.Images.BaseURL = &url
+	}
+	if conf.Output.Comments != nil {
+		conf.Output.Comments.Directory = replacer.Replace(conf.Output.Comments.Directory)
+	}
+	return conf
+}
+
+// mergeOutputConfig returns a copy of base with the non-empty settings of
+// override applied. The state directory is shared by every source and
+// cannot be overridden.
+func mergeOutputConfig(base, override *OutputConfig) *OutputConfig {
+	merged := OutputConfig{}
+	if base != nil {
+		merged = *base
+	}
+	articles := OutputArticlesConfig{}
+	if merged.Articles != nil {
+		articles = *merged.Articles
+	}
+	images := OutputImagesConfig{}
+	if merged.Images != nil {
+		images = *merged.Images
+	}
+	if merged.Comments != nil {
+		comments := *merged.Comments
+		merged.Comments = &comments
+	}
+
+	if override != nil {
+		if a := override.Articles; a != nil {
+			if a.Directory != "" {
+				articles.Directory = a.Directory
+			}
+			if a.Filename != "" {
+				articles.Filename = a.Filename
+			}
+			if a.Collision != "" {
+				articles.Collision = a.Collision
+			}
+			if a.Date != "" {
+				articles.Date = a.Date
+			}
+		}
+		if i := override.Images; i != nil {
+			if i.Directory != "" {
+				images.Directory = i.Directory
+			}
+			if i.Filename != "" {
+				images.Filename = i.Filename
+			}
+			if i.BaseURL != nil {
+				images.BaseURL = i.BaseURL
+			}
+			if i.Targets != nil {
+				images.Targets = i.Targets
+			}
+			if i.Resolve != "" {
+				images.Resolve = i.Resolve
+			}
+		}
+		if override.Comments != nil {
+			comments := *override.Comments
+			merged.Comments = &comments
+		}
+		if override.FrontMatter != nil {
+			merged.FrontMatter = override.FrontMatter
+		}
+		if override.Publish != nil {
+			merged.Publish = override.Publish
+		}
+		if override.Timezone != "" {
+			merged.Timezone = override.Timezone
+		}
+	}
+
+	merged.Articles = &articles
+	merged.Images = &images
+	return &merged
+}
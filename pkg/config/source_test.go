@@ -0,0 +1,130 @@
+package config
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestGitHubConfig_SourceList(t *testing.T) {
+	single := &GitHubConfig{Username: "octo", Repository: "blog", Labels: []string{"post"}}
+	if single.HasSources() {
+		t.Fatal("expected no sources")
+	}
+	sources := single.SourceList()
+	if len(sources) != 1 || sources[0].FullName() != "octo/blog" || !slices.Equal(sources[0].Labels, []string{"post"}) {
+		t.Fatalf("sources = %+v", sources)
+	}
+
+	multi := &GitHubConfig{
+		Username: "octo",
+		Labels:   []string{"post"},
+		Sources: []GitHubSourceConfig{
+			{Repository: "engineering"},
+			{Username: "design-team", Repository: "design", Labels: []string{}},
+		},
+	}
+	sources = multi.SourceList()
+	if len(sources) != 2 {
+		t.Fatalf("sources = %+v", sources)
+	}
+	if sources[0].FullName() != "octo/engineering" || !slices.Equal(sources[0].Labels, []string{"post"}) {
+		t.Fatalf("first source = %+v", sources[0])
+	}
+	if sources[1].FullName() != "design-team/design" || len(sources[1].Labels) != 0 {
+		t.Fatalf("second source = %+v", sources[1])
+	}
+
+	if source, ok := multi.Source("Design-Team", "DESIGN"); !ok || source.Repository != "design" {
+		t.Fatalf("source = %+v, %v", source, ok)
+	}
+	if _, ok := multi.Source("octo", "blog"); ok {
+		t.Fatal("expected unknown source")
+	}
+}
+
+func TestConfig_ForSource(t *testing.T) {
+	url := "/images/[:repository]/%Y"
+	conf := Config{
+		GitHub: &GitHubConfig{Username: "octo", Sources: []GitHubSourceConfig{{Repository: "engineering"}}},
+		Output: &OutputConfig{
+			Articles: &OutputArticlesConfig{Directory: "content/posts", Filename: "%Y-%m-%d.md"},
+			Images:   &OutputImagesConfig{Directory: "static/images/[:owner]/[:repository]/%Y", Filename: "[:id].png", BaseURL: &url},
+			Comments: &OutputCommentsConfig{Mode: CommentsModeData, Directory: "data/comments/[:repository]"},
+		},
+	}
+	source := GitHubSourceConfig{
+		Username:   "octo",
+		Repository: "engineering",
+		Labels:     []string{"blog"},
+		Output:     &OutputConfig{Articles: &OutputArticlesConfig{Directory: "content/[:repository]"}},
+	}
+
+	got := conf.ForSource(source)
+	if got.GitHub.Username != "octo" || got.GitHub.Repository != "engineering" || got.GitHub.HasSources() {
+		t.Fatalf("github = %+v", got.GitHub)
+	}
+	if !slices.Equal(got.GitHub.Labels, []string{"blog"}) {
+		t.Fatalf("labels = %v", got.GitHub.Labels)
+	}
+	if got.Output.Articles.Directory != "content/engineering" || got.Output.Articles.Filename != "%Y-%m-%d.md" {
+		t.Fatalf("articles = %+v", got.Output.Articles)
+	}
+	if got.Output.Images.Directory != "static/images/octo/engineering/%Y" || got.Output.Images.URL() != "/images/engineering/%Y" {
+		t.Fatalf("images = %+v", got.Output.Images)
+	}
+	if got.Output.Comments.Directory != "data/comments/engineering" {
+		t.Fatalf("comments = %+v", got.Output.Comments)
+	}
+
+	// The shared configuration is left untouched.
+	if conf.Output.Articles.Directory != "content/posts" || *conf.Output.Images.BaseURL != "/images/[:repository]/%Y" || conf.Output.Comments.Directory != "data/comments/[:repository]" {
+		t.Fatalf("shared output was modified: %+v", conf.Output)
+	}
+	if !conf.GitHub.HasSources() {
+		t.Fatal("shared github config was modified")
+	}
+}
+
+func TestConfigValidate_Sources(t *testing.T) {
+	newConf := func(sources ...GitHubSourceConfig) *Config {
+		return &Config{
+			GitHub: &GitHubConfig{Username: "octo", Sources: sources},
+			Output: &OutputConfig{
+				Articles: &OutputArticlesConfig{Directory: "content/posts", Filename: "%Y.md"},
+				Images:   &OutputImagesConfig{Directory: "static/images/[:repository]/%Y", Filename: "[:id].png"},
+			},
+		}
+	}
+
+	valid := newConf(GitHubSourceConfig{Repository: "engineering"}, GitHubSourceConfig{Repository: "design"})
+	if err := valid.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+
+	tests := map[string]*Config{
+		"missing repository": newConf(GitHubSourceConfig{Repository: "engineering"}, GitHubSourceConfig{}),
+		"duplicate source":   newConf(GitHubSourceConfig{Repository: "engineering"}, GitHubSourceConfig{Username: "Octo", Repository: "Engineering"}),
+		"invalid override":   newConf(GitHubSourceConfig{Repository: "engineering", Output: &OutputConfig{Images: &OutputImagesConfig{Resolve: "camo"}}}),
+	}
+	shared := newConf(GitHubSourceConfig{Repository: "engineering"}, GitHubSourceConfig{Repository: "design"})
+	shared.Output.Images.Directory = "static/images/%Y"
+	tests["shared image directory"] = shared
+	sharedData := newConf(GitHubSourceConfig{Repository: "engineering"}, GitHubSourceConfig{Repository: "design"})
+	sharedData.Output.Comments = &OutputCommentsConfig{Mode: CommentsModeData}
+	tests["shared comments directory"] = sharedData
+
+	for name, conf := range tests {
+		t.Run(name, func(t *testing.T) {
+			if err := conf.validate(); err == nil {
+				t.Fatal("expected validation error")
+			}
+		})
+	}
+
+	overridden := newConf(GitHubSourceConfig{Repository: "engineering"}, GitHubSourceConfig{Repository: "design"})
+	overridden.Output.Images.Directory = "static/images/%Y"
+	overridden.GitHub.Sources[1].Output = &OutputConfig{Images: &OutputImagesConfig{Directory: "static/design/%Y"}}
+	if err := overridden.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+}
@@ -1,33 +1,244 @@
 package config
 
 import (
+	"fmt"
+	"strconv"
 	"strings"
+	"sync"
+	"text/template"
 	"time"
 )
 
-// compile compiles a template with the given datetime.
-// %Y - Year with century as a decimal number.
-// %m - Month as a decimal number [01,12].
-// %d - Day of the month as a decimal number [01,31].
-// %H - Hour (24-hour clock) as a decimal number [00,23].
-// %M - Minute as a decimal number [00,59].
-// %S - Second as a decimal number [00,59].
-func CompileTimeTemplate(datetime time.Time, template string) string {
-	now := datetime
-
-	year := now.Format("2006")
-	month := now.Format("01")
-	day := now.Format("02")
-	hour := now.Format("15")
-	minute := now.Format("04")
-	second := now.Format("05")
-
-	template = strings.ReplaceAll(template, "%Y", year)
-	template = strings.ReplaceAll(template, "%m", month)
-	template = strings.ReplaceAll(template, "%d", day)
-	template = strings.ReplaceAll(template, "%H", hour)
-	template = strings.ReplaceAll(template, "%M", minute)
-	template = strings.ReplaceAll(template, "%S", second)
-
-	return template
+// PathTemplateData is what output path templates are expanded with. Go
+// template actions such as {{ .Date.Year }} access its fields.
+type PathTemplateData struct {
+	// Date is the article date in output.timezone.
+	Date time.Time
+	// Number is the issue number.
+	Number int
+	// Title is the article title as written, as a single path segment.
+	Title string
+	// Slug is the slug front-matter value or the title, slugified.
+	Slug string
+	// Author is the login of the issue author, slugified.
+	Author string
+	// Milestone is the milestone title, slugified.
+	Milestone string
+	// Labels are the labels written to the front matter, each as a single
+	// path segment.
+	Labels []string
+}
+
+// pathPlaceholders maps the article placeholders of output paths to the
+// actions they stand for. Other [:name] placeholders, such as [:id], are
+// kept as text.
+var pathPlaceholders = map[string]string{
+	"[:number]":    "{{ $.Number }}",
+	"[:slug]":      "{{ $.Slug }}",
+	"[:author]":    "{{ $.Author }}",
+	"[:milestone]": "{{ $.Milestone }}",
+}
+
+// strftimeDirectives are the strftime directives output paths accept.
+//
+//	%Y - Year with century, such as 2024.
+//	%y - Year without century [00,99].
+//	%m - Month [01,12].
+//	%d - Day of the month [01,31].
+//	%H - Hour (24-hour clock) [00,23].
+//	%M - Minute [00,59].
+//	%S - Second [00,59].
+//	%j - Day of the year [001,366].
+//	%B - Month name, such as January.
+//	%b - Abbreviated month name, such as Jan.
+//	%A - Weekday name, such as Monday.
+//	%a - Abbreviated weekday name, such as Mon.
+//	%G - ISO 8601 week-based year.
+//	%V - ISO 8601 week number [01,53].
+//	%u - ISO 8601 weekday [1,7], where Monday is 1.
+//	%% - A literal %.
+//
+// A % followed by anything else is kept as text, so that percent-encoded
+// bytes such as %20 or %E3 are written as they are. A percent-encoded byte
+// that starts with a directive, such as %AB, must be written as %%AB.
+var strftimeDirectives = map[byte]func(time.Time) string{
+	'Y': func(t time.Time) string { return t.Format("2006") },
+	'y': func(t time.Time) string { return t.Format("06") },
+	'm': func(t time.Time) string { return t.Format("01") },
+	'd': func(t time.Time) string { return t.Format("02") },
+	'H': func(t time.Time) string { return t.Format("15") },
+	'M': func(t time.Time) string { return t.Format("04") },
+	'S': func(t time.Time) string { return t.Format("05") },
+	'j': func(t time.Time) string { return fmt.Sprintf("%03d", t.YearDay()) },
+	'B': func(t time.Time) string { return t.Format("January") },
+	'b': func(t time.Time) string { return t.Format("Jan") },
+	'A': func(t time.Time) string { return t.Format("Monday") },
+	'a': func(t time.Time) string { return t.Format("Mon") },
+	'G': func(t time.Time) string { year, _ := t.ISOWeek(); return fmt.Sprintf("%04d", year) },
+	'V': func(t time.Time) string { _, week := t.ISOWeek(); return fmt.Sprintf("%02d", week) },
+	'u': func(t time.Time) string { return strconv.Itoa((int(t.Weekday())+6)%7 + 1) },
+	'%': func(time.Time) string { return "%" },
+}
+
+// directiveAt returns the strftime directive that starts at text[i], if any.
+func directiveAt(text string, i int) (func(time.Time) string, bool) {
+	if text[i] != '%' || i+1 == len(text) {
+		return nil, false
+	}
+	directive, ok := strftimeDirectives[text[i+1]]
+	return directive, ok
+}
+
+func isDirectiveAt(text string, i int) bool {
+	_, ok := directiveAt(text, i)
+	return ok
+}
+
+// Strftime formats t with the strftime directives of output paths. Unknown
+// directives are kept as text.
+func Strftime(format string, t time.Time) string {
+	var b strings.Builder
+	for i := 0; i < len(format); i++ {
+		directive, ok := directiveAt(format, i)
+		if !ok {
+			b.WriteByte(format[i])
+			continue
+		}
+		b.WriteString(directive(t))
+		i++
+	}
+	return b.String()
+}
+
+// PathTemplate is a parsed output path template. It accepts the strftime
+// directives of Strftime, the [:number], [:slug], [:author] and [:milestone]
+// placeholders, and Go text/template actions with PathTemplateData as data.
+type PathTemplate struct {
+	text     string
+	template *template.Template
+}
+
+// pathTemplates caches parsed templates by their text, since every article
+// expands the same few paths.
+var pathTemplates sync.Map
+
+// ParsePathTemplate parses an output path template. Strftime directives and
+// placeholders are only expanded outside of {{ }} actions.
+func ParsePathTemplate(text string) (*PathTemplate, error) {
+	if cached, ok := pathTemplates.Load(text); ok {
+		return cached.(*PathTemplate), nil
+	}
+	source, err := translatePathTemplate(text)
+	if err != nil {
+		return nil, err
+	}
+	parsed, err := template.New("path").Funcs(template.FuncMap{
+		"strftime": Strftime,
+	}).Parse(source)
+	if err != nil {
+		return nil, fmt.Errorf("parse template %q: %w", text, err)
+	}
+	t := &PathTemplate{text: text, template: parsed}
+	pathTemplates.Store(text, t)
+	return t, nil
+}
+
+// Execute expands the template with data.
+func (t *PathTemplate) Execute(data PathTemplateData) (string, error) {
+	var b strings.Builder
+	if err := t.template.Execute(&b, data); err != nil {
+		return "", fmt.Errorf("expand template %q: %w", t.text, err)
+	}
+	return b.String(), nil
+}
+
+// samplePathTemplateData is what templates are tried with when the
+// configuration is loaded, so that mistakes such as unknown fields are found
+// before any article is generated.
+var samplePathTemplateData = PathTemplateData{
+	Date:      time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+	Number:    1,
+	Title:     "Title",
+	Slug:      "title",
+	Author:    "author",
+	Milestone: "milestone",
+	Labels:    []string{"label"},
+}
+
+// checkPathTemplate parses the template and tries it with sample data.
+func checkPathTemplate(text string) error {
+	parsed, err := ParsePathTemplate(text)
+	if err != nil {
+		return err
+	}
+	_, err = parsed.Execute(samplePathTemplateData)
+	return err
+}
+
+// translatePathTemplate turns the strftime directives and placeholders
+// outside of actions into actions. Text that is neither, including a % that
+// does not start a known directive, is kept as it is.
+func translatePathTemplate(text string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(text); {
+		switch {
+		case strings.HasPrefix(text[i:], "{{"):
+			end := actionEnd(text, i)
+			if end < 0 {
+				return "", fmt.Errorf("parse template %q: unclosed action", text)
+			}
+			b.WriteString(text[i:end])
+			i = end
+		case isDirectiveAt(text, i):
+			if text[i+1] == '%' {
+				b.WriteByte('%')
+			} else {
+				fmt.Fprintf(&b, `{{ strftime "%%%c" $.Date }}`, text[i+1])
+			}
+			i += 2
+		default:
+			if placeholder, action, ok := matchPlaceholder(text[i:]); ok {
+				b.WriteString(action)
+				i += len(placeholder)
+				continue
+			}
+			b.WriteByte(text[i])
+			i++
+		}
+	}
+	return b.String(), nil
+}
+
+// actionEnd returns the index just after the }} closing the action that
+// starts at start, skipping quoted strings, or -1 when it is not closed.
+func actionEnd(text string, start int) int {
+	var quote byte
+	for i := start + 2; i < len(text); i++ {
+		switch {
+		case quote != 0:
+			if text[i] == '\\' && quote != '`' {
+				i++
+			} else if text[i] == quote {
+				quote = 0
+			}
+		case text[i] == '"' || text[i] == '`' || text[i] == '\'':
+			quote = text[i]
+		case strings.HasPrefix(text[i:], "}}"):
+			return i + 2
+		}
+	}
+	return -1
+}
+
+// matchPlaceholder returns the placeholder text starts with and its action.
+func matchPlaceholder(text string) (string, string, bool) {
+	if !strings.HasPrefix(text, "[:") {
+		return "", "", false
+	}
+	for placeholder, action := range pathPlaceholders {
+		if strings.HasPrefix(text, placeholder) {
+			return placeholder, action, true
+		}
+	}
+	return "", "", false
 }
@@ -5,37 +5,105 @@ import (
 	"time"
 )
 
-func Test_CompileTimeTemplate(t *testing.T) {
-	type args struct {
-		datetime time.Time
+func TestStrftime(t *testing.T) {
+	// 2021-01-01 is a Friday in week 53 of ISO year 2020.
+	datetime := time.Date(2021, 1, 1, 13, 4, 5, 0, time.UTC)
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"%Y-%m-%d %H:%M:%S", "2021-01-01 13:04:05"},
+		{"content/posts/%Y/%m/%d", "content/posts/2021/01/01"},
+		{"%y", "21"},
+		{"%j", "001"},
+		{"%B %b", "January Jan"},
+		{"%A %a", "Friday Fri"},
+		{"%G-W%V-%u", "2020-W53-5"},
+		{"100%%", "100%"},
+		{"%%Y", "%Y"},
+		{"%Q", "%Q"},
+		{"100%", "100%"},
+		{"/images/%E3%81%82%20%Y", "/images/%E3%81%82%202021"},
+		{"/images/%%AB%%a0/%A/%b", "/images/%AB%a0/Friday/Jan"},
+		{"%b01/%Bab/%a1/%d0", "Jan01/Januaryab/Fri1/010"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			if got := Strftime(tt.format, datetime); got != tt.want {
+				t.Errorf("Strftime() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Sunday is the last day of the ISO week.
+	if got := Strftime("%u %V", time.Date(2024, 12, 29, 0, 0, 0, 0, time.UTC)); got != "7 52" {
+		t.Errorf("Strftime(%%u %%V) = %v", got)
+	}
+}
+
+func TestPathTemplate_Execute(t *testing.T) {
+	data := PathTemplateData{
+		Date:   time.Date(2024, 12, 30, 0, 0, 0, 0, time.UTC),
+		Number: 7,
+		Slug:   "hello-world",
+		Labels: []string{"go"},
+	}
+	tests := []struct {
 		template string
+		want     string
+	}{
+		{"content/posts/%Y/%m/[:slug].md", "content/posts/2024/12/hello-world.md"},
+		{"{{ .Date.Year }}/{{ .Slug }}", "2024/hello-world"},
+		{"%G/week-%V/[:number]", "2025/week-01/7"},
+		{`{{ if .Labels }}{{ index .Labels 0 }}{{ else }}misc{{ end }}/%d`, "go/30"},
+		{`{{ "}}" }}-%Y`, "}}-2024"},
+		{"[:owner]/[:repository]", "[:owner]/[:repository]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.template, func(t *testing.T) {
+			parsed, err := ParsePathTemplate(tt.template)
+			if err != nil {
+				t.Fatalf("ParsePathTemplate() error = %v", err)
+			}
+			got, err := parsed.Execute(data)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Execute() = %v, want %v", got, tt.want)
+			}
+		})
 	}
+}
+
+func TestConfigValidate_PathTemplates(t *testing.T) {
 	tests := []struct {
-		name string
-		args args
-		want string
+		name    string
+		output  *OutputConfig
+		sources []GitHubSourceConfig
+		wantErr bool
 	}{
+		{name: "defaults", output: NewOutputConfig()},
+		{name: "go template", output: &OutputConfig{Articles: &OutputArticlesConfig{Directory: "content/{{ .Date.Year }}", Filename: "{{ .Slug }}.md"}}},
+		{name: "unknown directive", output: &OutputConfig{Articles: &OutputArticlesConfig{Filename: "%Y-%Q.md"}}},
+		{name: "encoded url", output: &OutputConfig{Images: &OutputImagesConfig{BaseURL: Ptr("/%E3%81%82%20images/%Y")}}},
+		{name: "unknown field", output: &OutputConfig{Images: &OutputImagesConfig{Directory: "static/{{ .Repository }}"}}, wantErr: true},
+		{name: "unclosed action", output: &OutputConfig{Images: &OutputImagesConfig{BaseURL: Ptr("/images/{{ .Slug")}}, wantErr: true},
+		{name: "comments directory", output: &OutputConfig{Comments: &OutputCommentsConfig{Mode: CommentsModeData, Directory: "data/{{ .Date.Nope }}"}}, wantErr: true},
 		{
-			name: "Test compile",
-			args: args{
-				datetime: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
-				template: "%Y-%m-%d",
-			},
-			want: "2021-01-01",
-		},
-		{
-			name: "Compile with filepath string",
-			args: args{
-				datetime: time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
-				template: "content/posts/%Y/%m/%d",
-			},
-			want: "content/posts/2021/01/01",
+			name:   "source override",
+			output: NewOutputConfig(),
+			sources: []GitHubSourceConfig{{Username: "owner", Repository: "repo", Output: &OutputConfig{
+				Articles: &OutputArticlesConfig{Filename: "{{ .Nope }}.md"},
+			}}},
+			wantErr: true,
 		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			if got := CompileTimeTemplate(tt.args.datetime, tt.args.template); got != tt.want {
-				t.Errorf("compile() = %v, want %v", got, tt.want)
+			conf := &Config{GitHub: &GitHubConfig{Sources: tt.sources}, Output: tt.output}
+			if err := conf.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("validate error = %v, wantErr %v", err, tt.wantErr)
 			}
 		})
 	}
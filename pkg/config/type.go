@@ -1,5 +1,13 @@
 package config
 
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+)
+
 // Config package is a package for configuration.
 // If you change the configuration, you also need to change ``config.Generate()`` (config.go).
 
@@ -10,19 +18,148 @@ type Config struct {
 }
 
 type GitHubConfig struct {
-	Username   string   `yaml:"username" mapstructure:"username"`
-	Repository string   `yaml:"repository" mapstructure:"repository"`
-	Labels     []string `yaml:"labels,omitempty" mapstructure:"labels"`
+	Username   string                `yaml:"username" mapstructure:"username"`
+	Repository string                `yaml:"repository" mapstructure:"repository"`
+	Labels     []string              `yaml:"labels,omitempty" mapstructure:"labels"`
+	API        string                `yaml:"api,omitempty" mapstructure:"api"`
+	BaseURL    string                `yaml:"baseURL,omitempty" mapstructure:"baseURL"`
+	UploadURL  string                `yaml:"uploadURL,omitempty" mapstructure:"uploadURL"`
+	TokenFile  string                `yaml:"tokenFile,omitempty" mapstructure:"tokenFile"`
+	App        *GitHubAppConfig      `yaml:"app,omitempty" mapstructure:"app"`
+	Retry      *GitHubRetryConfig    `yaml:"retry,omitempty" mapstructure:"retry"`
+	Filter     *GitHubFilterConfig   `yaml:"filter,omitempty" mapstructure:"filter"`
+	Trust      *GitHubTrustConfig    `yaml:"trust,omitempty" mapstructure:"trust"`
+	Approval   *GitHubApprovalConfig `yaml:"approval,omitempty" mapstructure:"approval"`
+	Sources    []GitHubSourceConfig  `yaml:"sources,omitempty" mapstructure:"sources"`
+}
+
+// GitHubSourceConfig is one repository of github.sources. An empty username
+// defaults to github.username, and nil labels and filter default to
+// github.labels and github.filter. Output overrides the matching output
+// settings for the articles of this repository.
+type GitHubSourceConfig struct {
+	Username   string              `yaml:"username,omitempty" mapstructure:"username"`
+	Repository string              `yaml:"repository" mapstructure:"repository"`
+	Labels     []string            `yaml:"labels,omitempty" mapstructure:"labels"`
+	Filter     *GitHubFilterConfig `yaml:"filter,omitempty" mapstructure:"filter"`
+	Output     *OutputConfig       `yaml:"output,omitempty" mapstructure:"output"`
+}
+
+// GitHubFilterConfig selects issues beyond github.labels. Dates are either
+// "2006-01-02", meaning midnight UTC, or RFC 3339 timestamps.
+type GitHubFilterConfig struct {
+	State         string   `yaml:"state,omitempty" mapstructure:"state"`
+	ExcludeLabels []string `yaml:"excludeLabels,omitempty" mapstructure:"excludeLabels"`
+	AnyLabels     []string `yaml:"anyLabels,omitempty" mapstructure:"anyLabels"`
+	Authors       []string `yaml:"authors,omitempty" mapstructure:"authors"`
+	Milestone     string   `yaml:"milestone,omitempty" mapstructure:"milestone"`
+	CreatedAfter  string   `yaml:"createdAfter,omitempty" mapstructure:"createdAfter"`
+	CreatedBefore string   `yaml:"createdBefore,omitempty" mapstructure:"createdBefore"`
+}
+
+const (
+	// IssueStateAll selects open and closed issues.
+	IssueStateAll = "all"
+	// IssueStateOpen selects open issues only.
+	IssueStateOpen = "open"
+	// IssueStateClosed selects closed issues only.
+	IssueStateClosed = "closed"
+)
+
+// GitHubTrustConfig restricts publishing to issues opened by trusted authors.
+// An author is trusted when listed in users, when the issue's
+// author_association is one of associations, or, with checkPermission, when
+// the author has write access to the repository. Issues carrying the
+// approval label count as trusted.
+type GitHubTrustConfig struct {
+	Untrusted       string   `yaml:"untrusted,omitempty" mapstructure:"untrusted"`
+	Associations    []string `yaml:"associations,omitempty" mapstructure:"associations"`
+	Users           []string `yaml:"users,omitempty" mapstructure:"users"`
+	CheckPermission bool     `yaml:"checkPermission,omitempty" mapstructure:"checkPermission"`
+	ApprovalLabel   string   `yaml:"approvalLabel,omitempty" mapstructure:"approvalLabel"`
+}
+
+const (
+	// UntrustedPublish publishes issues of untrusted authors like any other.
+	UntrustedPublish = "publish"
+	// UntrustedDraft saves issues of untrusted authors as drafts.
+	UntrustedDraft = "draft"
+	// UntrustedSkip does not generate articles for issues of untrusted
+	// authors.
+	UntrustedSkip = "skip"
+)
+
+// DefaultTrustedAssociations are the author associations trusted when
+// github.trust.associations is not set.
+var DefaultTrustedAssociations = []string{"OWNER", "MEMBER", "COLLABORATOR"}
+
+// authorAssociations are the values GitHub reports as author_association.
+var authorAssociations = []string{
+	"OWNER", "MEMBER", "COLLABORATOR", "CONTRIBUTOR",
+	"FIRST_TIME_CONTRIBUTOR", "FIRST_TIMER", "MANNEQUIN", "NONE",
 }
 
+// GitHubApprovalConfig only publishes issues carrying the approval label when
+// the label was applied by a user with write access to the repository.
+type GitHubApprovalConfig struct {
+	Label string `yaml:"label" mapstructure:"label"`
+}
+
+type GitHubAppConfig struct {
+	ID             int64  `yaml:"id" mapstructure:"id"`
+	InstallationID int64  `yaml:"installationId,omitempty" mapstructure:"installationId"`
+	PrivateKey     string `yaml:"privateKey,omitempty" mapstructure:"privateKey"`
+}
+
+// GitHubRetryConfig controls how failed GitHub API requests and image
+// downloads are retried. Durations use Go syntax such as "1s" or "15m".
+type GitHubRetryConfig struct {
+	MaxRetries   *int   `yaml:"maxRetries,omitempty" mapstructure:"maxRetries"`
+	InitialDelay string `yaml:"initialDelay,omitempty" mapstructure:"initialDelay"`
+	MaxDelay     string `yaml:"maxDelay,omitempty" mapstructure:"maxDelay"`
+	OnRateLimit  string `yaml:"onRateLimit,omitempty" mapstructure:"onRateLimit"`
+	MaxWait      string `yaml:"maxWait,omitempty" mapstructure:"maxWait"`
+}
+
+const (
+	// RateLimitWait waits until an exceeded rate limit resets.
+	RateLimitWait = "wait"
+	// RateLimitFail fails as soon as a rate limit is exceeded.
+	RateLimitFail = "fail"
+)
+
+const (
+	// DefaultMaxRetries is the number of retries after the first attempt.
+	DefaultMaxRetries = 3
+	// DefaultRetryInitialDelay is the backoff before the first retry.
+	DefaultRetryInitialDelay = time.Second
+	// DefaultRetryMaxDelay caps the exponential backoff.
+	DefaultRetryMaxDelay = 30 * time.Second
+	// DefaultRateLimitMaxWait is the longest wait for a rate limit reset.
+	DefaultRateLimitMaxWait = 15 * time.Minute
+)
+
 type OutputConfig struct {
-	Articles *OutputArticlesConfig `yaml:"articles" mapstructure:"articles"`
-	Images   *OutputImagesConfig   `yaml:"images" mapstructure:"images"`
+	Articles    *OutputArticlesConfig    `yaml:"articles" mapstructure:"articles"`
+	Images      *OutputImagesConfig      `yaml:"images" mapstructure:"images"`
+	State       string                   `yaml:"state,omitempty" mapstructure:"state"`
+	Comments    *OutputCommentsConfig    `yaml:"comments,omitempty" mapstructure:"comments"`
+	FrontMatter *OutputFrontMatterConfig `yaml:"frontMatter,omitempty" mapstructure:"frontMatter"`
+	Publish     *OutputPublishConfig     `yaml:"publish,omitempty" mapstructure:"publish"`
+	// Timezone is the IANA time zone, such as "Asia/Tokyo", that dates are
+	// written in and path templates are expanded in. Issue times are written
+	// in UTC when it is not set.
+	Timezone string `yaml:"timezone,omitempty" mapstructure:"timezone"`
 }
 
 type OutputArticlesConfig struct {
 	Directory string `yaml:"directory" mapstructure:"directory"`
 	Filename  string `yaml:"filename" mapstructure:"filename"`
+	// Collision decides what happens when two issues resolve to the same
+	// article or image path in one run.
+	Collision string `yaml:"collision,omitempty" mapstructure:"collision"`
+	// Date selects the issue time used as the article date and in paths.
+	Date string `yaml:"date,omitempty" mapstructure:"date"`
 }
 
 type OutputImagesConfig struct {
@@ -30,8 +167,167 @@ type OutputImagesConfig struct {
 	Filename  string   `yaml:"filename" mapstructure:"filename"`
 	BaseURL   *string  `yaml:"url" mapstructure:"url"`
 	Targets   []string `yaml:"targets" mapstructure:"targets"`
+	Resolve   string   `yaml:"resolve,omitempty" mapstructure:"resolve"`
 }
 
+const (
+	// ImageResolveDirect downloads images from the URLs in the issue markdown.
+	ImageResolveDirect = "direct"
+	// ImageResolveHTML downloads user-attachments images through the signed
+	// URLs found in the HTML GitHub renders for the issue.
+	ImageResolveHTML = "html"
+)
+
+const (
+	// CollisionError fails to save an article whose path is already used by
+	// another issue.
+	CollisionError = "error"
+	// CollisionNumber appends the issue number to the path of the article
+	// saved later.
+	CollisionNumber = "number"
+)
+
+const (
+	// DateSourceCreated dates articles by the creation time of the issue.
+	DateSourceCreated = "created"
+	// DateSourceClosed dates articles by the time the issue was last closed.
+	DateSourceClosed = "closed"
+	// DateSourceFirstClosed dates articles by the time the issue was first
+	// closed, so that reopening and closing it again keeps the date.
+	DateSourceFirstClosed = "first_closed"
+	// DateSourceFrontMatter expects the date in the front matter of the
+	// issue body.
+	DateSourceFrontMatter = "front_matter"
+)
+
+const (
+	// GitHubAPIREST selects the GitHub REST API for fetching issues.
+	GitHubAPIREST = "rest"
+	// GitHubAPIGraphQL selects the GitHub GraphQL API for fetching issues.
+	GitHubAPIGraphQL = "graphql"
+)
+
+type OutputCommentsConfig struct {
+	Mode       string `yaml:"mode" mapstructure:"mode"`
+	Heading    string `yaml:"heading,omitempty" mapstructure:"heading"`
+	Directory  string `yaml:"directory,omitempty" mapstructure:"directory"`
+	AuthorOnly bool   `yaml:"authorOnly,omitempty" mapstructure:"authorOnly"`
+}
+
+// OutputFrontMatterConfig controls which GitHub fields are written to the
+// front matter of articles.
+type OutputFrontMatterConfig struct {
+	// Mapping maps GitHub fields to the front-matter keys they are written
+	// to. Fields not listed keep their default key. A key ending in "[]" is
+	// always written as a list, and an empty key omits the field.
+	Mapping map[string]string `yaml:"mapping,omitempty" mapstructure:"mapping"`
+	// Taxonomies route labels by prefix to other front-matter keys. Labels
+	// matching no rule stay in the labels field.
+	Taxonomies []OutputTaxonomyConfig `yaml:"taxonomies,omitempty" mapstructure:"taxonomies"`
+}
+
+// OutputTaxonomyConfig routes the labels starting with Prefix to Key.
+type OutputTaxonomyConfig struct {
+	// Prefix is matched case-insensitively and stripped from the label.
+	Prefix string `yaml:"prefix" mapstructure:"prefix"`
+	// Key is the front-matter key the labels are listed under. An empty key
+	// drops the labels.
+	Key string `yaml:"key" mapstructure:"key"`
+}
+
+// OutputPublishConfig decides which issues are published, saved as drafts or
+// skipped, and whether drafts are written at all.
+type OutputPublishConfig struct {
+	// StateReasons maps the state_reason of issues, such as "not_planned",
+	// to PublishPublish, PublishDraft or PublishSkip. Reasons not listed are
+	// published.
+	StateReasons map[string]string `yaml:"stateReasons,omitempty" mapstructure:"stateReasons"`
+	// DraftLabels save the issues carrying any of the labels as drafts.
+	DraftLabels []string `yaml:"draftLabels,omitempty" mapstructure:"draftLabels"`
+	// Scheduled is the action for articles whose publishDate front matter is
+	// in the future.
+	Scheduled string `yaml:"scheduled,omitempty" mapstructure:"scheduled"`
+	// Expired is the action for articles whose expiryDate front matter has
+	// passed.
+	Expired string `yaml:"expired,omitempty" mapstructure:"expired"`
+	// Drafts decides whether draft articles are written.
+	Drafts string `yaml:"drafts,omitempty" mapstructure:"drafts"`
+}
+
+const (
+	// PublishPublish saves the article as usual.
+	PublishPublish = "publish"
+	// PublishDraft saves the article as a draft.
+	PublishDraft = "draft"
+	// PublishSkip does not generate the article.
+	PublishSkip = "skip"
+)
+
+const (
+	// DraftsWrite writes draft articles with draft: true.
+	DraftsWrite = "write"
+	// DraftsSkip does not generate draft articles.
+	DraftsSkip = "skip"
+)
+
+// stateReasons are the state_reason values GitHub reports for issues.
+var stateReasons = []string{"completed", "not_planned", "duplicate", "reopened"}
+
+// GitHub fields that output.frontMatter.mapping can write to the front matter.
+const (
+	FrontMatterFieldAuthor      = "author"
+	FrontMatterFieldMilestone   = "milestone"
+	FrontMatterFieldLabels      = "labels"
+	FrontMatterFieldDraft       = "draft"
+	FrontMatterFieldNumber      = "number"
+	FrontMatterFieldURL         = "url"
+	FrontMatterFieldAssignees   = "assignees"
+	FrontMatterFieldStateReason = "state_reason"
+	FrontMatterFieldCreated     = "created"
+	FrontMatterFieldUpdated     = "updated"
+	FrontMatterFieldClosed      = "closed"
+	FrontMatterFieldReactions   = "reactions"
+)
+
+// FrontMatterFields lists every field output.frontMatter.mapping accepts.
+var FrontMatterFields = []string{
+	FrontMatterFieldAuthor, FrontMatterFieldMilestone, FrontMatterFieldLabels, FrontMatterFieldDraft,
+	FrontMatterFieldNumber, FrontMatterFieldURL, FrontMatterFieldAssignees, FrontMatterFieldStateReason,
+	FrontMatterFieldCreated, FrontMatterFieldUpdated, FrontMatterFieldClosed, FrontMatterFieldReactions,
+}
+
+// defaultFrontMatterKeys are the keys fields are written to when
+// output.frontMatter.mapping does not list them. Other fields are omitted.
+var defaultFrontMatterKeys = map[string]string{
+	FrontMatterFieldAuthor:    "author",
+	FrontMatterFieldMilestone: "categories",
+	FrontMatterFieldLabels:    "tags",
+	FrontMatterFieldDraft:     "draft",
+	FrontMatterFieldUpdated:   "lastmod",
+}
+
+// reservedFrontMatterKeys are written from the issue regardless of the
+// mapping and cannot be mapped to.
+var reservedFrontMatterKeys = []string{"title", "date", "repository"}
+
+const (
+	// CommentsModeAppend appends comments to the article under a heading.
+	CommentsModeAppend = "append"
+	// CommentsModeData writes comments to a separate data file per article.
+	CommentsModeData = "data"
+)
+
+const (
+	// DefaultCommentsHeading is the heading used by CommentsModeAppend.
+	DefaultCommentsHeading = "Comments"
+	// DefaultCommentsDirectory is the directory used by CommentsModeData.
+	DefaultCommentsDirectory = "data/comments"
+)
+
+// DefaultStateDirectory is the directory where generation state such as the
+// incremental sync state is stored when output.state is not set.
+const DefaultStateDirectory = ".gic"
+
 var defaultImageTargets = []string{
 	"https://github.com/user-attachments/",
 	"https://user-images.githubusercontent.com/",
@@ -73,8 +369,281 @@ type HugoURLConfig struct {
 	Images      string `yaml:"images" mapstructure:"images"`
 }
 
+const defaultGitHubWebURL = "https://github.com"
+
 func (c *GitHubConfig) RepositoryURL() string {
-	return "https://github.com/" + c.Username + "/" + c.Repository
+	return c.WebURL() + "/" + c.Username + "/" + c.Repository
+}
+
+// FullName returns the repository in "owner/repository" form.
+func (s GitHubSourceConfig) FullName() string {
+	return s.Username + "/" + s.Repository
+}
+
+// HasSources reports whether github.sources lists the repositories to read
+// issues from instead of github.username and github.repository.
+func (c *GitHubConfig) HasSources() bool {
+	return c != nil && len(c.Sources) > 0
+}
+
+// SourceList returns every repository issues are read from: the entries of
+// github.sources with defaults applied, or the single repository of
+// github.username and github.repository.
+func (c *GitHubConfig) SourceList() []GitHubSourceConfig {
+	if c == nil {
+		return nil
+	}
+	if !c.HasSources() {
+		return []GitHubSourceConfig{{Username: c.Username, Repository: c.Repository, Labels: c.Labels, Filter: c.Filter}}
+	}
+	sources := make([]GitHubSourceConfig, 0, len(c.Sources))
+	for _, source := range c.Sources {
+		if source.Username == "" {
+			source.Username = c.Username
+		}
+		if source.Labels == nil {
+			source.Labels = c.Labels
+		}
+		if source.Filter == nil {
+			source.Filter = c.Filter
+		}
+		sources = append(sources, source)
+	}
+	return sources
+}
+
+// Source returns the entry of SourceList for the given repository. Names are
+// compared case-insensitively like GitHub does.
+func (c *GitHubConfig) Source(username, repository string) (GitHubSourceConfig, bool) {
+	for _, source := range c.SourceList() {
+		if strings.EqualFold(source.Username, username) && strings.EqualFold(source.Repository, repository) {
+			return source, true
+		}
+	}
+	return GitHubSourceConfig{}, false
+}
+
+// IsEnterprise reports whether a GitHub Enterprise Server is configured.
+func (c *GitHubConfig) IsEnterprise() bool {
+	return c != nil && c.BaseURL != ""
+}
+
+// EnterpriseHost returns the host of the configured GitHub Enterprise Server,
+// or an empty string for github.com.
+func (c *GitHubConfig) EnterpriseHost() string {
+	if !c.IsEnterprise() {
+		return ""
+	}
+	parsed, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return ""
+	}
+	return parsed.Host
+}
+
+// WebURL returns the URL of the GitHub web interface without a trailing slash.
+func (c *GitHubConfig) WebURL() string {
+	if !c.IsEnterprise() {
+		return defaultGitHubWebURL
+	}
+	parsed, err := url.Parse(c.BaseURL)
+	if err != nil {
+		return defaultGitHubWebURL
+	}
+	return parsed.Scheme + "://" + parsed.Host
+}
+
+// UploadBaseURL returns the upload API URL, which defaults to the base URL.
+func (c *GitHubConfig) UploadBaseURL() string {
+	if c == nil {
+		return ""
+	}
+	if c.UploadURL == "" {
+		return c.BaseURL
+	}
+	return c.UploadURL
+}
+
+// EnterpriseImageTargets returns the attachment URL prefixes of the
+// configured GitHub Enterprise Server, covering instances with and without
+// subdomain isolation.
+func (c *GitHubConfig) EnterpriseImageTargets() []string {
+	host := c.EnterpriseHost()
+	if host == "" {
+		return nil
+	}
+	web := c.WebURL()
+	scheme := strings.TrimSuffix(web, host)
+	return []string{
+		web + "/user-attachments/",
+		web + "/storage/user/",
+		scheme + "media." + host + "/",
+	}
+}
+
+// ImageTargetURLs returns output.images.targets, or the built-in GitHub
+// attachment URL rules extended with those of a configured GitHub Enterprise
+// Server when targets are not set.
+func (c *Config) ImageTargetURLs() []string {
+	if c.Output != nil && c.Output.Images != nil && c.Output.Images.Targets != nil {
+		return c.Output.Images.Targets
+	}
+	return slices.Concat(defaultImageTargets, c.GitHub.EnterpriseImageTargets())
+}
+
+// Retries returns the number of retries after the first attempt.
+func (c *GitHubRetryConfig) Retries() int {
+	if c == nil || c.MaxRetries == nil {
+		return DefaultMaxRetries
+	}
+	return *c.MaxRetries
+}
+
+// InitialDelayDuration returns the backoff before the first retry.
+func (c *GitHubRetryConfig) InitialDelayDuration() time.Duration {
+	if c == nil {
+		return DefaultRetryInitialDelay
+	}
+	return parseDurationOr(c.InitialDelay, DefaultRetryInitialDelay)
+}
+
+// MaxDelayDuration returns the upper bound of the exponential backoff.
+func (c *GitHubRetryConfig) MaxDelayDuration() time.Duration {
+	if c == nil {
+		return DefaultRetryMaxDelay
+	}
+	return parseDurationOr(c.MaxDelay, DefaultRetryMaxDelay)
+}
+
+// MaxWaitDuration returns the longest wait for a rate limit reset. Requests
+// whose rate limit resets later fail immediately.
+func (c *GitHubRetryConfig) MaxWaitDuration() time.Duration {
+	if c == nil {
+		return DefaultRateLimitMaxWait
+	}
+	return parseDurationOr(c.MaxWait, DefaultRateLimitMaxWait)
+}
+
+// RateLimitPolicy returns what to do when a rate limit is exceeded. It
+// defaults to RateLimitWait.
+func (c *GitHubRetryConfig) RateLimitPolicy() string {
+	if c == nil || c.OnRateLimit == "" {
+		return RateLimitWait
+	}
+	return c.OnRateLimit
+}
+
+func parseDurationOr(value string, fallback time.Duration) time.Duration {
+	if value == "" {
+		return fallback
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return fallback
+	}
+	return d
+}
+
+// IssueState returns the state of the selected issues. It defaults to
+// IssueStateAll.
+func (c *GitHubFilterConfig) IssueState() string {
+	if c == nil || c.State == "" {
+		return IssueStateAll
+	}
+	return c.State
+}
+
+// CreatedAfterTime returns the lower bound of the creation time, or the zero
+// time when it is not set.
+func (c *GitHubFilterConfig) CreatedAfterTime() time.Time {
+	if c == nil {
+		return time.Time{}
+	}
+	t, _ := ParseFilterDate(c.CreatedAfter)
+	return t
+}
+
+// CreatedBeforeTime returns the exclusive upper bound of the creation time,
+// or the zero time when it is not set.
+func (c *GitHubFilterConfig) CreatedBeforeTime() time.Time {
+	if c == nil {
+		return time.Time{}
+	}
+	t, _ := ParseFilterDate(c.CreatedBefore)
+	return t
+}
+
+// ParseFilterDate parses a date of github.filter. An empty value yields the
+// zero time.
+func ParseFilterDate(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+// UntrustedPolicy returns what happens to issues of untrusted authors. It
+// defaults to UntrustedPublish, which disables the author checks.
+func (c *GitHubTrustConfig) UntrustedPolicy() string {
+	if c == nil || c.Untrusted == "" {
+		return UntrustedPublish
+	}
+	return c.Untrusted
+}
+
+// TrustedAssociations returns the trusted author associations in upper case.
+func (c *GitHubTrustConfig) TrustedAssociations() []string {
+	if c == nil || c.Associations == nil {
+		return DefaultTrustedAssociations
+	}
+	associations := make([]string, 0, len(c.Associations))
+	for _, association := range c.Associations {
+		associations = append(associations, strings.ToUpper(association))
+	}
+	return associations
+}
+
+// Enabled reports whether issues need an approval to be published.
+func (c *GitHubApprovalConfig) Enabled() bool {
+	return c != nil && c.Label != ""
+}
+
+// ResolveStrategy returns how image URLs are resolved before downloading.
+// It defaults to ImageResolveDirect.
+func (c *OutputImagesConfig) ResolveStrategy() string {
+	if c == nil || c.Resolve == "" {
+		return ImageResolveDirect
+	}
+	return c.Resolve
+}
+
+// CollisionPolicy returns the policy for articles whose paths collide. It
+// defaults to CollisionError.
+func (c *OutputArticlesConfig) CollisionPolicy() string {
+	if c == nil || c.Collision == "" {
+		return CollisionError
+	}
+	return c.Collision
+}
+
+// DateSource returns the issue time used as the article date. It defaults to
+// DateSourceCreated.
+func (c *OutputArticlesConfig) DateSource() string {
+	if c == nil || c.Date == "" {
+		return DateSourceCreated
+	}
+	return c.Date
+}
+
+// IssueAPI returns the API used to fetch issues. It defaults to GitHubAPIREST.
+func (c *GitHubConfig) IssueAPI() string {
+	if c == nil || c.API == "" {
+		return GitHubAPIREST
+	}
+	return c.API
 }
 
 func NewConfig() *Config {
@@ -115,6 +684,122 @@ func NewOutputImagesConfig() *OutputImagesConfig {
 	}
 }
 
+// StateDirectory returns the directory used to persist generation state.
+func (c *OutputConfig) StateDirectory() string {
+	if c == nil || c.State == "" {
+		return DefaultStateDirectory
+	}
+	return c.State
+}
+
+// Location returns the time zone of output.timezone, or nil when it is not
+// set. Dates are then written in UTC, and dates written with an offset keep
+// it in paths. It fails when the zone is not known.
+func (c *OutputConfig) Location() (*time.Location, error) {
+	if c == nil || c.Timezone == "" {
+		return nil, nil
+	}
+	location, err := time.LoadLocation(c.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("output.timezone: %w", err)
+	}
+	return location, nil
+}
+
+// Key returns the front-matter key the field is written to and whether it is
+// always written as a list. An empty key means the field is omitted.
+func (c *OutputFrontMatterConfig) Key(field string) (key string, list bool) {
+	key, ok := "", false
+	if c != nil {
+		key, ok = c.Mapping[field]
+	}
+	if !ok {
+		key = defaultFrontMatterKeys[field]
+	}
+	key = strings.TrimSpace(key)
+	if name, found := strings.CutSuffix(key, "[]"); found {
+		return name, true
+	}
+	return key, false
+}
+
+// Taxonomy returns the first taxonomy rule whose prefix label starts with,
+// and the label without the prefix.
+func (c *OutputFrontMatterConfig) Taxonomy(label string) (OutputTaxonomyConfig, string, bool) {
+	if c == nil {
+		return OutputTaxonomyConfig{}, "", false
+	}
+	for _, taxonomy := range c.Taxonomies {
+		if len(label) >= len(taxonomy.Prefix) && strings.EqualFold(label[:len(taxonomy.Prefix)], taxonomy.Prefix) {
+			taxonomy.Key = strings.TrimSpace(taxonomy.Key)
+			return taxonomy, strings.TrimSpace(label[len(taxonomy.Prefix):]), true
+		}
+	}
+	return OutputTaxonomyConfig{}, "", false
+}
+
+// StateReasonAction returns the action for issues with the state_reason. It
+// defaults to PublishPublish.
+func (c *OutputPublishConfig) StateReasonAction(reason string) string {
+	if c == nil || reason == "" {
+		return PublishPublish
+	}
+	for key, action := range c.StateReasons {
+		if strings.EqualFold(key, reason) {
+			return action
+		}
+	}
+	return PublishPublish
+}
+
+// ScheduledAction returns the action for articles whose publishDate is in the
+// future. It defaults to PublishPublish.
+func (c *OutputPublishConfig) ScheduledAction() string {
+	if c == nil || c.Scheduled == "" {
+		return PublishPublish
+	}
+	return c.Scheduled
+}
+
+// ExpiredAction returns the action for articles whose expiryDate has passed.
+// It defaults to PublishPublish.
+func (c *OutputPublishConfig) ExpiredAction() string {
+	if c == nil || c.Expired == "" {
+		return PublishPublish
+	}
+	return c.Expired
+}
+
+// DraftsPolicy returns whether draft articles are written. It defaults to
+// DraftsWrite.
+func (c *OutputPublishConfig) DraftsPolicy() string {
+	if c == nil || c.Drafts == "" {
+		return DraftsWrite
+	}
+	return c.Drafts
+}
+
+// Enabled reports whether issue comments are included in the output.
+func (c *OutputCommentsConfig) Enabled() bool {
+	return c != nil && c.Mode != ""
+}
+
+// HeadingText returns the heading comments are appended under.
+func (c *OutputCommentsConfig) HeadingText() string {
+	if c == nil || c.Heading == "" {
+		return DefaultCommentsHeading
+	}
+	return c.Heading
+}
+
+// DataDirectory returns the directory comment data files are written to.
+func (c *OutputCommentsConfig) DataDirectory() string {
+	if c == nil || c.Directory == "" {
+		return DefaultCommentsDirectory
+	}
+	return c.Directory
+}
+
 func (c *OutputImagesConfig) URL() string {
 	if c == nil || c.BaseURL == nil {
 		return ""
@@ -1,6 +1,10 @@
 package config
 
-import "testing"
+import (
+	"slices"
+	"testing"
+	"time"
+)
 
 func TestConfigNormalize_LegacyHugoDirectoryFields(t *testing.T) {
 	conf := Config{
@@ -155,3 +159,523 @@ func TestOutputImagesConfig_TargetURLs_PreservesExplicitEmptyList(t *testing.T)
 		t.Fatalf("target count = %d", len(got))
 	}
 }
+
+func TestOutputConfig_StateDirectory(t *testing.T) {
+	var nilConf *OutputConfig
+	if got := nilConf.StateDirectory(); got != DefaultStateDirectory {
+		t.Fatalf("nil state directory = %q", got)
+	}
+
+	conf := &OutputConfig{}
+	if got := conf.StateDirectory(); got != DefaultStateDirectory {
+		t.Fatalf("default state directory = %q", got)
+	}
+
+	conf.State = "build/state"
+	if got := conf.StateDirectory(); got != "build/state" {
+		t.Fatalf("state directory = %q", got)
+	}
+}
+
+func TestGitHubConfig_IssueAPI(t *testing.T) {
+	var nilConf *GitHubConfig
+	if got := nilConf.IssueAPI(); got != GitHubAPIREST {
+		t.Fatalf("nil issue api = %q", got)
+	}
+
+	conf := &GitHubConfig{API: GitHubAPIGraphQL}
+	if got := conf.IssueAPI(); got != GitHubAPIGraphQL {
+		t.Fatalf("issue api = %q", got)
+	}
+}
+
+func TestConfigValidate_RejectsUnknownGitHubAPI(t *testing.T) {
+	conf := &Config{GitHub: &GitHubConfig{API: "soap"}}
+	if err := conf.validate(); err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	conf.GitHub.API = GitHubAPIGraphQL
+	if err := conf.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+}
+
+func TestOutputCommentsConfig_Defaults(t *testing.T) {
+	var nilConf *OutputCommentsConfig
+	if nilConf.Enabled() {
+		t.Fatal("nil comments config is enabled")
+	}
+	if got := nilConf.HeadingText(); got != DefaultCommentsHeading {
+		t.Fatalf("heading = %q", got)
+	}
+	if got := nilConf.DataDirectory(); got != DefaultCommentsDirectory {
+		t.Fatalf("directory = %q", got)
+	}
+
+	conf := &OutputCommentsConfig{Mode: CommentsModeData, Heading: "Updates", Directory: "data/discussion"}
+	if !conf.Enabled() {
+		t.Fatal("comments config is disabled")
+	}
+	if got := conf.HeadingText(); got != "Updates" {
+		t.Fatalf("heading = %q", got)
+	}
+	if got := conf.DataDirectory(); got != "data/discussion" {
+		t.Fatalf("directory = %q", got)
+	}
+}
+
+func TestConfigValidate_RejectsUnknownCommentsMode(t *testing.T) {
+	conf := &Config{Output: &OutputConfig{Comments: &OutputCommentsConfig{Mode: "inline"}}}
+	if err := conf.validate(); err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	conf.Output.Comments.Mode = CommentsModeAppend
+	if err := conf.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+}
+
+func TestConfigValidate_ImageResolve(t *testing.T) {
+	var nilConf *OutputImagesConfig
+	if got := nilConf.ResolveStrategy(); got != ImageResolveDirect {
+		t.Fatalf("resolve = %q", got)
+	}
+
+	conf := &Config{Output: &OutputConfig{Images: &OutputImagesConfig{Resolve: "camo"}}}
+	if err := conf.validate(); err == nil {
+		t.Fatal("expected validation error")
+	}
+
+	conf.Output.Images.Resolve = ImageResolveHTML
+	if err := conf.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+}
+
+func TestGitHubRetryConfig_Defaults(t *testing.T) {
+	var nilConf *GitHubRetryConfig
+	if got := nilConf.Retries(); got != DefaultMaxRetries {
+		t.Fatalf("retries = %d", got)
+	}
+	if got := nilConf.MaxWaitDuration(); got != DefaultRateLimitMaxWait {
+		t.Fatalf("max wait = %s", got)
+	}
+	if got := nilConf.RateLimitPolicy(); got != RateLimitWait {
+		t.Fatalf("rate limit policy = %q", got)
+	}
+
+	zero := 0
+	conf := &GitHubRetryConfig{MaxRetries: &zero, InitialDelay: "250ms"}
+	if got := conf.Retries(); got != 0 {
+		t.Fatalf("retries = %d", got)
+	}
+	if got := conf.InitialDelayDuration(); got != 250*time.Millisecond {
+		t.Fatalf("initial delay = %s", got)
+	}
+	if got := conf.MaxDelayDuration(); got != DefaultRetryMaxDelay {
+		t.Fatalf("max delay = %s", got)
+	}
+}
+
+func TestConfigValidate_Retry(t *testing.T) {
+	negative := -1
+	invalid := []*GitHubRetryConfig{
+		{MaxRetries: &negative},
+		{InitialDelay: "soon"},
+		{MaxWait: "-1m"},
+		{OnRateLimit: "ignore"},
+	}
+	for _, retry := range invalid {
+		conf := &Config{GitHub: &GitHubConfig{Retry: retry}}
+		if err := conf.validate(); err == nil {
+			t.Fatalf("expected validation error for %#v", retry)
+		}
+	}
+
+	conf := &Config{GitHub: &GitHubConfig{Retry: &GitHubRetryConfig{InitialDelay: "2s", MaxDelay: "1m", OnRateLimit: RateLimitFail, MaxWait: "1h"}}}
+	if err := conf.validate(); err != nil {
+		t.Fatalf("validate: %v", err)
+	}
+}
+
+func TestGitHubConfig_Enterprise(t *testing.T) {
+	conf := &GitHubConfig{Username: "user", Repository: "repo"}
+	if conf.IsEnterprise() {
+		t.Fatal("github.com config is enterprise")
+	}
+	if got := conf.RepositoryURL(); got != "https://github.com/user/repo" {
+		t.Fatalf("repository url = %q", got)
+	}
+	if got := conf.EnterpriseImageTargets(); got != nil {
+		t.Fatalf("enterprise targets = %#v", got)
+	}
+
+	conf.BaseURL = "https://ghe.example.com/api/v3/"
+	if !conf.IsEnterprise() {
+		t.Fatal("enterprise config is not enterprise")
+	}
+	if got := conf.RepositoryURL(); got != "https://ghe.example.com/user/repo" {
+		t.Fatalf("repository url = %q", got)
+	}
+	if got := conf.EnterpriseHost(); got != "ghe.example.com" {
+		t.Fatalf("enterprise host = %q", got)
+	}
+	if got := conf.UploadBaseURL(); got != conf.BaseURL {
+		t.Fatalf("upload url = %q", got)
+	}
+	conf.UploadURL = "https://ghe.example.com/api/uploads/"
+	if got := conf.UploadBaseURL(); got != conf.UploadURL {
+		t.Fatalf("upload url = %q", got)
+	}
+
+	want := []string{
+		"https://ghe.example.com/user-attachments/",
+		"https://ghe.example.com/storage/user/",
+		"https://media.ghe.example.com/",
+	}
+	got := conf.EnterpriseImageTargets()
+	if len(got) != len(want) {
+		t.Fatalf("enterprise targets = %#v", got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("enterprise targets = %#v", got)
+		}
+	}
+}
+
+func TestConfig_ImageTargetURLs(t *testing.T) {
+	conf := NewConfig()
+	if got := conf.ImageTargetURLs(); len(got) != len(defaultImageTargets) {
+		t.Fatalf("target urls = %#v", got)
+	}
+
+	conf.GitHub.BaseURL = "https://ghe.example.com/api/v3/"
+	got := conf.ImageTargetURLs()
+	if len(got) != len(defaultImageTargets)+3 || got[len(got)-1] != "https://media.ghe.example.com/" {
+		t.Fatalf("target urls = %#v", got)
+	}
+
+	conf.Output.Images.Targets = []string{}
+	if got := conf.ImageTargetURLs(); len(got) != 0 {
+		t.Fatalf("target urls = %#v", got)
+	}
+}
+
+func TestConfigValidate_GitHubURLs(t *testing.T) {
+	tests := []struct {
+		name    string
+		github  *GitHubConfig
+		wantErr bool
+	}{
+		{"github.com", &GitHubConfig{}, false},
+		{"enterprise", &GitHubConfig{BaseURL: "https://ghe.example.com/api/v3/"}, false},
+		{"upload without base", &GitHubConfig{UploadURL: "https://ghe.example.com/api/uploads/"}, true},
+		{"relative base", &GitHubConfig{BaseURL: "ghe.example.com"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &Config{GitHub: tt.github}
+			if err := conf.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGitHubFilterConfig(t *testing.T) {
+	var nilConf *GitHubFilterConfig
+	if got := nilConf.IssueState(); got != IssueStateAll {
+		t.Fatalf("state = %q", got)
+	}
+	if !nilConf.CreatedAfterTime().IsZero() {
+		t.Fatalf("created after = %s", nilConf.CreatedAfterTime())
+	}
+
+	conf := &GitHubFilterConfig{State: IssueStateClosed, CreatedAfter: "2024-01-01", CreatedBefore: "2024-02-01T09:00:00+09:00"}
+	if got := conf.IssueState(); got != IssueStateClosed {
+		t.Fatalf("state = %q", got)
+	}
+	if got := conf.CreatedAfterTime(); !got.Equal(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("created after = %s", got)
+	}
+	if got := conf.CreatedBeforeTime(); !got.Equal(time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("created before = %s", got)
+	}
+}
+
+func TestConfigValidate_Filter(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  *GitHubFilterConfig
+		wantErr bool
+	}{
+		{"unset", nil, false},
+		{"valid", &GitHubFilterConfig{State: IssueStateOpen, CreatedAfter: "2024-01-01"}, false},
+		{"unknown state", &GitHubFilterConfig{State: "merged"}, true},
+		{"invalid date", &GitHubFilterConfig{CreatedAfter: "01/02/2024"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &Config{GitHub: &GitHubConfig{Filter: tt.filter}}
+			if err := conf.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	// Filters of github.sources are validated too.
+	conf := &Config{GitHub: &GitHubConfig{Username: "octo", Sources: []GitHubSourceConfig{{Repository: "blog", Filter: &GitHubFilterConfig{State: "merged"}}}}}
+	if err := conf.validate(); err == nil {
+		t.Fatal("expected validation error")
+	}
+}
+
+func TestGitHubTrustConfig(t *testing.T) {
+	var unset *GitHubTrustConfig
+	if got := unset.UntrustedPolicy(); got != UntrustedPublish {
+		t.Fatalf("untrusted policy = %q", got)
+	}
+	if got := unset.TrustedAssociations(); !slices.Equal(got, DefaultTrustedAssociations) {
+		t.Fatalf("trusted associations = %v", got)
+	}
+
+	trust := &GitHubTrustConfig{Untrusted: UntrustedSkip, Associations: []string{"owner", "Member"}}
+	if got := trust.UntrustedPolicy(); got != UntrustedSkip {
+		t.Fatalf("untrusted policy = %q", got)
+	}
+	if got := trust.TrustedAssociations(); !slices.Equal(got, []string{"OWNER", "MEMBER"}) {
+		t.Fatalf("trusted associations = %v", got)
+	}
+}
+
+func TestConfigValidate_Trust(t *testing.T) {
+	tests := []struct {
+		name    string
+		trust   *GitHubTrustConfig
+		wantErr bool
+	}{
+		{"unset", nil, false},
+		{"valid", &GitHubTrustConfig{Untrusted: UntrustedDraft, Associations: []string{"owner", "CONTRIBUTOR"}}, false},
+		{"unknown policy", &GitHubTrustConfig{Untrusted: "hide"}, true},
+		{"unknown association", &GitHubTrustConfig{Untrusted: UntrustedSkip, Associations: []string{"MAINTAINER"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &Config{GitHub: &GitHubConfig{Trust: tt.trust}}
+			if err := conf.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGitHubApprovalConfig_Enabled(t *testing.T) {
+	var unset *GitHubApprovalConfig
+	if unset.Enabled() {
+		t.Fatal("nil approval must be disabled")
+	}
+	if (&GitHubApprovalConfig{}).Enabled() {
+		t.Fatal("approval without a label must be disabled")
+	}
+	if !(&GitHubApprovalConfig{Label: "approved"}).Enabled() {
+		t.Fatal("approval with a label must be enabled")
+	}
+}
+
+func TestOutputFrontMatterConfig_Key(t *testing.T) {
+	var unset *OutputFrontMatterConfig
+	if key, list := unset.Key(FrontMatterFieldMilestone); key != "categories" || list {
+		t.Fatalf("milestone key = %q, list = %v", key, list)
+	}
+	if key, _ := unset.Key(FrontMatterFieldNumber); key != "" {
+		t.Fatalf("number key = %q, want omitted by default", key)
+	}
+
+	frontMatter := &OutputFrontMatterConfig{Mapping: map[string]string{
+		FrontMatterFieldAuthor:    "authors[]",
+		FrontMatterFieldMilestone: "series",
+		FrontMatterFieldLabels:    "",
+	}}
+	if key, list := frontMatter.Key(FrontMatterFieldAuthor); key != "authors" || !list {
+		t.Fatalf("author key = %q, list = %v", key, list)
+	}
+	if key, _ := frontMatter.Key(FrontMatterFieldMilestone); key != "series" {
+		t.Fatalf("milestone key = %q", key)
+	}
+	if key, _ := frontMatter.Key(FrontMatterFieldLabels); key != "" {
+		t.Fatalf("labels key = %q, want omitted", key)
+	}
+	if key, _ := frontMatter.Key(FrontMatterFieldDraft); key != "draft" {
+		t.Fatalf("draft key = %q", key)
+	}
+}
+
+// sourceOverrideConfig returns a configuration whose only github.sources
+// entry overrides the default output with output.
+func sourceOverrideConfig(output *OutputConfig) *Config {
+	return &Config{
+		GitHub: &GitHubConfig{Sources: []GitHubSourceConfig{{Username: "octo", Repository: "blog", Output: output}}},
+		Output: NewOutputConfig(),
+	}
+}
+
+func TestConfigValidate_FrontMatter(t *testing.T) {
+	tests := []struct {
+		name    string
+		mapping map[string]string
+		wantErr bool
+	}{
+		{"unset", nil, false},
+		{"valid", map[string]string{"author": "authors[]", "milestone": "series", "labels": "keywords", "number": "issue"}, false},
+		{"swapped defaults", map[string]string{"milestone": "tags", "labels": "categories"}, false},
+		{"unknown field", map[string]string{"body": "summary"}, true},
+		{"duplicate key", map[string]string{"labels": "categories"}, true},
+		{"reserved key", map[string]string{"url": "title"}, true},
+		{"omitted draft", map[string]string{"draft": ""}, true},
+		{"draft list", map[string]string{"draft": "draft[]"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &OutputConfig{FrontMatter: &OutputFrontMatterConfig{Mapping: tt.mapping}}
+			if err := (&Config{Output: output}).validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := sourceOverrideConfig(output).validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("source override: validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidate_Taxonomies(t *testing.T) {
+	tests := []struct {
+		name       string
+		mapping    map[string]string
+		taxonomies []OutputTaxonomyConfig
+		wantErr    bool
+	}{
+		{"valid", nil, []OutputTaxonomyConfig{{Prefix: "series:", Key: "series"}, {Prefix: "tag:", Key: "tags"}, {Prefix: "status:"}}, false},
+		{"category with milestone moved", map[string]string{"milestone": "series"}, []OutputTaxonomyConfig{{Prefix: "category:", Key: "categories"}}, false},
+		{"category taken by milestone", nil, []OutputTaxonomyConfig{{Prefix: "category:", Key: "categories"}}, true},
+		{"missing prefix", nil, []OutputTaxonomyConfig{{Key: "series"}}, true},
+		{"reserved key", nil, []OutputTaxonomyConfig{{Prefix: "title:", Key: "title"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			output := &OutputConfig{FrontMatter: &OutputFrontMatterConfig{Mapping: tt.mapping, Taxonomies: tt.taxonomies}}
+			if err := (&Config{Output: output}).validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err := sourceOverrideConfig(output).validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("source override: validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidate_Collision(t *testing.T) {
+	var unset *OutputArticlesConfig
+	if got := unset.CollisionPolicy(); got != CollisionError {
+		t.Fatalf("collision policy = %q", got)
+	}
+	for collision, wantErr := range map[string]bool{"": false, CollisionError: false, CollisionNumber: false, "overwrite": true} {
+		output := &OutputConfig{Articles: &OutputArticlesConfig{Collision: collision}}
+		if err := (&Config{Output: output}).validate(); (err != nil) != wantErr {
+			t.Fatalf("collision %q: validate error = %v, wantErr %v", collision, err, wantErr)
+		}
+		if err := sourceOverrideConfig(output).validate(); (err != nil) != wantErr {
+			t.Fatalf("collision %q of a source: validate error = %v, wantErr %v", collision, err, wantErr)
+		}
+	}
+}
+
+func TestConfigValidate_DateSource(t *testing.T) {
+	var unset *OutputArticlesConfig
+	if got := unset.DateSource(); got != DateSourceCreated {
+		t.Fatalf("date source = %q", got)
+	}
+	for source, wantErr := range map[string]bool{"": false, DateSourceCreated: false, DateSourceClosed: false, DateSourceFirstClosed: false, DateSourceFrontMatter: false, "updated": true} {
+		output := &OutputConfig{Articles: &OutputArticlesConfig{Date: source}}
+		if err := (&Config{Output: output}).validate(); (err != nil) != wantErr {
+			t.Fatalf("date %q: validate error = %v, wantErr %v", source, err, wantErr)
+		}
+		if err := sourceOverrideConfig(output).validate(); (err != nil) != wantErr {
+			t.Fatalf("date %q of a source: validate error = %v, wantErr %v", source, err, wantErr)
+		}
+	}
+}
+
+func TestConfigValidate_Publish(t *testing.T) {
+	var unset *OutputPublishConfig
+	if unset.StateReasonAction("not_planned") != PublishPublish || unset.ScheduledAction() != PublishPublish || unset.ExpiredAction() != PublishPublish || unset.DraftsPolicy() != DraftsWrite {
+		t.Fatal("unset output.publish must publish everything")
+	}
+	publish := &OutputPublishConfig{StateReasons: map[string]string{"Not_Planned": PublishSkip}}
+	if got := publish.StateReasonAction("not_planned"); got != PublishSkip {
+		t.Fatalf("state reason action = %q", got)
+	}
+
+	tests := []struct {
+		name    string
+		publish *OutputPublishConfig
+		wantErr bool
+	}{
+		{name: "empty", publish: &OutputPublishConfig{}},
+		{name: "valid", publish: &OutputPublishConfig{
+			StateReasons: map[string]string{"completed": PublishPublish, "not_planned": PublishSkip, "duplicate": PublishDraft},
+			DraftLabels:  []string{"draft"},
+			Scheduled:    PublishDraft,
+			Expired:      PublishSkip,
+			Drafts:       DraftsSkip,
+		}},
+		{name: "unknown state reason", publish: &OutputPublishConfig{StateReasons: map[string]string{"wontfix": PublishSkip}}, wantErr: true},
+		{name: "unknown action", publish: &OutputPublishConfig{StateReasons: map[string]string{"not_planned": "hide"}}, wantErr: true},
+		{name: "unknown scheduled action", publish: &OutputPublishConfig{Scheduled: "wait"}, wantErr: true},
+		{name: "unknown drafts policy", publish: &OutputPublishConfig{Drafts: "draft"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := &Config{Output: &OutputConfig{Publish: tt.publish}}
+			if err := conf.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+			// Overrides of github.sources are validated too.
+			conf = &Config{
+				GitHub: &GitHubConfig{Sources: []GitHubSourceConfig{{Username: "octo", Repository: "blog", Output: &OutputConfig{Publish: tt.publish}}}},
+				Output: NewOutputConfig(),
+			}
+			if err := conf.validate(); (err != nil) != tt.wantErr {
+				t.Fatalf("source override: validate error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidate_Timezone(t *testing.T) {
+	if location, err := (&OutputConfig{}).Location(); location != nil || err != nil {
+		t.Fatalf("location = %v, %v, want nil", location, err)
+	}
+	if location, err := (&OutputConfig{Timezone: "Asia/Tokyo"}).Location(); err != nil || location.String() != "Asia/Tokyo" {
+		t.Fatalf("location = %v, %v", location, err)
+	}
+	if _, err := (&OutputConfig{Timezone: "JST"}).Location(); err == nil {
+		t.Fatal("Location() of an unknown zone succeeded")
+	}
+	for timezone, wantErr := range map[string]bool{"": false, "UTC": false, "Asia/Tokyo": false, "JST": true, "Mars/Olympus": true} {
+		conf := &Config{Output: &OutputConfig{Timezone: timezone}}
+		if err := conf.validate(); (err != nil) != wantErr {
+			t.Fatalf("timezone %q: validate error = %v, wantErr %v", timezone, err, wantErr)
+		}
+	}
+
+	conf := &Config{
+		GitHub: &GitHubConfig{Sources: []GitHubSourceConfig{{Username: "octo", Repository: "blog", Output: &OutputConfig{Timezone: "Mars/Olympus"}}}},
+		Output: NewOutputConfig(),
+	}
+	if err := conf.validate(); err == nil {
+		t.Fatal("validate accepted an unknown timezone of a source")
+	}
+}
@@ -3,6 +3,10 @@ package config
 import (
 	"fmt"
 	"log/slog"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
 )
 
 func (c *Config) validate() error {
@@ -12,6 +16,21 @@ func (c *Config) validate() error {
 	}{
 		// Constraints
 		{"Failed to validate deprecated options", c.WarnDeprecatedOptions},
+		{"github.api must be either \"rest\" or \"graphql\"", c.ValidateGitHubAPI},
+		{"github.baseURL and github.uploadURL must be absolute http(s) URLs, and github.uploadURL requires github.baseURL", c.ValidateGitHubURLs},
+		{"output.comments.mode must be either \"append\" or \"data\"", c.ValidateCommentsMode},
+		{"output.images.resolve must be either \"direct\" or \"html\"", c.ValidateImageResolve},
+		{"github.sources must give every entry a username and repository without duplicates, and each source needs its own output.images.directory and output.comments.directory (use [:owner] and [:repository])", c.ValidateSources},
+		{"github.filter must use a state of \"all\", \"open\" or \"closed\" and dates in YYYY-MM-DD or RFC 3339 form", c.ValidateFilter},
+		{"github.trust must use an untrusted policy of \"publish\", \"draft\" or \"skip\" and associations GitHub reports, such as OWNER, MEMBER or COLLABORATOR", c.ValidateTrust},
+		{"output paths must be valid templates: use Go template actions on the documented fields only", c.ValidatePathTemplates},
+		{"output.articles.collision must be either \"error\" or \"number\"", c.ValidateCollision},
+		{"output.articles.date must be one of \"created\", \"closed\", \"first_closed\" or \"front_matter\"", c.ValidateDateSource},
+		{"output.frontMatter.mapping must map known GitHub fields to distinct keys other than title, date and repository, and draft cannot be omitted or written as a list", c.ValidateFrontMatter},
+		{"output.frontMatter.taxonomies must have a prefix, and a key other than title, date, repository and the keys of output.frontMatter.mapping except the labels key", c.ValidateTaxonomies},
+		{"output.timezone must be an IANA time zone name, such as \"Asia/Tokyo\" or \"UTC\"", c.ValidateTimezone},
+		{"output.publish must map the state_reason values completed, not_planned, duplicate and reopened, and use scheduled and expired actions of \"publish\", \"draft\" or \"skip\", and drafts of \"write\" or \"skip\"", c.ValidatePublish},
+		{"github.retry must use a non-negative maxRetries, non-negative durations, and an onRateLimit of \"wait\" or \"fail\"", c.ValidateRetry},
 	}
 
 	// Check
@@ -39,3 +58,330 @@ func (c *Config) WarnDeprecatedOptions() bool {
 
 	return true
 }
+
+func (c *Config) ValidateGitHubAPI() bool {
+	switch c.GitHub.IssueAPI() {
+	case GitHubAPIREST, GitHubAPIGraphQL:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Config) ValidateCommentsMode() bool {
+	if c.Output == nil || !c.Output.Comments.Enabled() {
+		return true
+	}
+	switch c.Output.Comments.Mode {
+	case CommentsModeAppend, CommentsModeData:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Config) ValidateImageResolve() bool {
+	if c.Output == nil {
+		return true
+	}
+	switch c.Output.Images.ResolveStrategy() {
+	case ImageResolveDirect, ImageResolveHTML:
+		return true
+	default:
+		return false
+	}
+}
+
+// outputs returns the output configuration and, with github.sources, the
+// output each source is generated with after its overrides are merged, so
+// that constraints on output settings hold for the overrides too.
+func (c *Config) outputs() []*OutputConfig {
+	if c.Output == nil {
+		return nil
+	}
+	outputs := []*OutputConfig{c.Output}
+	if c.GitHub.HasSources() {
+		for _, source := range c.GitHub.SourceList() {
+			outputs = append(outputs, c.ForSource(source).Output)
+		}
+	}
+	return outputs
+}
+
+func (c *Config) ValidatePathTemplates() bool {
+	for _, output := range c.outputs() {
+		templates := []string{}
+		if output.Articles != nil {
+			templates = append(templates, output.Articles.Directory, output.Articles.Filename)
+		}
+		if output.Images != nil {
+			templates = append(templates, output.Images.Directory, output.Images.Filename, output.Images.URL())
+		}
+		if output.Comments.Enabled() {
+			templates = append(templates, output.Comments.DataDirectory())
+		}
+		for _, template := range templates {
+			if err := checkPathTemplate(template); err != nil {
+				slog.Error("Invalid output path template", "error", err)
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c *Config) ValidateCollision() bool {
+	for _, output := range c.outputs() {
+		switch output.Articles.CollisionPolicy() {
+		case CollisionError, CollisionNumber:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Config) ValidateDateSource() bool {
+	for _, output := range c.outputs() {
+		switch output.Articles.DateSource() {
+		case DateSourceCreated, DateSourceClosed, DateSourceFirstClosed, DateSourceFrontMatter:
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Config) ValidateTimezone() bool {
+	for _, output := range c.outputs() {
+		if _, err := output.Location(); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Config) ValidatePublish() bool {
+	for _, output := range c.outputs() {
+		if !validatePublish(output.Publish) {
+			return false
+		}
+	}
+	return true
+}
+
+func validatePublish(publish *OutputPublishConfig) bool {
+	if publish == nil {
+		return true
+	}
+	actions := []string{publish.ScheduledAction(), publish.ExpiredAction()}
+	for reason, action := range publish.StateReasons {
+		if !slices.Contains(stateReasons, strings.ToLower(reason)) {
+			return false
+		}
+		actions = append(actions, action)
+	}
+	for _, action := range actions {
+		switch action {
+		case PublishPublish, PublishDraft, PublishSkip:
+		default:
+			return false
+		}
+	}
+	switch publish.DraftsPolicy() {
+	case DraftsWrite, DraftsSkip:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Config) ValidateRetry() bool {
+	if c.GitHub == nil || c.GitHub.Retry == nil {
+		return true
+	}
+	retry := c.GitHub.Retry
+	if retry.MaxRetries != nil && *retry.MaxRetries < 0 {
+		return false
+	}
+	for _, value := range []string{retry.InitialDelay, retry.MaxDelay, retry.MaxWait} {
+		if value == "" {
+			continue
+		}
+		if d, err := time.ParseDuration(value); err != nil || d < 0 {
+			return false
+		}
+	}
+	switch retry.RateLimitPolicy() {
+	case RateLimitWait, RateLimitFail:
+		return true
+	default:
+		return false
+	}
+}
+
+func (c *Config) ValidateGitHubURLs() bool {
+	if c.GitHub == nil {
+		return true
+	}
+	if c.GitHub.UploadURL != "" && c.GitHub.BaseURL == "" {
+		return false
+	}
+	for _, raw := range []string{c.GitHub.BaseURL, c.GitHub.UploadURL} {
+		if raw == "" {
+			continue
+		}
+		parsed, err := url.Parse(raw)
+		if err != nil || (parsed.Scheme != "https" && parsed.Scheme != "http") || parsed.Host == "" {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Config) ValidateSources() bool {
+	if !c.GitHub.HasSources() {
+		return true
+	}
+	repositories := map[string]struct{}{}
+	imageDirectories := map[string]struct{}{}
+	dataDirectories := map[string]struct{}{}
+	for _, source := range c.GitHub.SourceList() {
+		if source.Username == "" || source.Repository == "" {
+			return false
+		}
+		name := strings.ToLower(source.FullName())
+		if _, ok := repositories[name]; ok {
+			return false
+		}
+		repositories[name] = struct{}{}
+
+		conf := c.ForSource(source)
+		if !conf.ValidateCommentsMode() || !conf.ValidateImageResolve() {
+			return false
+		}
+		// Images and comment data files are named after the creation time
+		// and the issue number, which repeat across repositories.
+		if _, ok := imageDirectories[conf.Output.Images.Directory]; ok {
+			return false
+		}
+		imageDirectories[conf.Output.Images.Directory] = struct{}{}
+		if conf.Output.Comments.Enabled() && conf.Output.Comments.Mode == CommentsModeData {
+			directory := conf.Output.Comments.DataDirectory()
+			if _, ok := dataDirectories[directory]; ok {
+				return false
+			}
+			dataDirectories[directory] = struct{}{}
+		}
+	}
+	return true
+}
+
+func (c *Config) ValidateFilter() bool {
+	if c.GitHub == nil {
+		return true
+	}
+	for _, source := range c.GitHub.SourceList() {
+		filter := source.Filter
+		if filter == nil {
+			continue
+		}
+		switch filter.IssueState() {
+		case IssueStateAll, IssueStateOpen, IssueStateClosed:
+		default:
+			return false
+		}
+		for _, value := range []string{filter.CreatedAfter, filter.CreatedBefore} {
+			if _, err := ParseFilterDate(value); err != nil {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (c *Config) ValidateTrust() bool {
+	if c.GitHub == nil || c.GitHub.Trust == nil {
+		return true
+	}
+	switch c.GitHub.Trust.UntrustedPolicy() {
+	case UntrustedPublish, UntrustedDraft, UntrustedSkip:
+	default:
+		return false
+	}
+	for _, association := range c.GitHub.Trust.TrustedAssociations() {
+		if !slices.Contains(authorAssociations, association) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *Config) ValidateFrontMatter() bool {
+	for _, output := range c.outputs() {
+		if !validateFrontMatter(output.FrontMatter) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateFrontMatter(frontMatter *OutputFrontMatterConfig) bool {
+	if frontMatter == nil {
+		return true
+	}
+	for field := range frontMatter.Mapping {
+		if !slices.Contains(FrontMatterFields, field) {
+			return false
+		}
+	}
+	if key, list := frontMatter.Key(FrontMatterFieldDraft); key == "" || list {
+		return false
+	}
+	seen := map[string]bool{}
+	for _, field := range FrontMatterFields {
+		key, _ := frontMatter.Key(field)
+		if key == "" {
+			continue
+		}
+		if seen[key] || slices.Contains(reservedFrontMatterKeys, key) {
+			return false
+		}
+		seen[key] = true
+	}
+	return true
+}
+
+func (c *Config) ValidateTaxonomies() bool {
+	for _, output := range c.outputs() {
+		if !validateTaxonomies(output.FrontMatter) {
+			return false
+		}
+	}
+	return true
+}
+
+func validateTaxonomies(frontMatter *OutputFrontMatterConfig) bool {
+	if frontMatter == nil {
+		return true
+	}
+	labelsKey, _ := frontMatter.Key(FrontMatterFieldLabels)
+	for _, taxonomy := range frontMatter.Taxonomies {
+		if taxonomy.Prefix == "" {
+			return false
+		}
+		key := strings.TrimSpace(taxonomy.Key)
+		if key == "" || key == labelsKey {
+			continue
+		}
+		if slices.Contains(reservedFrontMatterKeys, key) {
+			return false
+		}
+		for _, field := range FrontMatterFields {
+			if fieldKey, _ := frontMatter.Key(field); fieldKey == key {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,92 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v86/github"
+)
+
+// IssueEventStore lists the events of an issue. Issue stores implement it to
+// support github.approval and the first_closed article date.
+type IssueEventStore interface {
+	// ListIssueEvents returns the labeled, unlabeled and closed events of an
+	// issue in chronological order. Other events may be included.
+	ListIssueEvents(ctx context.Context, username, repository string, number int) ([]*github.IssueEvent, error)
+}
+
+// filterApproved drops the issues github.approval does not allow to be
+// published and logs the reason for each of them.
+func (g *ArticleGenerator) filterApproved(ctx context.Context, src issueSource, issues []*github.Issue) ([]*github.Issue, error) {
+	if !src.config.GitHub.Approval.Enabled() {
+		return issues, nil
+	}
+	approved := make([]*github.Issue, 0, len(issues))
+	for _, issue := range issues {
+		ok, reason, err := g.isApproved(ctx, src, issue)
+		if err != nil {
+			return nil, fmt.Errorf("issue #%d: %w", issue.GetNumber(), err)
+		}
+		if !ok {
+			g.logger.Info("Skipping unapproved issue", "issue", issue.GetNumber(), "reason", reason)
+			continue
+		}
+		approved = append(approved, issue)
+	}
+	return approved, nil
+}
+
+// isApproved reports whether the approval label of the issue was applied by
+// a user with write access and, when not, why. Without github.approval every
+// issue is approved.
+func (g *ArticleGenerator) isApproved(ctx context.Context, src issueSource, issue *github.Issue) (bool, string, error) {
+	approval := src.config.GitHub.Approval
+	if !approval.Enabled() {
+		return true, "", nil
+	}
+	// Only call the API for issues that carry the label.
+	if !issueHasLabel(issue, approval.Label) {
+		return false, fmt.Sprintf("label %s is not applied", approval.Label), nil
+	}
+
+	store, ok := g.issueRepo.(IssueEventStore)
+	if !ok {
+		return false, "", fmt.Errorf("the configured issue store cannot list issue events")
+	}
+	events, err := store.ListIssueEvents(ctx, src.username, src.repository, issue.GetNumber())
+	if err != nil {
+		return false, "", fmt.Errorf("list events: %w", err)
+	}
+	actor := lastLabelActor(events, approval.Label)
+	if actor == "" {
+		return false, fmt.Sprintf("no event records who applied label %s", approval.Label), nil
+	}
+
+	permission, err := g.permission(ctx, src, actor)
+	if err != nil {
+		return false, "", fmt.Errorf("check permission of %s: %w", actor, err)
+	}
+	if !hasWriteAccess(permission) {
+		return false, fmt.Sprintf("label %s was applied by %s, who has %s permission", approval.Label, actor, permission), nil
+	}
+	return true, "", nil
+}
+
+// lastLabelActor returns the login of the user who applied the label most
+// recently, or an empty string when no event records it.
+func lastLabelActor(events []*github.IssueEvent, label string) string {
+	actor := ""
+	for _, event := range events {
+		if !strings.EqualFold(event.GetLabel().GetName(), label) {
+			continue
+		}
+		switch event.GetEvent() {
+		case "labeled":
+			actor = event.GetActor().GetLogin()
+		case "unlabeled":
+			actor = ""
+		}
+	}
+	return actor
+}
@@ -0,0 +1,163 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newApprovalStandIn serves the issues, issue events and collaborator
+// permissions of testuser/testrepo. It records the issues whose events were
+// requested.
+func newApprovalStandIn(t *testing.T, eventRequests *[]string) *httptest.Server {
+	t.Helper()
+	issue := func(number int, labels string) string {
+		return fmt.Sprintf(`{"number": %d, "title": "Issue %d", "body": "Body", "state": "closed", "user": {"login": "guest"}, "created_at": "2024-01-0%dT00:00:00Z", "labels": [%s]}`, number, number, number, labels)
+	}
+	issues := []string{
+		issue(1, `{"name": "approved"}`),
+		issue(2, `{"name": "Approved"}`),
+		issue(3, ``),
+		issue(4, `{"name": "approved"}`),
+	}
+	events := map[string]string{
+		// Approved by a maintainer.
+		"1": `[{"event": "labeled", "actor": {"login": "maintainer"}, "label": {"name": "approved"}}]`,
+		// Applied by an issue template on behalf of the author.
+		"2": `[{"event": "labeled", "actor": {"login": "guest"}, "label": {"name": "approved"}}]`,
+		// Approved by a maintainer, then removed and re-applied by a triager.
+		"4": `[
+			{"event": "labeled", "actor": {"login": "maintainer"}, "label": {"name": "approved"}},
+			{"event": "unlabeled", "actor": {"login": "triager"}, "label": {"name": "approved"}},
+			{"event": "labeled", "actor": {"login": "triager"}, "label": {"name": "approved"}}
+		]`,
+	}
+	permissions := map[string]string{"maintainer": "write", "triager": "read", "guest": "read"}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		path := strings.TrimPrefix(r.URL.Path, "/api/v3/repos/testuser/testrepo/")
+		switch {
+		case path == "issues":
+			_, _ = w.Write([]byte("[" + strings.Join(issues, ",") + "]"))
+		case strings.HasPrefix(path, "issues/") && strings.HasSuffix(path, "/events"):
+			number := strings.TrimSuffix(strings.TrimPrefix(path, "issues/"), "/events")
+			*eventRequests = append(*eventRequests, number)
+			_, _ = w.Write([]byte(events[number]))
+		case strings.HasPrefix(path, "issues/"):
+			number, err := strconv.Atoi(strings.TrimPrefix(path, "issues/"))
+			if err != nil || number < 1 || number > len(issues) {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_, _ = w.Write([]byte(issues[number-1]))
+		case strings.HasPrefix(path, "collaborators/"):
+			user := strings.TrimSuffix(strings.TrimPrefix(path, "collaborators/"), "/permission")
+			_, _ = w.Write([]byte(`{"permission": "` + permissions[user] + `"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func newApprovalTestGenerator(t *testing.T, server *httptest.Server, saved map[int]*Article) *ArticleGenerator {
+	t.Helper()
+	client, err := github.NewClient(nil).WithEnterpriseURLs(server.URL, server.URL)
+	require.NoError(t, err)
+
+	conf := *config.NewConfig()
+	conf.GitHub.Approval = &config.GitHubApprovalConfig{Label: "approved"}
+	return &ArticleGenerator{
+		issueRepo: &GitHubIssueRepository{client: client, logger: slog.Default()},
+		articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+			saved[article.Number] = article
+			return &ArticleOutput{ArticlePath: article.Key + ".md"}, nil
+		}},
+		service: NewArticleService(conf),
+		config:  conf,
+		logger:  slog.Default(),
+	}
+}
+
+func TestArticleGenerator_Generate_RequiresApproval(t *testing.T) {
+	var eventRequests []string
+	server := newApprovalStandIn(t, &eventRequests)
+	defer server.Close()
+	saved := map[int]*Article{}
+	gen := newApprovalTestGenerator(t, server, saved)
+
+	count, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	assertEqualCmp(t, 1, count)
+	assert.Contains(t, saved, 1)
+	// Issues without the label are skipped without listing their events.
+	assertEqualCmp(t, []string{"1", "2", "4"}, eventRequests)
+}
+
+func TestArticleGenerator_GenerateIssue_RequiresApproval(t *testing.T) {
+	var eventRequests []string
+	server := newApprovalStandIn(t, &eventRequests)
+	defer server.Close()
+	saved := map[int]*Article{}
+	gen := newApprovalTestGenerator(t, server, saved)
+	gen.SetManifest(NewManifest())
+
+	outcome, err := gen.GenerateIssue(context.Background(), "testuser", "testrepo", 1)
+	require.NoError(t, err)
+	assertEqualCmp(t, IssueGenerated, outcome)
+
+	outcome, err = gen.GenerateIssue(context.Background(), "testuser", "testrepo", 2)
+	require.NoError(t, err)
+	assertEqualCmp(t, IssueSkipped, outcome)
+	assert.NotContains(t, saved, 2)
+}
+
+func TestArticleGenerator_Generate_ApprovalRequiresEventStore(t *testing.T) {
+	conf := *config.NewConfig()
+	conf.GitHub.Approval = &config.GitHubApprovalConfig{Label: "approved"}
+	gen := &ArticleGenerator{
+		issueRepo: &stubIssueStore{issues: []*github.Issue{
+			{Number: Ptr(1), State: Ptr("closed"), Labels: []*github.Label{{Name: Ptr("approved")}}},
+		}},
+		articleRepo: stubArticleStore{},
+		service:     NewArticleService(conf),
+		config:      conf,
+		logger:      slog.Default(),
+	}
+
+	_, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	assert.ErrorContains(t, err, "cannot list issue events")
+}
+
+func TestLastLabelActor(t *testing.T) {
+	event := func(name, actor, label string) *github.IssueEvent {
+		return &github.IssueEvent{Event: Ptr(name), Actor: &github.User{Login: Ptr(actor)}, Label: &github.Label{Name: Ptr(label)}}
+	}
+	tests := []struct {
+		name   string
+		events []*github.IssueEvent
+		want   string
+	}{
+		{"no events", nil, ""},
+		{"labeled", []*github.IssueEvent{event("labeled", "maintainer", "Approved")}, "maintainer"},
+		{"other labels are ignored", []*github.IssueEvent{event("labeled", "maintainer", "approved"), event("labeled", "guest", "bug")}, "maintainer"},
+		{"relabeled", []*github.IssueEvent{event("labeled", "maintainer", "approved"), event("labeled", "triager", "approved")}, "triager"},
+		{"unlabeled", []*github.IssueEvent{event("labeled", "maintainer", "approved"), event("unlabeled", "maintainer", "approved")}, ""},
+		{"other events are ignored", []*github.IssueEvent{event("labeled", "maintainer", "approved"), {Event: Ptr("closed"), Actor: &github.User{Login: Ptr("guest")}}}, "maintainer"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertEqualCmp(t, tt.want, lastLabelActor(tt.events, "approved"))
+		})
+	}
+}
@@ -2,7 +2,11 @@ package core
 
 import (
 	"fmt"
+	"slices"
+	"strings"
+	"time"
 
+	"github.com/rokuosan/github-issue-cms/pkg/config"
 	"gopkg.in/yaml.v3"
 )
 
@@ -34,7 +38,7 @@ func (HugoArticleRenderer) Render(article *Article) (string, error) {
 		}
 	}
 
-	partial, err := yaml.Marshal(rendered)
+	partial, err := marshalFrontMatterEntries(frontMatterEntries(rendered, extra))
 	if err != nil {
 		return "", err
 	}
@@ -43,6 +47,110 @@ func (HugoArticleRenderer) Render(article *Article) (string, error) {
 	return fmt.Sprintf("---\n%s---\n\n%s\n", frontMatter, rendered.Content), nil
 }
 
+// frontMatterEntry is one key of the front matter written from the issue.
+type frontMatterEntry struct {
+	key   string
+	value any
+}
+
+// mappedFields are the fields only written when output.frontMatter.mapping
+// names a key for them, in the order they are written.
+var mappedFields = []string{
+	config.FrontMatterFieldNumber, config.FrontMatterFieldURL, config.FrontMatterFieldAssignees,
+	config.FrontMatterFieldStateReason, config.FrontMatterFieldCreated, config.FrontMatterFieldUpdated,
+	config.FrontMatterFieldClosed, config.FrontMatterFieldReactions,
+}
+
+// frontMatterEntries returns the front matter written from the issue, in
+// order. Keys that are also set in extra, the front matter of the issue
+// body, are left to extra so that no key is written twice.
+func frontMatterEntries(article *Article, extra map[string]any) []frontMatterEntry {
+	var entries []frontMatterEntry
+	add := func(field string, value any) {
+		key, list := article.Mapping.Key(field)
+		if key == "" {
+			return
+		}
+		if _, ok := extra[key]; ok {
+			return
+		}
+		if list {
+			value = frontMatterList(value)
+		}
+		entries = append(entries, frontMatterEntry{key: key, value: value})
+	}
+
+	add(config.FrontMatterFieldAuthor, article.Author)
+	for _, entry := range []frontMatterEntry{{key: "title", value: article.Title}, {key: "date", value: article.Date}} {
+		if _, ok := extra[entry.key]; !ok {
+			entries = append(entries, entry)
+		}
+	}
+	add(config.FrontMatterFieldMilestone, article.Category)
+	add(config.FrontMatterFieldLabels, article.Tags)
+	for _, key := range taxonomyKeys(article) {
+		if _, ok := extra[key]; !ok {
+			entries = append(entries, frontMatterEntry{key: key, value: article.Taxonomies[key]})
+		}
+	}
+	add(config.FrontMatterFieldDraft, article.Draft)
+	if article.Source != "" {
+		entries = append(entries, frontMatterEntry{key: "repository", value: article.Source})
+	}
+	for _, field := range mappedFields {
+		if value, ok := article.Fields[field]; ok && value != nil {
+			add(field, value)
+		}
+	}
+	return entries
+}
+
+// taxonomyKeys returns the keys of the non-empty taxonomies of the article in
+// the order of output.frontMatter.taxonomies.
+func taxonomyKeys(article *Article) []string {
+	var keys []string
+	if article.Mapping == nil {
+		return keys
+	}
+	for _, taxonomy := range article.Mapping.Taxonomies {
+		key := strings.TrimSpace(taxonomy.Key)
+		if len(article.Taxonomies[key]) > 0 && !slices.Contains(keys, key) {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// frontMatterList wraps a single value into a list. An empty string becomes
+// an empty list.
+func frontMatterList(value any) any {
+	switch typed := value.(type) {
+	case []string, []any:
+		return typed
+	case string:
+		if typed == "" {
+			return []string{}
+		}
+		return []string{typed}
+	default:
+		return []any{typed}
+	}
+}
+
+// marshalFrontMatterEntries marshals the entries as a YAML mapping, keeping
+// their order.
+func marshalFrontMatterEntries(entries []frontMatterEntry) ([]byte, error) {
+	mapping := &yaml.Node{Kind: yaml.MappingNode}
+	for _, entry := range entries {
+		value := &yaml.Node{}
+		if err := value.Encode(entry.value); err != nil {
+			return nil, err
+		}
+		mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: entry.key}, value)
+	}
+	return yaml.Marshal(mapping)
+}
+
 // ApplyFrontMatterOverrides applies frontmatter metadata overrides to an article.
 // This is used when saving articles (to merge issue-body frontmatter with GitHub metadata)
 // and when generating OGP images (so the image reflects the final rendered values).
@@ -50,30 +158,46 @@ func ApplyFrontMatterOverrides(article *Article, extra map[string]any) {
 	applyFrontMatterOverrides(article, extra)
 }
 
+// applyFrontMatterOverrides moves the values of the issue body front matter
+// that override article fields from extra onto the article. Fields are looked
+// up under the keys of output.frontMatter.mapping.
 func applyFrontMatterOverrides(article *Article, extra map[string]any) {
-	if author, ok := stringValue(extra["author"]); ok {
-		article.Author = author
-		delete(extra, "author")
+	if key, list := article.Mapping.Key(config.FrontMatterFieldAuthor); key != "" {
+		if author, ok := authorValue(extra[key], list); ok {
+			article.Author = author
+			delete(extra, key)
+		}
 	}
 	if title, ok := stringValue(extra["title"]); ok {
 		article.Title = title
 		delete(extra, "title")
 	}
-	if date, ok := stringValue(extra["date"]); ok {
+	if date, ok := dateValue(extra["date"]); ok {
 		article.Date = date
 		delete(extra, "date")
 	}
-	if category, ok := categoryValue(extra["categories"]); ok {
-		article.Category = category
-		delete(extra, "categories")
+	if key, _ := article.Mapping.Key(config.FrontMatterFieldMilestone); key != "" {
+		if category, ok := categoryValue(extra[key]); ok {
+			article.Category = category
+			delete(extra, key)
+		}
+	}
+	if key, _ := article.Mapping.Key(config.FrontMatterFieldLabels); key != "" {
+		if tags, ok := stringSliceValue(extra[key]); ok {
+			article.Tags = tags
+			delete(extra, key)
+		}
 	}
-	if tags, ok := stringSliceValue(extra["tags"]); ok {
-		article.Tags = tags
-		delete(extra, "tags")
+	if key, _ := article.Mapping.Key(config.FrontMatterFieldDraft); key != "" {
+		if draft, ok := boolValue(extra[key]); ok {
+			article.Draft = draft
+			delete(extra, key)
+		}
 	}
-	if draft, ok := boolValue(extra["draft"]); ok {
-		article.Draft = draft
-		delete(extra, "draft")
+	if article.Source != "" {
+		// The source repository is recorded by the generator and cannot be
+		// overridden from the issue body.
+		delete(extra, "repository")
 	}
 }
 
@@ -82,11 +206,40 @@ func stringValue(value any) (string, bool) {
 	return s, ok
 }
 
+// dateValue accepts a date written as a string, or as a YAML timestamp that
+// the front-matter parser decoded into a time.
+func dateValue(value any) (string, bool) {
+	switch typed := value.(type) {
+	case string:
+		return typed, true
+	case time.Time:
+		return typed.Format(time.RFC3339), true
+	default:
+		return "", false
+	}
+}
+
 func boolValue(value any) (bool, bool) {
 	b, ok := value.(bool)
 	return b, ok
 }
 
+// authorValue accepts a single author, written as a list of one when the
+// author key is a list. Lists of several authors stay in the front matter.
+func authorValue(value any, list bool) (string, bool) {
+	if s, ok := value.(string); ok {
+		return s, true
+	}
+	if !list {
+		return "", false
+	}
+	values, ok := stringSliceValue(value)
+	if !ok || len(values) != 1 {
+		return "", false
+	}
+	return values[0], true
+}
+
 func categoryValue(value any) (string, bool) {
 	if s, ok := value.(string); ok {
 		return s, true
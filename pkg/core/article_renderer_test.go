@@ -1,9 +1,15 @@
 package core
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestHugoArticleRenderer_Render(t *testing.T) {
@@ -89,6 +95,30 @@ tags:
 draft: true
 ---
 
+Test content
+`,
+		},
+		{
+			name: "source repository",
+			article: &Article{
+				Author:      "John Doe",
+				Title:       "Test",
+				Content:     "Test content",
+				Date:        "2021-01-01",
+				Draft:       false,
+				Source:      "octo/design",
+				FrontMatter: NewFrontMatter(map[string]any{"repository": "spoofed/repo"}),
+			},
+			want: `---
+author: John Doe
+title: Test
+date: "2021-01-01"
+categories: ""
+tags: []
+draft: false
+repository: octo/design
+---
+
 Test content
 `,
 		},
@@ -153,3 +183,137 @@ func TestHugoArticleRenderer_DoesNotMutateReceiver(t *testing.T) {
 		return cmp.Equal(x.Values(), y.Values())
 	}))
 }
+
+func TestHugoArticleRenderer_RenderMappedFrontMatter(t *testing.T) {
+	renderer := NewHugoArticleRenderer()
+	mapping := &config.OutputFrontMatterConfig{Mapping: map[string]string{
+		config.FrontMatterFieldAuthor:    "authors[]",
+		config.FrontMatterFieldMilestone: "series",
+		config.FrontMatterFieldLabels:    "keywords",
+		config.FrontMatterFieldNumber:    "issue",
+		config.FrontMatterFieldClosed:    "closed",
+		config.FrontMatterFieldReactions: "reactions",
+	}}
+	article := &Article{
+		Author:   "testuser",
+		Title:    "Test Title",
+		Content:  "Test content",
+		Date:     "2021-01-01T00:00:00Z",
+		Category: "Go",
+		Tags:     []string{"go", "hugo"},
+		Fields: map[string]any{
+			config.FrontMatterFieldNumber:    3,
+			config.FrontMatterFieldURL:       "https://github.com/testuser/testrepo/issues/3",
+			config.FrontMatterFieldReactions: map[string]any{"total_count": 1, "+1": 1},
+		},
+		Mapping:     mapping,
+		FrontMatter: EmptyFrontMatter(),
+	}
+
+	got, err := renderer.Render(article)
+	require.NoError(t, err)
+	assertEqualCmp(t, `---
+authors:
+    - testuser
+title: Test Title
+date: "2021-01-01T00:00:00Z"
+series: Go
+keywords:
+    - go
+    - hugo
+draft: false
+issue: 3
+reactions:
+    "+1": 1
+    total_count: 1
+---
+
+Test content
+`, got)
+
+	t.Run("issue body overrides mapped keys", func(t *testing.T) {
+		overridden := article.Clone()
+		overridden.FrontMatter = NewFrontMatter(map[string]any{
+			"authors":  []any{"alice", "bob"},
+			"series":   "Rust",
+			"keywords": []any{"rust"},
+			"issue":    "custom",
+			"draft":    true,
+		})
+
+		got, err := renderer.Render(overridden)
+		require.NoError(t, err)
+		assertEqualCmp(t, `---
+title: Test Title
+date: "2021-01-01T00:00:00Z"
+series: Rust
+keywords:
+    - rust
+draft: true
+reactions:
+    "+1": 1
+    total_count: 1
+authors:
+    - alice
+    - bob
+issue: custom
+---
+
+Test content
+`, got)
+	})
+
+	t.Run("unquoted body date overrides the date", func(t *testing.T) {
+		overridden := article.Clone()
+		overridden.FrontMatter = NewFrontMatter(map[string]any{
+			"date": time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("", 9*60*60)),
+		})
+
+		got, err := renderer.Render(overridden)
+		require.NoError(t, err)
+		assert.Equal(t, 1, strings.Count(got, "date:"))
+		assert.Contains(t, got, "date: \"2024-05-06T07:08:09+09:00\"\n")
+	})
+
+	t.Run("single author list overrides the author", func(t *testing.T) {
+		overridden := article.Clone()
+		overridden.FrontMatter = NewFrontMatter(map[string]any{"authors": []any{"alice"}})
+		ApplyFrontMatterOverrides(overridden, overridden.FrontMatter.Values())
+		assertEqualCmp(t, "alice", overridden.Author)
+	})
+}
+
+func TestArticleService_ConvertIssueToArticle_MappedFields(t *testing.T) {
+	conf := *config.NewConfig()
+	conf.Output.FrontMatter = &config.OutputFrontMatterConfig{Mapping: map[string]string{
+		config.FrontMatterFieldAssignees:   "contributors",
+		config.FrontMatterFieldStateReason: "state_reason",
+		config.FrontMatterFieldUpdated:     "lastmod",
+		config.FrontMatterFieldURL:         "",
+	}}
+	issue := &github.Issue{
+		Number: Ptr(3), Title: Ptr("Hello"), Body: Ptr("World"), State: Ptr("closed"), StateReason: Ptr("completed"),
+		HTMLURL: Ptr("https://github.com/testuser/testrepo/issues/3"), User: &github.User{Login: Ptr("testuser")},
+		Assignees: []*github.User{{Login: Ptr("alice")}},
+		CreatedAt: parseTime("2024-01-01T00:00:00Z"), UpdatedAt: parseTime("2024-01-02T12:00:00Z"),
+	}
+
+	got, err := NewHugoArticleRenderer().Render(NewArticleService(conf).ConvertIssueToArticle(issue))
+	require.NoError(t, err)
+	assertEqualCmp(t, `---
+author: testuser
+title: Hello
+date: "2024-01-01T00:00:00Z"
+categories: ""
+tags: []
+draft: false
+contributors:
+    - alice
+state_reason: completed
+lastmod: "2024-01-02T12:00:00Z"
+---
+
+World
+
+`, got)
+}
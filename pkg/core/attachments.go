@@ -0,0 +1,95 @@
+package core
+
+import (
+	"html"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+)
+
+// userAttachmentsAssetsPath is the path prefix of images attached by
+// drag-and-drop, e.g. https://github.com/user-attachments/assets/<id>.
+const userAttachmentsAssetsPath = "/user-attachments/assets/"
+
+// privateUserImagesHost serves the signed URLs of private attachments on
+// github.com.
+const privateUserImagesHost = "private-user-images.githubusercontent.com"
+
+var regexHTMLURLAttribute = regexp.MustCompile(`(?:src|href)="([^"]+)"`)
+
+// userAttachmentAssetID returns the asset ID of a user-attachments image URL,
+// or an empty string for any other URL.
+func userAttachmentAssetID(rawURL string) string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || !strings.HasPrefix(parsed.Path, userAttachmentsAssetsPath) {
+		return ""
+	}
+	id := strings.TrimPrefix(parsed.Path, userAttachmentsAssetsPath)
+	if id == "" || strings.Contains(id, "/") {
+		return ""
+	}
+	return id
+}
+
+// hasUserAttachments reports whether any image is a user-attachments image.
+func hasUserAttachments(images []*Image) bool {
+	for _, image := range images {
+		if userAttachmentAssetID(image.URL) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// signedAttachmentHosts returns the hosts GitHub serves signed attachment URLs
+// from: private-user-images.githubusercontent.com, and the host of a
+// configured GitHub Enterprise Server and its media subdomain.
+func signedAttachmentHosts(conf config.Config) []string {
+	host := conf.GitHub.EnterpriseHost()
+	if host == "" {
+		return []string{privateUserImagesHost}
+	}
+	return []string{privateUserImagesHost, host, "media." + host}
+}
+
+// resolveSignedAttachmentURLs points the DownloadURL of every user-attachments
+// image at its signed counterpart in renderedHTML. GitHub renders private
+// attachments as short-lived https://private-user-images.githubusercontent.com
+// URLs whose file name contains the asset ID and that any token can fetch.
+// Only URLs on one of hosts are considered, because anyone who can comment
+// can link to other hosts. It returns the number of images
+// that were resolved.
+func resolveSignedAttachmentURLs(images []*Image, renderedHTML string, hosts []string) int {
+	signed := map[string]string{}
+	for _, match := range regexHTMLURLAttribute.FindAllStringSubmatch(renderedHTML, -1) {
+		candidate := html.UnescapeString(match[1])
+		parsed, err := url.Parse(candidate)
+		if err != nil || !strings.EqualFold(parsed.Scheme, "https") || userAttachmentAssetID(candidate) != "" {
+			continue
+		}
+		if !slices.ContainsFunc(hosts, func(host string) bool { return strings.EqualFold(parsed.Host, host) }) {
+			continue
+		}
+		signed[path.Base(parsed.Path)] = candidate
+	}
+
+	resolved := 0
+	for _, image := range images {
+		id := userAttachmentAssetID(image.URL)
+		if id == "" {
+			continue
+		}
+		for name, candidate := range signed {
+			if strings.Contains(name, id) {
+				image.DownloadURL = candidate
+				resolved++
+				break
+			}
+		}
+	}
+	return resolved
+}
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"testing"
+
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserAttachmentAssetID(t *testing.T) {
+	assert.Equal(t, "0f1e2d3c-aaaa-bbbb-cccc-1234567890ab", userAttachmentAssetID("https://github.com/user-attachments/assets/0f1e2d3c-aaaa-bbbb-cccc-1234567890ab"))
+	assert.Equal(t, "abc", userAttachmentAssetID("https://ghe.example.com/user-attachments/assets/abc"))
+	assert.Empty(t, userAttachmentAssetID("https://github.com/user-attachments/files/123/report.pdf"))
+	assert.Empty(t, userAttachmentAssetID("https://user-images.githubusercontent.com/1/abc.png"))
+}
+
+func TestResolveSignedAttachmentURLs(t *testing.T) {
+	images := []*Image{
+		NewImage("https://github.com/user-attachments/assets/0f1e2d3c-aaaa-bbbb-cccc-1234567890ab", "2024-01-01_000000", 0),
+		NewImage("https://github.com/user-attachments/assets/9a8b7c6d-dddd-eeee-ffff-0987654321ba", "2024-01-01_000000", 1),
+		NewImage("https://user-images.githubusercontent.com/1/legacy.png", "2024-01-01_000000", 2),
+	}
+	renderedHTML := `<p>Hello</p>
+<p><a target="_blank" rel="noopener noreferrer" href="https://private-user-images.githubusercontent.com/1234/300000001-0f1e2d3c-aaaa-bbbb-cccc-1234567890ab.png?jwt=header.payload.signature&amp;v=1"><img src="https://private-user-images.githubusercontent.com/1234/300000001-0f1e2d3c-aaaa-bbbb-cccc-1234567890ab.png?jwt=header.payload.signature&amp;v=1" alt="image" style="max-width: 100%;"></a></p>
+<p><a href="https://github.com/user-attachments/assets/9a8b7c6d-dddd-eeee-ffff-0987654321ba">link</a></p>
+<p><a href="https://attacker.example/9a8b7c6d-dddd-eeee-ffff-0987654321ba.png">look-alike</a></p>`
+
+	resolved := resolveSignedAttachmentURLs(images, renderedHTML, signedAttachmentHosts(*config.NewConfig()))
+
+	assert.Equal(t, 1, resolved)
+	assert.Equal(t, "https://private-user-images.githubusercontent.com/1234/300000001-0f1e2d3c-aaaa-bbbb-cccc-1234567890ab.png?jwt=header.payload.signature&v=1", images[0].DownloadURL)
+	// Attachments without a signed counterpart are downloaded as before,
+	// even when a link on another host carries their asset ID.
+	assert.Empty(t, images[1].DownloadURL)
+	assert.Empty(t, images[2].DownloadURL)
+}
+
+func TestSignedAttachmentHosts(t *testing.T) {
+	conf := *config.NewConfig()
+	assert.Equal(t, []string{"private-user-images.githubusercontent.com"}, signedAttachmentHosts(conf))
+
+	conf.GitHub.BaseURL = "https://ghe.example.com/api/v3/"
+	assert.Equal(t, []string{"private-user-images.githubusercontent.com", "ghe.example.com", "media.ghe.example.com"}, signedAttachmentHosts(conf))
+}
+
+func TestHasUserAttachments(t *testing.T) {
+	assert.False(t, hasUserAttachments(nil))
+	assert.False(t, hasUserAttachments([]*Image{NewImage("https://user-images.githubusercontent.com/1/a.png", "", 0)}))
+	assert.True(t, hasUserAttachments([]*Image{NewImage("https://github.com/user-attachments/assets/abc", "", 0)}))
+}
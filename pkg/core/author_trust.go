@@ -0,0 +1,145 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+)
+
+const (
+	// PermissionAdmin is the permission of repository administrators.
+	PermissionAdmin = "admin"
+	// PermissionWrite is the permission of users who can push, including
+	// maintainers.
+	PermissionWrite = "write"
+	// PermissionRead is the permission of users who can only read, including
+	// triagers.
+	PermissionRead = "read"
+	// PermissionNone means the user has no access to the repository.
+	PermissionNone = "none"
+)
+
+// PermissionStore returns the permission a user has on a repository. Issue
+// stores implement it to support github.trust.checkPermission.
+type PermissionStore interface {
+	// GetPermission returns PermissionAdmin, PermissionWrite, PermissionRead
+	// or PermissionNone.
+	GetPermission(ctx context.Context, username, repository, user string) (string, error)
+}
+
+// hasWriteAccess reports whether the permission allows pushing to the
+// repository.
+func hasWriteAccess(permission string) bool {
+	return permission == PermissionAdmin || permission == PermissionWrite
+}
+
+// untrustedAction returns what github.trust does with the issue: an empty
+// action when the issue is published as usual, or config.UntrustedDraft or
+// config.UntrustedSkip together with the reason its author is not trusted.
+func (g *ArticleGenerator) untrustedAction(ctx context.Context, src issueSource, issue *github.Issue) (string, string, error) {
+	trust := src.config.GitHub.Trust
+	policy := trust.UntrustedPolicy()
+	if policy == config.UntrustedPublish {
+		return "", "", nil
+	}
+	trusted, reason, err := g.isTrustedAuthor(ctx, src, trust, issue)
+	if err != nil || trusted {
+		return "", "", err
+	}
+	return policy, reason, nil
+}
+
+// isTrustedAuthor reports whether the author of the issue is trusted and,
+// when not, why.
+func (g *ArticleGenerator) isTrustedAuthor(ctx context.Context, src issueSource, trust *config.GitHubTrustConfig, issue *github.Issue) (bool, string, error) {
+	if trust.ApprovalLabel != "" && issueHasLabel(issue, trust.ApprovalLabel) {
+		return true, "", nil
+	}
+	return g.isTrustedUser(ctx, src, trust, issue.GetUser().GetLogin(), issue.GetAuthorAssociation())
+}
+
+// isTrustedUser reports whether github.trust trusts the user with the given
+// author association and, when not, why.
+func (g *ArticleGenerator) isTrustedUser(ctx context.Context, src issueSource, trust *config.GitHubTrustConfig, user, association string) (bool, string, error) {
+	if user != "" && containsFold(trust.Users, user) {
+		return true, "", nil
+	}
+	association = strings.ToUpper(association)
+	if slices.Contains(trust.TrustedAssociations(), association) {
+		return true, "", nil
+	}
+	reason := fmt.Sprintf("author association %s is not trusted", association)
+	if !trust.CheckPermission || user == "" {
+		return false, reason, nil
+	}
+
+	permission, err := g.permission(ctx, src, user)
+	if err != nil {
+		return false, "", fmt.Errorf("check permission of %s: %w", user, err)
+	}
+	if hasWriteAccess(permission) {
+		return true, "", nil
+	}
+	return false, fmt.Sprintf("%s and the author has %s permission", reason, permission), nil
+}
+
+// trustedComments drops the comments whose authors github.trust does not
+// trust, so that neither their text nor their images are published. The
+// approval label only approves the issue itself, not comments added to it.
+func (g *ArticleGenerator) trustedComments(ctx context.Context, src issueSource, issue *github.Issue, comments []*github.IssueComment) ([]*github.IssueComment, error) {
+	trust := src.config.GitHub.Trust
+	if trust.UntrustedPolicy() == config.UntrustedPublish {
+		return comments, nil
+	}
+	trusted := make([]*github.IssueComment, 0, len(comments))
+	for _, comment := range comments {
+		ok, reason, err := g.isTrustedUser(ctx, src, trust, comment.GetUser().GetLogin(), comment.GetAuthorAssociation())
+		if err != nil {
+			return nil, fmt.Errorf("comment %d: %w", comment.GetID(), err)
+		}
+		if !ok {
+			g.logger.Info("Dropping comment of untrusted author", "issue", issue.GetNumber(), "comment", comment.GetID(), "author", comment.GetUser().GetLogin(), "reason", reason)
+			continue
+		}
+		trusted = append(trusted, comment)
+	}
+	return trusted, nil
+}
+
+// permission returns the permission of user on the source repository. Results
+// are cached for the lifetime of the generator.
+func (g *ArticleGenerator) permission(ctx context.Context, src issueSource, user string) (string, error) {
+	store, ok := g.issueRepo.(PermissionStore)
+	if !ok {
+		return "", fmt.Errorf("the configured issue store cannot check permissions")
+	}
+	key := strings.ToLower(src.username + "/" + src.repository + "@" + user)
+	if permission, ok := g.permissions[key]; ok {
+		return permission, nil
+	}
+	permission, err := store.GetPermission(ctx, src.username, src.repository, user)
+	if err != nil {
+		return "", err
+	}
+	if g.permissions == nil {
+		g.permissions = map[string]string{}
+	}
+	g.permissions[key] = permission
+	return permission, nil
+}
+
+// forceDraft marks the article as a draft, overriding a draft value in the
+// front matter of the issue body.
+func forceDraft(article *Article) {
+	article.Draft = true
+	values := article.FrontMatter.Values()
+	key, _ := article.Mapping.Key(config.FrontMatterFieldDraft)
+	if _, ok := values[key]; ok {
+		values[key] = true
+		article.FrontMatter = NewFrontMatter(values)
+	}
+}
@@ -0,0 +1,216 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func trustTestIssues() []*github.Issue {
+	issue := func(number int, author, association string, labels ...string) *github.Issue {
+		issue := &github.Issue{
+			Number:            Ptr(number),
+			Title:             Ptr("Issue"),
+			Body:              Ptr("---\ndraft: false\n---\nBody"),
+			State:             Ptr("closed"),
+			User:              &github.User{Login: Ptr(author)},
+			AuthorAssociation: Ptr(association),
+			CreatedAt:         parseTime("2024-01-01T00:00:00Z"),
+		}
+		for _, label := range labels {
+			issue.Labels = append(issue.Labels, &github.Label{Name: Ptr(label)})
+		}
+		return issue
+	}
+	return []*github.Issue{
+		issue(1, "owner", "OWNER"),
+		issue(2, "stranger", "CONTRIBUTOR"),
+		issue(3, "friend", "NONE"),
+		issue(4, "pusher", "NONE"),
+		issue(5, "reader", "NONE", "Approved"),
+	}
+}
+
+func TestArticleGenerator_Generate_AppliesTrustPolicy(t *testing.T) {
+	newGenerator := func(untrusted string) (*ArticleGenerator, *stubPermissionIssueStore, map[int]*Article) {
+		conf := *config.NewConfig()
+		conf.GitHub.Trust = &config.GitHubTrustConfig{
+			Untrusted:       untrusted,
+			Users:           []string{"Friend"},
+			CheckPermission: true,
+			ApprovalLabel:   "approved",
+		}
+		issueRepo := &stubPermissionIssueStore{
+			stubIssueStore: stubIssueStore{issues: trustTestIssues()},
+			permissions:    map[string]string{"pusher": PermissionWrite, "stranger": PermissionRead},
+		}
+		saved := map[int]*Article{}
+		gen := &ArticleGenerator{
+			issueRepo: issueRepo,
+			articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+				saved[article.Number] = article
+				return &ArticleOutput{ArticlePath: article.Key + ".md"}, nil
+			}},
+			service: NewArticleService(conf),
+			config:  conf,
+			logger:  slog.Default(),
+		}
+		return gen, issueRepo, saved
+	}
+
+	t.Run("publish disables the checks", func(t *testing.T) {
+		gen, issueRepo, saved := newGenerator(config.UntrustedPublish)
+		count, err := gen.Generate(context.Background(), "testuser", "testrepo")
+		require.NoError(t, err)
+		assertEqualCmp(t, 5, count)
+		assert.False(t, saved[2].Draft)
+		assertEqualCmp(t, []string(nil), issueRepo.lookups)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		gen, issueRepo, saved := newGenerator(config.UntrustedSkip)
+		count, err := gen.Generate(context.Background(), "testuser", "testrepo")
+		require.NoError(t, err)
+		assertEqualCmp(t, 4, count)
+		assert.NotContains(t, saved, 2)
+		assertEqualCmp(t, []string{"stranger", "pusher"}, issueRepo.lookups)
+	})
+
+	t.Run("draft overrides the front matter", func(t *testing.T) {
+		gen, _, saved := newGenerator(config.UntrustedDraft)
+		count, err := gen.Generate(context.Background(), "testuser", "testrepo")
+		require.NoError(t, err)
+		assertEqualCmp(t, 5, count)
+		for number, article := range saved {
+			assertEqualCmp(t, number == 2, article.Draft)
+		}
+		rendered, err := NewHugoArticleRenderer().Render(saved[2])
+		require.NoError(t, err)
+		assert.Contains(t, rendered, "draft: true")
+		assert.NotContains(t, rendered, "draft: false")
+	})
+
+	t.Run("permissions are looked up once per author", func(t *testing.T) {
+		gen, issueRepo, _ := newGenerator(config.UntrustedSkip)
+		for range 2 {
+			_, err := gen.Generate(context.Background(), "testuser", "testrepo")
+			require.NoError(t, err)
+		}
+		assertEqualCmp(t, []string{"stranger", "pusher"}, issueRepo.lookups)
+	})
+
+	t.Run("requires a permission store", func(t *testing.T) {
+		gen, _, _ := newGenerator(config.UntrustedSkip)
+		gen.issueRepo = &stubIssueStore{issues: trustTestIssues()}
+		_, err := gen.Generate(context.Background(), "testuser", "testrepo")
+		assert.ErrorContains(t, err, "cannot check permissions")
+	})
+}
+
+func TestArticleGenerator_Generate_DropsUntrustedComments(t *testing.T) {
+	comment := func(author, association, asset string) *github.IssueComment {
+		return &github.IssueComment{
+			Body:              Ptr("Comment of " + author + "\n\n![image](https://github.com/user-attachments/assets/" + asset + ")"),
+			User:              &github.User{Login: Ptr(author)},
+			AuthorAssociation: Ptr(association),
+			CreatedAt:         parseTime("2024-01-02T00:00:00Z"),
+		}
+	}
+	newGenerator := func(untrusted string) (*ArticleGenerator, map[int]*Article) {
+		conf := *config.NewConfig()
+		conf.Output.Comments = &config.OutputCommentsConfig{Mode: config.CommentsModeAppend}
+		conf.GitHub.Trust = &config.GitHubTrustConfig{Untrusted: untrusted, CheckPermission: true, ApprovalLabel: "approved"}
+		issue := trustTestIssues()[0]
+		issue.Comments = Ptr(3)
+		issue.Labels = []*github.Label{{Name: Ptr("approved")}}
+		saved := map[int]*Article{}
+		gen := &ArticleGenerator{
+			issueRepo: &stubPermissionIssueStore{
+				stubIssueStore: stubIssueStore{
+					issues: []*github.Issue{issue},
+					comments: map[int][]*github.IssueComment{1: {
+						comment("member", "MEMBER", "member-asset"),
+						comment("stranger", "NONE", "stranger-asset"),
+						comment("pusher", "NONE", "pusher-asset"),
+					}},
+				},
+				permissions: map[string]string{"pusher": PermissionWrite},
+			},
+			articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+				saved[article.Number] = article
+				return &ArticleOutput{ArticlePath: article.Key + ".md"}, nil
+			}},
+			service: NewArticleService(conf),
+			config:  conf,
+			logger:  slog.Default(),
+		}
+		return gen, saved
+	}
+
+	for _, untrusted := range []string{config.UntrustedSkip, config.UntrustedDraft} {
+		t.Run(untrusted, func(t *testing.T) {
+			gen, saved := newGenerator(untrusted)
+			_, err := gen.Generate(context.Background(), "testuser", "testrepo")
+			require.NoError(t, err)
+
+			article := saved[1]
+			require.NotNil(t, article)
+			assert.Contains(t, article.Content, "Comment of member")
+			assert.Contains(t, article.Content, "Comment of pusher")
+			assert.NotContains(t, article.Content, "stranger")
+			var images []string
+			for _, image := range article.Images {
+				images = append(images, image.URL)
+			}
+			assertEqualCmp(t, []string{
+				"https://github.com/user-attachments/assets/member-asset",
+				"https://github.com/user-attachments/assets/pusher-asset",
+			}, images)
+		})
+	}
+
+	t.Run("publish keeps every comment", func(t *testing.T) {
+		gen, saved := newGenerator(config.UntrustedPublish)
+		_, err := gen.Generate(context.Background(), "testuser", "testrepo")
+		require.NoError(t, err)
+		assert.Contains(t, saved[1].Content, "Comment of stranger")
+	})
+}
+
+func TestArticleGenerator_Prune_RemovesSkippedUntrustedIssues(t *testing.T) {
+	conf := *config.NewConfig()
+	conf.GitHub.Trust = &config.GitHubTrustConfig{Untrusted: config.UntrustedSkip}
+	manifest := NewManifest()
+	for _, issue := range trustTestIssues()[:2] {
+		manifest.record(numberKey(issue.GetNumber()), "", issue, &ArticleOutput{ArticlePath: t.TempDir() + "/index.md"})
+	}
+	gen := &ArticleGenerator{
+		issueRepo: &stubIssueStore{issues: trustTestIssues()[:2]},
+		config:    conf,
+		logger:    slog.Default(),
+	}
+	gen.SetManifest(manifest)
+
+	plan, err := gen.Prune(context.Background(), true)
+	require.NoError(t, err)
+	assertEqualCmp(t, []string{"2"}, plan.Keys)
+}
+
+type stubPermissionIssueStore struct {
+	stubIssueStore
+	permissions map[string]string
+	lookups     []string
+}
+
+func (s *stubPermissionIssueStore) GetPermission(ctx context.Context, username, repository, user string) (string, error) {
+	s.lookups = append(s.lookups, user)
+	if permission, ok := s.permissions[user]; ok {
+		return permission, nil
+	}
+	return PermissionNone, nil
+}
@@ -5,6 +5,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/rokuosan/github-issue-cms/pkg/config"
 	"gopkg.in/yaml.v3"
 )
 
@@ -17,9 +18,30 @@ type Article struct {
 	Category    string      `yaml:"categories"`
 	Tags        []string    `yaml:"tags"`
 	Draft       bool        `yaml:"draft"`
+	Source      string      `yaml:"repository,omitempty"`
 	FrontMatter FrontMatter `yaml:"-"`
 	Key         string      `yaml:"-"`
 	Images      []*Image    `yaml:"-"`
+	Number      int         `yaml:"-"`
+	Comments    []*Comment  `yaml:"-"`
+	// Fields holds the GitHub fields that output.frontMatter.mapping can add
+	// to the front matter, by field name. Unset fields are never written.
+	Fields map[string]any `yaml:"-"`
+	// Taxonomies holds the labels routed by output.frontMatter.taxonomies,
+	// without their prefix, by front-matter key.
+	Taxonomies map[string][]string `yaml:"-"`
+	// Mapping decides the front-matter keys the fields are written to. Nil
+	// writes the default keys.
+	Mapping *config.OutputFrontMatterConfig `yaml:"-"`
+}
+
+// Comment represents one issue comment included in the output.
+type Comment struct {
+	ID      int64  `json:"id"`
+	Author  string `json:"author"`
+	Date    string `json:"date"`
+	URL     string `json:"url,omitempty"`
+	Content string `json:"content"`
 }
 
 // FrontMatter stores normalized metadata values.
@@ -27,11 +49,25 @@ type FrontMatter struct {
 	values map[string]any
 }
 
-// Image represents one image reference found in the issue body.
+// Image represents one image reference found in the issue body or comments.
 type Image struct {
 	URL  string
 	Time string
 	ID   int
+	// DownloadURL, when set, is downloaded instead of URL, such as the signed
+	// URL of a private attachment. URL is still the one replaced in content.
+	DownloadURL string
+}
+
+// ArticleOutput describes the files written for one article.
+type ArticleOutput struct {
+	ArticlePath string
+	ImagePaths  []string
+	// DataPaths lists data files such as comment data written for the article.
+	DataPaths []string
+	// OGPPath is set by post-save hooks that render an OGP image.
+	OGPPath     string
+	ContentHash string
 }
 
 // ImageAsset is a streamed remote asset payload.
@@ -60,14 +96,63 @@ func NewImage(url, time string, id int) *Image {
 	}
 }
 
+// Files returns every generated file path in the output.
+func (o *ArticleOutput) Files() []string {
+	files := make([]string, 0, len(o.ImagePaths)+len(o.DataPaths)+2)
+	if o.ArticlePath != "" {
+		files = append(files, o.ArticlePath)
+	}
+	files = append(files, o.ImagePaths...)
+	files = append(files, o.DataPaths...)
+	if o.OGPPath != "" {
+		files = append(files, o.OGPPath)
+	}
+	return files
+}
+
+// dateLayouts are the date formats ParseDateTime accepts, from issue times
+// to dates written by hand in the front matter.
+var dateLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05",
+	"2006-01-02 15:04:05Z07:00",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04",
+	"2006-01-02",
+}
+
+// ParseDateTime parses the article date. A date with an offset keeps it, so
+// that paths use the clock time written in the date; a date without one is
+// read as UTC.
 func (a *Article) ParseDateTime() (time.Time, error) {
-	if t, err := time.Parse(time.RFC3339, a.Date); err == nil {
-		return t, nil
+	return parseDate(a.Date, nil)
+}
+
+// ParseDateTimeIn parses the article date and converts it to loc, so that
+// paths use the clock time of output.timezone. A date without an offset is
+// read in loc. With a nil loc it is the same as ParseDateTime.
+func (a *Article) ParseDateTimeIn(loc *time.Location) (time.Time, error) {
+	return parseDate(a.Date, loc)
+}
+
+// parseDate parses a date in one of dateLayouts, as described by
+// ParseDateTimeIn.
+func parseDate(value string, loc *time.Location) (time.Time, error) {
+	zone := loc
+	if zone == nil {
+		zone = time.UTC
 	}
-	if t, err := time.Parse("2006-01-02", a.Date); err == nil {
+	for _, layout := range dateLayouts {
+		t, err := time.ParseInLocation(layout, value, zone)
+		if err != nil {
+			continue
+		}
+		if loc != nil {
+			t = t.In(loc)
+		}
 		return t, nil
 	}
-	return time.Time{}, fmt.Errorf("failed to parse date %s", a.Date)
+	return time.Time{}, fmt.Errorf("failed to parse date %s", value)
 }
 
 func (a *Article) Clone() *Article {
@@ -78,6 +163,22 @@ func (a *Article) Clone() *Article {
 	if a.Images != nil {
 		cloned.Images = append([]*Image(nil), a.Images...)
 	}
+	if a.Comments != nil {
+		cloned.Comments = make([]*Comment, len(a.Comments))
+		for i, comment := range a.Comments {
+			c := *comment
+			cloned.Comments[i] = &c
+		}
+	}
+	if a.Taxonomies != nil {
+		cloned.Taxonomies = make(map[string][]string, len(a.Taxonomies))
+		for key, values := range a.Taxonomies {
+			cloned.Taxonomies[key] = append([]string(nil), values...)
+		}
+	}
+	if a.Fields != nil {
+		cloned.Fields = NewFrontMatter(a.Fields).values
+	}
 	cloned.FrontMatter = NewFrontMatter(a.FrontMatter.Values())
 	return &cloned
 }
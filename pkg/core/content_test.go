@@ -3,6 +3,8 @@ package core
 import (
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewImage(t *testing.T) {
@@ -70,6 +72,24 @@ func TestArticle_ParseDateTime(t *testing.T) {
 			want:    time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC),
 			wantErr: false,
 		},
+		{
+			name:    "date and time without offset",
+			date:    "2021-02-03 04:05:06",
+			want:    time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC),
+			wantErr: false,
+		},
+		{
+			name:    "date and time with space and offset",
+			date:    "2021-02-03 04:05:06+09:00",
+			want:    time.Date(2021, 2, 3, 4, 5, 6, 0, time.FixedZone("+0900", 9*60*60)),
+			wantErr: false,
+		},
+		{
+			name:    "fractional seconds",
+			date:    "2021-02-03T04:05:06.5Z",
+			want:    time.Date(2021, 2, 3, 4, 5, 6, 500000000, time.UTC),
+			wantErr: false,
+		},
 		{
 			name:    "invalid format",
 			date:    "invalid-date",
@@ -92,3 +112,25 @@ func TestArticle_ParseDateTime(t *testing.T) {
 		})
 	}
 }
+
+func TestArticle_ParseDateTimeIn(t *testing.T) {
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	require.NoError(t, err)
+	tests := []struct {
+		date string
+		want time.Time
+	}{
+		{date: "2024-01-14T23:30:00Z", want: time.Date(2024, 1, 15, 8, 30, 0, 0, tokyo)},
+		{date: "2024-01-15T08:30:00+09:00", want: time.Date(2024, 1, 15, 8, 30, 0, 0, tokyo)},
+		{date: "2024-01-15 00:30:00", want: time.Date(2024, 1, 15, 0, 30, 0, 0, tokyo)},
+		{date: "2024-01-15", want: time.Date(2024, 1, 15, 0, 0, 0, 0, tokyo)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.date, func(t *testing.T) {
+			got, err := (&Article{Date: tt.date}).ParseDateTimeIn(tokyo)
+			require.NoError(t, err)
+			assertEqualCmp(t, tt.want, got)
+			assertEqualCmp(t, tt.want.Day(), got.Day())
+		})
+	}
+}
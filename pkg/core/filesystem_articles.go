@@ -3,7 +3,10 @@ package core
 import (
 	"context"
 	"crypto/rand"
+	"crypto/sha256"
 	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -27,6 +30,10 @@ type FileSystemArticleRepository struct {
 	imageRepo AssetFetcher
 	renderer  ArticleRenderer
 	logger    *slog.Logger
+	// claims maps every path written by Save, or recorded in the manifest of
+	// an earlier run, to the issue that wrote it, so that two issues
+	// resolving to the same path are detected.
+	claims map[string]string
 }
 
 // NewFileSystemArticleRepository creates a new FileSystemArticleRepository.
@@ -43,10 +50,10 @@ func NewFileSystemArticleRepositoryWithLogger(imageRepo AssetFetcher, logger *sl
 	}
 }
 
-// Save stores an article in the filesystem.
-func (r *FileSystemArticleRepository) Save(ctx context.Context, article *Article, conf config.Config) error {
+// Save stores an article in the filesystem and reports the files it wrote.
+func (r *FileSystemArticleRepository) Save(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
 	if err := ctx.Err(); err != nil {
-		return err
+		return nil, err
 	}
 
 	rendered := article.Clone()
@@ -55,79 +62,339 @@ func (r *FileSystemArticleRepository) Save(ctx context.Context, article *Article
 	rendered.FrontMatter = NewFrontMatter(extra)
 	FilterArticleTags(rendered, conf)
 
-	datetime, err := rendered.ParseDateTime()
+	location, err := conf.Output.Location()
 	if err != nil {
-		return fmt.Errorf("failed to parse datetime: %w", err)
+		return nil, err
+	}
+	datetime, err := rendered.ParseDateTimeIn(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse datetime: %w", err)
 	}
 
-	articleDir, err := resolveArticleDirectory(conf, datetime)
+	articleDir, err := resolveArticleDirectory(conf, rendered, datetime)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	if err := createDirectoryIfNotExist(articleDir); err != nil {
-		return fmt.Errorf("failed to create directory %s: %w", articleDir, err)
+	articlePath, err := resolveArticlePath(conf, rendered, datetime, articleDir)
+	if err != nil {
+		return nil, err
 	}
-
-	articlePath, err := resolveArticlePath(conf, datetime, articleDir)
+	imageDir, imageURLBase, err := resolveImageOutput(conf, rendered, datetime)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	imageDir, imageURLBase, err := resolveImageOutput(conf, datetime)
+	owner := articleOwner(rendered)
+	r.releaseClaims(owner)
+	claimedPath, err := r.claim(conf, owner, articlePath, disambiguateArticlePath(articlePath, rendered.Number))
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if claimedPath != articlePath {
+		// Move images stored inside a disambiguated page bundle along with it.
+		claimedDir := filepath.Dir(claimedPath)
+		if rel, err := filepath.Rel(articleDir, filepath.Clean(imageDir)); err == nil && claimedDir != articleDir && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			imageDir = filepath.Join(claimedDir, rel)
+			imageURLBase = moveURLBase(imageURLBase, filepath.Base(articleDir), filepath.Base(claimedDir))
+		}
+		r.logger.Warn("Article path is already used by another issue; appending the issue number", "issue", rendered.Number, "path", articlePath, "newPath", claimedPath)
+		articleDir, articlePath = claimedDir, claimedPath
 	}
+	if err := createDirectoryIfNotExist(articleDir); err != nil {
+		return nil, fmt.Errorf("failed to create directory %s: %w", articleDir, err)
+	}
+	output := &ArticleOutput{ArticlePath: articlePath}
 	replacements := make([]string, 0, len(rendered.Images)*2)
 	for _, image := range rendered.Images {
 		if err := ctx.Err(); err != nil {
-			return err
+			return nil, err
+		}
+		filename, err := r.saveImage(ctx, image, imageDir, conf, rendered, datetime)
+		if errors.Is(err, ErrPathCollision) {
+			return nil, err
 		}
-		filename, err := r.saveImage(ctx, image, imageDir, conf, datetime)
 		if err != nil {
 			r.logger.Error("Failed to download image", "url", image.URL, "error", err)
 			continue
 		}
+		output.ImagePaths = append(output.ImagePaths, filepath.Join(imageDir, filename))
 		replacements = append(replacements, image.URL, joinURLPath(imageURLBase, filename))
 	}
 	if len(replacements) > 0 {
-		rendered.Content = strings.NewReplacer(replacements...).Replace(rendered.Content)
+		replacer := strings.NewReplacer(replacements...)
+		rendered.Content = replacer.Replace(rendered.Content)
+		for _, comment := range rendered.Comments {
+			comment.Content = replacer.Replace(comment.Content)
+		}
 	}
 
 	text, err := r.renderer.Render(rendered)
 	if err != nil {
-		return fmt.Errorf("failed to render article: %w", err)
+		return nil, fmt.Errorf("failed to render article: %w", err)
 	}
 	if err := createFileAndWrite(articlePath, text); err != nil {
-		return fmt.Errorf("failed to write file %s: %w", articlePath, err)
+		return nil, fmt.Errorf("failed to write file %s: %w", articlePath, err)
+	}
+	output.ContentHash = contentHash(text)
+
+	if comments := conf.Output.Comments; comments.Enabled() && comments.Mode == config.CommentsModeData {
+		dataPath, err := writeCommentsData(conf, datetime, rendered)
+		if err != nil {
+			return nil, err
+		}
+		output.DataPaths = append(output.DataPaths, dataPath)
+	}
+
+	return output, nil
+}
+
+// writeCommentsData writes the article's comments to <directory>/<number>.json.
+func writeCommentsData(conf config.Config, datetime time.Time, article *Article) (string, error) {
+	dataDir, err := ExpandPathTemplate(conf.Output.Comments.DataDirectory(), article, datetime)
+	if err != nil {
+		return "", err
+	}
+	dataDir = filepath.Clean(dataDir)
+	if err := createDirectoryIfNotExist(dataDir); err != nil {
+		return "", fmt.Errorf("failed to create directory %s: %w", dataDir, err)
+	}
+
+	comments := article.Comments
+	if comments == nil {
+		comments = []*Comment{}
+	}
+	data, err := json.MarshalIndent(comments, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to encode comments: %w", err)
 	}
 
-	return nil
+	dataPath := filepath.Join(dataDir, strconv.Itoa(article.Number)+".json")
+	if err := createFileAndWrite(dataPath, string(data)+"\n"); err != nil {
+		return "", fmt.Errorf("failed to write file %s: %w", dataPath, err)
+	}
+	return dataPath, nil
 }
 
-func resolveArticleDirectory(conf config.Config, datetime time.Time) (string, error) {
+// contentHash returns the hex-encoded SHA-256 digest of rendered content.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// ExpandPathTemplate expands an output path template (see
+// config.ParsePathTemplate) for the article. The slug is the slug
+// front-matter value or the title; it, the author and the milestone are
+// slugified so that they are safe in paths and URLs. The title and labels are
+// written as single path segments, and a path that the issue turns into an
+// absolute path or one with .. segments is rejected, since anyone who can
+// open an issue chooses them.
+func ExpandPathTemplate(template string, article *Article, datetime time.Time) (string, error) {
+	parsed, err := config.ParsePathTemplate(template)
+	if err != nil {
+		return "", err
+	}
+	slug, ok := article.FrontMatter.values["slug"].(string)
+	if !ok || slugify(slug) == "" {
+		slug = article.Title
+	}
+	slug = slugify(slug)
+	if slug == "" {
+		slug = strconv.Itoa(article.Number)
+	}
+	labels := make([]string, len(article.Tags))
+	for i, label := range article.Tags {
+		labels[i] = pathSegment(label)
+	}
+	expanded, err := parsed.Execute(config.PathTemplateData{
+		Date:      datetime,
+		Number:    article.Number,
+		Title:     pathSegment(article.Title),
+		Slug:      slug,
+		Author:    slugify(article.Author),
+		Milestone: slugify(article.Category),
+		Labels:    labels,
+	})
+	if err != nil {
+		return "", err
+	}
+	if isAbsolutePath(expanded) && !isAbsolutePath(template) {
+		return "", fmt.Errorf("template %q expands to the absolute path %q for issue #%d", template, expanded, article.Number)
+	}
+	if hasParentSegment(expanded) && !hasParentSegment(template) {
+		return "", fmt.Errorf("template %q expands to %q for issue #%d, which leaves the output directory", template, expanded, article.Number)
+	}
+	return expanded, nil
+}
+
+// pathSegment makes s usable as a single path segment: separators and
+// control characters are replaced, and . and .. become empty.
+func pathSegment(s string) string {
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case r == '/' || r == '\\' || r == ':':
+			return '-'
+		case r < ' ' || r == 0x7f:
+			return -1
+		}
+		return r
+	}, s)
+	s = strings.TrimSpace(s)
+	if s == "." || s == ".." {
+		return ""
+	}
+	return s
+}
+
+func isAbsolutePath(p string) bool {
+	return strings.HasPrefix(p, "/") || strings.HasPrefix(p, "\\") || filepath.IsAbs(p)
+}
+
+func hasParentSegment(p string) bool {
+	for _, segment := range strings.FieldsFunc(p, func(r rune) bool { return r == '/' || r == '\\' }) {
+		if segment == ".." {
+			return true
+		}
+	}
+	return false
+}
+
+func resolveArticleDirectory(conf config.Config, article *Article, datetime time.Time) (string, error) {
 	dest := conf.Output.Articles.Directory
 	if dest == "" {
 		return "", fmt.Errorf("output articles directory is not set")
 	}
-	return filepath.Clean(config.CompileTimeTemplate(datetime, dest)), nil
+	dest, err := ExpandPathTemplate(dest, article, datetime)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Clean(dest), nil
 }
 
-func resolveArticlePath(conf config.Config, datetime time.Time, directory string) (string, error) {
+func resolveArticlePath(conf config.Config, article *Article, datetime time.Time, directory string) (string, error) {
 	filename := conf.Output.Articles.Filename
 	if filename == "" {
 		return "", fmt.Errorf("output articles filename is not set")
 	}
-	filename = config.CompileTimeTemplate(datetime, filename)
+	filename, err := ExpandPathTemplate(filename, article, datetime)
+	if err != nil {
+		return "", err
+	}
 	return filepath.Join(directory, filename), nil
 }
 
-func resolveImageOutput(conf config.Config, datetime time.Time) (string, string, error) {
+func resolveImageOutput(conf config.Config, article *Article, datetime time.Time) (string, string, error) {
 	imageDir := conf.Output.Images.Directory
 	if imageDir == "" {
 		return "", "", fmt.Errorf("output images directory is not set")
 	}
-	imageURLBase := config.CompileTimeTemplate(datetime, conf.Output.Images.URL())
-	return config.CompileTimeTemplate(datetime, imageDir), imageURLBase, nil
+	imageURLBase, err := ExpandPathTemplate(conf.Output.Images.URL(), article, datetime)
+	if err != nil {
+		return "", "", err
+	}
+	imageDir, err = ExpandPathTemplate(imageDir, article, datetime)
+	if err != nil {
+		return "", "", err
+	}
+	return imageDir, imageURLBase, nil
+}
+
+// ErrPathCollision is returned by Save when an article or image path was
+// already written by another issue, in the same run or an earlier one.
+var ErrPathCollision = errors.New("output path collision")
+
+// articleOwner names the issue an article was generated from in collision
+// errors, such as "issue #7" or "owner/repository#7".
+func articleOwner(article *Article) string {
+	return issueOwner(article.Source, article.Number)
+}
+
+func issueOwner(source string, number int) string {
+	if source != "" {
+		return fmt.Sprintf("%s#%d", source, number)
+	}
+	return fmt.Sprintf("issue #%d", number)
+}
+
+// claimManifest claims the article and image paths the manifest records for
+// every issue, so that an issue that is not saved again in this run still
+// owns its outputs.
+func (r *FileSystemArticleRepository) claimManifest(manifest *Manifest) {
+	if r.claims == nil {
+		r.claims = map[string]string{}
+	}
+	for _, entry := range manifest.Articles {
+		owner := issueOwner(entry.Repository, entry.Number)
+		for _, path := range append([]string{entry.ArticlePath}, entry.ImagePaths...) {
+			if _, ok := r.claims[path]; !ok && path != "" {
+				r.claims[path] = owner
+			}
+		}
+	}
+}
+
+// claim records that owner writes path. When another issue wrote path before,
+// it fails or, with the number collision policy, claims
+// alternative instead and returns it.
+func (r *FileSystemArticleRepository) claim(conf config.Config, owner, path, alternative string) (string, error) {
+	if r.claims == nil {
+		r.claims = map[string]string{}
+	}
+	other, ok := r.claims[path]
+	if ok && other != owner {
+		if conf.Output.Articles.CollisionPolicy() != config.CollisionNumber {
+			return "", fmt.Errorf("%w: %s of %s collides with %s; change output.articles or set output.articles.collision to %q", ErrPathCollision, path, owner, other, config.CollisionNumber)
+		}
+		if other, ok := r.claims[alternative]; ok && other != owner {
+			return "", fmt.Errorf("%w: %s of %s collides with %s", ErrPathCollision, alternative, owner, other)
+		}
+		path = alternative
+	}
+	r.claims[path] = owner
+	return path, nil
+}
+
+// releaseClaims forgets the paths owner wrote before, so that an issue saved
+// again, such as by the serve command, can move to other paths.
+func (r *FileSystemArticleRepository) releaseClaims(owner string) {
+	for path, claimedBy := range r.claims {
+		if claimedBy == owner {
+			delete(r.claims, path)
+		}
+	}
+}
+
+// disambiguateArticlePath appends the issue number to the directory of a page
+// bundle (index.md or _index.md) and to the file name of other articles.
+func disambiguateArticlePath(articlePath string, number int) string {
+	base := filepath.Base(articlePath)
+	if stem := strings.TrimSuffix(base, filepath.Ext(base)); stem == "index" || stem == "_index" {
+		return filepath.Join(fmt.Sprintf("%s-%d", filepath.Dir(articlePath), number), base)
+	}
+	return appendToFilename(articlePath, number)
+}
+
+// moveURLBase points an image URL base at a page bundle that was renamed from
+// oldName to newName by replacing the last path segment named oldName.
+// Relative bases follow the bundle by themselves and are returned unchanged.
+func moveURLBase(base, oldName, newName string) string {
+	if !strings.HasPrefix(base, "/") && !strings.Contains(base, "://") {
+		return base
+	}
+	segments := strings.Split(base, "/")
+	for i := len(segments) - 1; i >= 0; i-- {
+		if segments[i] == oldName {
+			segments[i] = newName
+			return strings.Join(segments, "/")
+		}
+	}
+	return base
+}
+
+// appendToFilename appends "-<number>" to the file name of path, before its
+// extension.
+func appendToFilename(path string, number int) string {
+	ext := filepath.Ext(path)
+	return fmt.Sprintf("%s-%d%s", strings.TrimSuffix(path, ext), number, ext)
 }
 
 // createDirectoryIfNotExist creates the directory if it does not exist.
@@ -140,7 +407,7 @@ func createFileAndWrite(path string, content string) error {
 	return os.WriteFile(path, []byte(content), 0o644)
 }
 
-func (r *FileSystemArticleRepository) saveImage(ctx context.Context, image *Image, imageDir string, conf config.Config, datetime time.Time) (string, error) {
+func (r *FileSystemArticleRepository) saveImage(ctx context.Context, image *Image, imageDir string, conf config.Config, article *Article, datetime time.Time) (string, error) {
 	if err := createDirectoryIfNotExist(imageDir); err != nil {
 		return "", fmt.Errorf("failed to create directory %s: %w", imageDir, err)
 	}
@@ -151,7 +418,10 @@ func (r *FileSystemArticleRepository) saveImage(ctx context.Context, image *Imag
 	}
 	defer asset.Body.Close()
 
-	filename := resolveImageFilename(conf, image, datetime)
+	filename, err := resolveImageFilename(conf, image, article, datetime)
+	if err != nil {
+		return "", err
+	}
 	if filepath.Ext(filename) == "" {
 		filename += extensionFromContentType(asset.ContentType)
 	}
@@ -160,6 +430,13 @@ func (r *FileSystemArticleRepository) saveImage(ctx context.Context, image *Imag
 	}
 
 	fullPath := filepath.Join(imageDir, filename)
+	claimedPath, err := r.claim(conf, articleOwner(article), fullPath, appendToFilename(fullPath, article.Number))
+	if err != nil {
+		return "", err
+	}
+	if claimedPath != fullPath {
+		fullPath, filename = claimedPath, filepath.Base(claimedPath)
+	}
 	var existingMode os.FileMode
 	preserveExistingMode := false
 	if info, err := os.Stat(fullPath); err == nil {
@@ -218,14 +495,17 @@ func createImageTempFile(directory string) (*os.File, error) {
 	return nil, fmt.Errorf("could not create a unique temporary file")
 }
 
-func resolveImageFilename(conf config.Config, image *Image, datetime time.Time) string {
+func resolveImageFilename(conf config.Config, image *Image, article *Article, datetime time.Time) (string, error) {
 	filename := conf.Output.Images.Filename
 	if filename == "" {
 		filename = "[:id]"
 	}
 
-	filename = config.CompileTimeTemplate(datetime, filename)
-	return strings.ReplaceAll(filename, "[:id]", strconv.Itoa(image.ID))
+	filename, err := ExpandPathTemplate(filename, article, datetime)
+	if err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(filename, "[:id]", strconv.Itoa(image.ID)), nil
 }
 
 func joinURLPath(base, filename string) string {
@@ -55,7 +55,7 @@ func TestFileSystemArticleRepository_SaveImage_RemovesPartialFileOnFailure(t *te
 	conf.Output.Images.Filename = "[:id].png"
 	repo := &FileSystemArticleRepository{imageRepo: failingImageRepository{}}
 
-	_, err := repo.saveImage(context.Background(), NewImage("https://example.com/image.png", "", 0), tempDir, conf, time.Now())
+	_, err := repo.saveImage(context.Background(), NewImage("https://example.com/image.png", "", 0), tempDir, conf, &Article{}, time.Now())
 	require.Error(t, err)
 	_, statErr := os.Stat(filepath.Join(tempDir, "0.png"))
 	assert.True(t, os.IsNotExist(statErr))
@@ -74,7 +74,7 @@ func TestFileSystemArticleRepository_SaveImage_RespectsProcessUmask(t *testing.T
 	conf.Output.Images.Filename = "[:id].png"
 	repo := &FileSystemArticleRepository{imageRepo: &fakeImageRepository{contentType: "image/png", body: "png"}}
 
-	filename, err := repo.saveImage(context.Background(), NewImage("https://example.com/image.png", "", 0), tempDir, conf, time.Now())
+	filename, err := repo.saveImage(context.Background(), NewImage("https://example.com/image.png", "", 0), tempDir, conf, &Article{}, time.Now())
 	require.NoError(t, err)
 	info, err := os.Stat(filepath.Join(tempDir, filename))
 	require.NoError(t, err)
@@ -92,7 +92,7 @@ func TestFileSystemArticleRepository_SaveImage_PreservesExistingFilePermissions(
 	conf.Output.Images.Filename = filename
 	repo := &FileSystemArticleRepository{imageRepo: &fakeImageRepository{contentType: "image/png", body: "new"}}
 
-	_, err := repo.saveImage(context.Background(), NewImage("https://example.com/image.png", "", 0), tempDir, conf, time.Now())
+	_, err := repo.saveImage(context.Background(), NewImage("https://example.com/image.png", "", 0), tempDir, conf, &Article{}, time.Now())
 	require.NoError(t, err)
 	info, err := os.Stat(fullPath)
 	require.NoError(t, err)
@@ -106,7 +106,7 @@ func TestFileSystemArticleRepository_SaveImage_SupportsMaximumLengthFilename(t *
 	conf.Output.Images.Filename = filename
 	repo := &FileSystemArticleRepository{imageRepo: &fakeImageRepository{contentType: "image/png", body: "png"}}
 
-	savedFilename, err := repo.saveImage(context.Background(), NewImage("https://example.com/image.png", "", 0), tempDir, conf, time.Now())
+	savedFilename, err := repo.saveImage(context.Background(), NewImage("https://example.com/image.png", "", 0), tempDir, conf, &Article{}, time.Now())
 	require.NoError(t, err)
 	assert.Equal(t, filename, savedFilename)
 	_, err = os.Stat(filepath.Join(tempDir, savedFilename))
@@ -197,8 +197,11 @@ func TestFileSystemArticleRepository_Save_RewritesImageURLs(t *testing.T) {
 				Images:   tt.images,
 			}
 
-			err := repo.Save(context.Background(), article, conf)
+			output, err := repo.Save(context.Background(), article, conf)
 			require.NoError(t, err)
+			assertEqualCmp(t, filepath.Join(tempDir, "content", "2021-01-01", "index.md"), output.ArticlePath)
+			assert.Len(t, output.ImagePaths, len(tt.images))
+			assert.NotEmpty(t, output.ContentHash)
 
 			outputPath := filepath.Join(tempDir, "content", "2021-01-01", "index.md")
 			data, err := os.ReadFile(outputPath)
@@ -218,6 +221,50 @@ func TestFileSystemArticleRepository_Save_RewritesImageURLs(t *testing.T) {
 	}
 }
 
+func TestFileSystemArticleRepository_Save_WritesCommentsDataFile(t *testing.T) {
+	tempDir := t.TempDir()
+
+	conf := *config.NewConfig()
+	conf.Output.Articles.Directory = filepath.Join(tempDir, "content")
+	conf.Output.Articles.Filename = "%Y-%m-%d.md"
+	conf.Output.Images.Directory = filepath.Join(tempDir, "static", "images")
+	conf.Output.Images.BaseURL = Ptr("/images")
+	conf.Output.Images.Filename = "[:id].png"
+	conf.Output.Comments = &config.OutputCommentsConfig{
+		Mode:      config.CommentsModeData,
+		Directory: filepath.Join(tempDir, "data", "comments"),
+	}
+
+	repo := &FileSystemArticleRepository{
+		imageRepo: &fakeImageRepository{contentType: "image/png", body: "png"},
+		renderer:  NewHugoArticleRenderer(),
+		logger:    slog.Default(),
+	}
+	article := &Article{
+		Title:   "Title",
+		Content: "Body\n",
+		Date:    "2021-01-01T00:00:00Z",
+		Number:  7,
+		Images:  []*Image{NewImage("https://github.com/user-attachments/assets/comment", "2021-01-01_000000", 0)},
+		Comments: []*Comment{
+			{ID: 1, Author: "reader", Date: "2021-01-02T00:00:00Z", Content: "![shot](https://github.com/user-attachments/assets/comment)"},
+		},
+	}
+
+	output, err := repo.Save(context.Background(), article, conf)
+	require.NoError(t, err)
+
+	dataPath := filepath.Join(tempDir, "data", "comments", "7.json")
+	assertEqualCmp(t, []string{dataPath}, output.DataPaths)
+	data, err := os.ReadFile(dataPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `"content": "![shot](/images/0.png)"`)
+	assert.Contains(t, string(data), `"author": "reader"`)
+
+	// The caller's article is not modified.
+	assertEqualCmp(t, "![shot](https://github.com/user-attachments/assets/comment)", article.Comments[0].Content)
+}
+
 func TestFileSystemArticleRepository_Save_FiltersIssueSelectionLabelsFromFrontMatterTags(t *testing.T) {
 	tempDir := t.TempDir()
 	conf := *config.NewConfig()
@@ -233,7 +280,8 @@ func TestFileSystemArticleRepository_Save_FiltersIssueSelectionLabelsFromFrontMa
 		FrontMatter: NewFrontMatter(map[string]any{"tags": []string{"published", "go"}}),
 	}
 
-	require.NoError(t, repo.Save(context.Background(), article, conf))
+	_, err := repo.Save(context.Background(), article, conf)
+	require.NoError(t, err)
 	data, err := os.ReadFile(filepath.Join(tempDir, "article.md"))
 	require.NoError(t, err)
 	assert.Contains(t, string(data), "go")
@@ -268,7 +316,7 @@ func TestFileSystemArticleRepository_Save_UsesFrontMatterDateForOutputPaths(t *t
 		},
 	}
 
-	err := repo.Save(context.Background(), article, conf)
+	_, err := repo.Save(context.Background(), article, conf)
 	require.NoError(t, err)
 
 	outputPath := filepath.Join(tempDir, "content", "2021-02-03_040506", "index.md")
@@ -311,7 +359,7 @@ func TestFileSystemArticleRepository_Save_AcceptsFrontMatterDateWithOffset(t *te
 		},
 	}
 
-	err := repo.Save(context.Background(), article, conf)
+	_, err := repo.Save(context.Background(), article, conf)
 	require.NoError(t, err)
 
 	outputPath := filepath.Join(tempDir, "content", "2021-02-03_040506", "index.md")
@@ -326,6 +374,321 @@ func TestResolveImageFilename_UsesResolvedArticleDatetime(t *testing.T) {
 	conf.Output.Images.Filename = "%H-[:id].png"
 
 	datetime := time.Date(2021, 2, 3, 4, 5, 6, 0, time.UTC)
-	got := resolveImageFilename(conf, NewImage("https://example.com/image.png", "2021-01-01_000000", 7), datetime)
+	got, err := resolveImageFilename(conf, NewImage("https://example.com/image.png", "2021-01-01_000000", 7), &Article{}, datetime)
+	require.NoError(t, err)
 	assertEqualCmp(t, "04-7.png", got)
 }
+
+func TestExpandPathTemplate(t *testing.T) {
+	datetime := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	article := &Article{
+		Author:      "TestUser",
+		Title:       "Goのテスト",
+		Category:    "Tech Notes",
+		Number:      42,
+		Tags:        []string{"go", "concurrency"},
+		FrontMatter: EmptyFrontMatter(),
+	}
+
+	tests := []struct {
+		name        string
+		template    string
+		frontMatter map[string]any
+		want        string
+	}{
+		{"date only", "%Y/%m/%d", nil, "2024/03/01"},
+		{"article placeholders", "[:milestone]/[:author]/%Y-[:number]-[:slug].md", nil, "tech-notes/testuser/2024-42-go-notesuto.md"},
+		{"slug from front matter", "[:slug]", map[string]any{"slug": "Concurrency in Go"}, "concurrency-in-go"},
+		{"unusable slug falls back to the title", "[:slug]", map[string]any{"slug": "!!!"}, "go-notesuto"},
+		{"kanji slug falls back to the title", "[:slug]", map[string]any{"slug": "並行処理"}, "go-notesuto"},
+		{"go template", "{{ .Date.Year }}/{{ .Slug }}", nil, "2024/go-notesuto"},
+		{"go template mixed with directives", `%Y/{{ printf "%04d" .Number }}-{{ index .Labels 0 }}`, nil, "2024/0042-go"},
+		{"directives inside actions are left to the action", `{{ strftime "%B %%" .Date }}`, nil, "March %"},
+		{"image id is kept", "[:number]-[:id]", nil, "42-[:id]"},
+		{"unknown directives are kept", "%Y/%Q-100%", nil, "2024/%Q-100%"},
+		{"url-encoded bytes are kept", "/%E3%81%82%%AB/%Y", nil, "/%E3%81%82%AB/2024"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			article := article.Clone()
+			article.FrontMatter = NewFrontMatter(tt.frontMatter)
+			got, err := ExpandPathTemplate(tt.template, article, datetime)
+			require.NoError(t, err)
+			assertEqualCmp(t, tt.want, got)
+		})
+	}
+
+	t.Run("title without letters falls back to the number", func(t *testing.T) {
+		article := article.Clone()
+		article.Title = "???"
+		got, err := ExpandPathTemplate("[:slug]", article, datetime)
+		require.NoError(t, err)
+		assertEqualCmp(t, "42", got)
+	})
+
+	t.Run("titles and labels cannot leave the directory", func(t *testing.T) {
+		article := article.Clone()
+		article.Title = "../../etc/passwd"
+		article.Tags = []string{"..", "a/b"}
+		got, err := ExpandPathTemplate("content/{{ .Title }}/{{ index .Labels 0 }}/{{ index .Labels 1 }}.md", article, datetime)
+		require.NoError(t, err)
+		assertEqualCmp(t, "content/..-..-etc-passwd//a-b.md", got)
+
+		article.Title = "..."
+		for _, template := range []string{"content/{{ slice .Title 1 }}/x", `{{ if .Title }}/{{ end }}etc`} {
+			_, err := ExpandPathTemplate(template, article, datetime)
+			assert.Error(t, err, template)
+		}
+		got, err = ExpandPathTemplate("../site/{{ .Title }}", article, datetime)
+		require.NoError(t, err)
+		assertEqualCmp(t, "../site/...", got)
+
+		article.Title = "/etc/passwd"
+		got, err = ExpandPathTemplate("{{ .Title }}", article, datetime)
+		require.NoError(t, err)
+		assertEqualCmp(t, "-etc-passwd", got)
+	})
+
+	t.Run("invalid templates", func(t *testing.T) {
+		for _, template := range []string{"{{ .Date.Year ", "{{ .Unknown }}"} {
+			_, err := ExpandPathTemplate(template, article, datetime)
+			assert.Error(t, err, template)
+		}
+	})
+}
+
+func TestFileSystemArticleRepository_Save_ExpandsArticlePlaceholders(t *testing.T) {
+	tempDir := t.TempDir()
+
+	conf := *config.NewConfig()
+	conf.Output.Articles.Directory = filepath.Join(tempDir, "content", "[:slug]")
+	conf.Output.Articles.Filename = "index.md"
+	conf.Output.Images.Directory = filepath.Join(tempDir, "static", "images", "[:number]")
+	conf.Output.Images.BaseURL = Ptr("/images/[:number]")
+	conf.Output.Images.Filename = "[:id].png"
+
+	imageURL := "https://example.com/image.png"
+	repo := &FileSystemArticleRepository{
+		imageRepo: &fakeImageRepository{contentType: "image/png", body: "png"},
+		renderer:  NewHugoArticleRenderer(),
+		logger:    slog.Default(),
+	}
+	article := &Article{
+		Author:      "Author",
+		Title:       "はじめての投稿",
+		Content:     "![image](" + imageURL + ")",
+		Date:        "2024-01-01T00:00:00Z",
+		Number:      7,
+		FrontMatter: EmptyFrontMatter(),
+		Images:      []*Image{NewImage(imageURL, "2024-01-01_000000", 0)},
+	}
+
+	output, err := repo.Save(context.Background(), article, conf)
+	require.NoError(t, err)
+
+	assertEqualCmp(t, filepath.Join(tempDir, "content", "7", "index.md"), output.ArticlePath)
+	assertEqualCmp(t, []string{filepath.Join(tempDir, "static", "images", "7", "0.png")}, output.ImagePaths)
+	data, err := os.ReadFile(output.ArticlePath)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "![image](/images/7/0.png)")
+}
+
+func TestFileSystemArticleRepository_Save_ExpandsPathsInTimezone(t *testing.T) {
+	tests := []struct {
+		name     string
+		timezone string
+		date     string
+		want     string
+	}{
+		// 08:30 JST on January 15 is still January 14 in UTC.
+		{name: "UTC by default", date: "2024-01-14T23:30:00Z", want: "2024-01-14_2330.md"},
+		{name: "ahead of UTC", timezone: "Asia/Tokyo", date: "2024-01-14T23:30:00Z", want: "2024-01-15_0830.md"},
+		{name: "behind UTC", timezone: "America/Los_Angeles", date: "2024-01-15T03:00:00Z", want: "2024-01-14_1900.md"},
+		{name: "offset converted", timezone: "Asia/Tokyo", date: "2024-12-31T23:59:00-01:00", want: "2025-01-01_0959.md"},
+		{name: "date without offset", timezone: "Asia/Tokyo", date: "2024-01-15 00:30:00", want: "2024-01-15_0030.md"},
+		{name: "offset kept without timezone", date: "2024-01-15T08:30:00+09:00", want: "2024-01-15_0830.md"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tempDir := t.TempDir()
+			conf := *config.NewConfig()
+			conf.Output.Timezone = tt.timezone
+			conf.Output.Articles.Directory = tempDir
+			conf.Output.Articles.Filename = "%Y-%m-%d_%H%M.md"
+			conf.Output.Images.Directory = tempDir
+			repo := &FileSystemArticleRepository{
+				imageRepo: &fakeImageRepository{},
+				renderer:  NewHugoArticleRenderer(),
+				logger:    slog.Default(),
+			}
+			article := &Article{Title: "Title", Date: tt.date, FrontMatter: EmptyFrontMatter()}
+
+			output, err := repo.Save(context.Background(), article, conf)
+			require.NoError(t, err)
+			assertEqualCmp(t, filepath.Join(tempDir, tt.want), output.ArticlePath)
+		})
+	}
+}
+
+func TestFileSystemArticleRepository_Save_DetectsPathCollisions(t *testing.T) {
+	newArticle := func(number int) *Article {
+		imageURL := "https://example.com/image.png"
+		return &Article{
+			Author:      "Author",
+			Title:       "Imported",
+			Content:     "![image](" + imageURL + ")",
+			Date:        "2024-01-01T00:00:00Z",
+			Number:      number,
+			FrontMatter: EmptyFrontMatter(),
+			Images:      []*Image{NewImage(imageURL, "2024-01-01_000000", 0)},
+		}
+	}
+	newRepo := func() *FileSystemArticleRepository {
+		return &FileSystemArticleRepository{
+			imageRepo: &fakeImageRepository{contentType: "image/png", body: "png"},
+			renderer:  NewHugoArticleRenderer(),
+			logger:    slog.Default(),
+		}
+	}
+	newConf := func(dir, collision string) config.Config {
+		conf := *config.NewConfig()
+		conf.Output.Articles.Directory = filepath.Join(dir, "content", "%Y-%m-%d_%H%M%S")
+		conf.Output.Articles.Filename = "index.md"
+		conf.Output.Articles.Collision = collision
+		conf.Output.Images.Directory = filepath.Join(dir, "content", "%Y-%m-%d_%H%M%S", "images")
+		conf.Output.Images.BaseURL = Ptr("images")
+		conf.Output.Images.Filename = "[:id].png"
+		return conf
+	}
+
+	t.Run("fails naming both issues by default", func(t *testing.T) {
+		dir := t.TempDir()
+		conf := newConf(dir, "")
+		repo := newRepo()
+		first, err := repo.Save(context.Background(), newArticle(7), conf)
+		require.NoError(t, err)
+
+		_, err = repo.Save(context.Background(), newArticle(8), conf)
+		require.ErrorIs(t, err, ErrPathCollision)
+		require.ErrorContains(t, err, "of issue #8 collides with issue #7")
+		data, err := os.ReadFile(first.ArticlePath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "Imported")
+	})
+
+	t.Run("saving the same issue again is not a collision", func(t *testing.T) {
+		conf := newConf(t.TempDir(), "")
+		repo := newRepo()
+		_, err := repo.Save(context.Background(), newArticle(7), conf)
+		require.NoError(t, err)
+		_, err = repo.Save(context.Background(), newArticle(7), conf)
+		require.NoError(t, err)
+	})
+
+	t.Run("appends the issue number with the number policy", func(t *testing.T) {
+		dir := t.TempDir()
+		conf := newConf(dir, config.CollisionNumber)
+		repo := newRepo()
+		first, err := repo.Save(context.Background(), newArticle(7), conf)
+		require.NoError(t, err)
+		second, err := repo.Save(context.Background(), newArticle(8), conf)
+		require.NoError(t, err)
+
+		bundle := filepath.Join(dir, "content", "2024-01-01_000000")
+		assertEqualCmp(t, filepath.Join(bundle, "index.md"), first.ArticlePath)
+		assertEqualCmp(t, []string{filepath.Join(bundle, "images", "0.png")}, first.ImagePaths)
+		assertEqualCmp(t, filepath.Join(bundle+"-8", "index.md"), second.ArticlePath)
+		assertEqualCmp(t, []string{filepath.Join(bundle+"-8", "images", "0.png")}, second.ImagePaths)
+	})
+
+	t.Run("image links follow a moved page bundle", func(t *testing.T) {
+		dir := t.TempDir()
+		conf := newConf(dir, config.CollisionNumber)
+		conf.Output.Images.BaseURL = Ptr("/%Y-%m-%d_%H%M%S/images")
+		repo := newRepo()
+		_, err := repo.Save(context.Background(), newArticle(7), conf)
+		require.NoError(t, err)
+		second, err := repo.Save(context.Background(), newArticle(8), conf)
+		require.NoError(t, err)
+
+		data, err := os.ReadFile(second.ArticlePath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "![image](/2024-01-01_000000-8/images/0.png)")
+	})
+
+	t.Run("image collisions fail the save", func(t *testing.T) {
+		dir := t.TempDir()
+		conf := newConf(dir, "")
+		conf.Output.Articles.Filename = "[:number].md"
+		conf.Output.Images.Directory = filepath.Join(dir, "static", "images")
+		repo := newRepo()
+		_, err := repo.Save(context.Background(), newArticle(7), conf)
+		require.NoError(t, err)
+
+		_, err = repo.Save(context.Background(), newArticle(8), conf)
+		require.ErrorIs(t, err, ErrPathCollision)
+		assert.ErrorContains(t, err, "0.png of issue #8 collides with issue #7")
+		assert.NoFileExists(t, filepath.Join(dir, "content", "2024-01-01_000000", "8.md"))
+	})
+
+	t.Run("disambiguates shared image directories", func(t *testing.T) {
+		dir := t.TempDir()
+		conf := newConf(dir, config.CollisionNumber)
+		conf.Output.Articles.Filename = "[:number].md"
+		conf.Output.Images.Directory = filepath.Join(dir, "static", "images", "%Y-%m-%d_%H%M%S")
+		repo := newRepo()
+		_, err := repo.Save(context.Background(), newArticle(7), conf)
+		require.NoError(t, err)
+		second, err := repo.Save(context.Background(), newArticle(8), conf)
+		require.NoError(t, err)
+
+		assertEqualCmp(t, []string{filepath.Join(dir, "static", "images", "2024-01-01_000000", "0-8.png")}, second.ImagePaths)
+		data, err := os.ReadFile(second.ArticlePath)
+		require.NoError(t, err)
+		assert.Contains(t, string(data), "![image](images/0-8.png)")
+	})
+
+	t.Run("issues of different sources collide too", func(t *testing.T) {
+		conf := newConf(t.TempDir(), "")
+		repo := newRepo()
+		first := newArticle(7)
+		first.Source = "octo/blog"
+		_, err := repo.Save(context.Background(), first, conf)
+		require.NoError(t, err)
+
+		second := newArticle(7)
+		second.Source = "octo/notes"
+		_, err = repo.Save(context.Background(), second, conf)
+		assert.ErrorContains(t, err, "of octo/notes#7 collides with octo/blog#7")
+	})
+
+	t.Run("paths of earlier runs are claimed from the manifest", func(t *testing.T) {
+		dir := t.TempDir()
+		conf := newConf(dir, "")
+		first, err := newRepo().Save(context.Background(), newArticle(7), conf)
+		require.NoError(t, err)
+		manifest := NewManifest()
+		manifest.Articles["7"] = &ManifestEntry{Number: 7, ArticlePath: first.ArticlePath, ImagePaths: first.ImagePaths}
+
+		repo := newRepo()
+		repo.claimManifest(manifest)
+		_, err = repo.Save(context.Background(), newArticle(8), conf)
+		require.ErrorIs(t, err, ErrPathCollision)
+		require.ErrorContains(t, err, "of issue #8 collides with issue #7")
+		_, err = repo.Save(context.Background(), newArticle(7), conf)
+		require.NoError(t, err)
+
+		conf = newConf(dir, config.CollisionNumber)
+		repo = newRepo()
+		repo.claimManifest(manifest)
+		second, err := repo.Save(context.Background(), newArticle(8), conf)
+		require.NoError(t, err)
+		assertEqualCmp(t, filepath.Join(dir, "content", "2024-01-01_000000-8", "index.md"), second.ArticlePath)
+	})
+}
+
+func TestDisambiguateArticlePath(t *testing.T) {
+	assertEqualCmp(t, filepath.Join("content", "post-3.md"), disambiguateArticlePath(filepath.Join("content", "post.md"), 3))
+	assertEqualCmp(t, filepath.Join("content", "v1.2-3", "index.md"), disambiguateArticlePath(filepath.Join("content", "v1.2", "index.md"), 3))
+	assertEqualCmp(t, filepath.Join("content", "posts-3", "_index.md"), disambiguateArticlePath(filepath.Join("content", "posts", "_index.md"), 3))
+}
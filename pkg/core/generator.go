@@ -5,6 +5,11 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v86/github"
 	"github.com/rokuosan/github-issue-cms/pkg/config"
@@ -14,14 +19,47 @@ type IssueListQuery struct {
 	Username   string
 	Repository string
 	Labels     []string
+	// Since limits the result to issues updated at or after the given time.
+	// The zero value lists every issue.
+	Since time.Time
+	// Filter narrows the result further.
+	Filter IssueFilter
 }
 
 type IssueStore interface {
 	ListIssues(ctx context.Context, query IssueListQuery) ([]*github.Issue, error)
+	// GetIssue returns a single issue. It returns an error wrapping
+	// ErrIssueNotFound when the issue does not exist or was deleted, and
+	// ErrRepositoryNotFound when the repository cannot be reached, so that
+	// an inaccessible repository is never mistaken for a deleted issue.
+	GetIssue(ctx context.Context, username, repository string, number int) (*github.Issue, error)
+}
+
+// CommentStore lists the comments of an issue. Issue stores implement it to
+// support output.comments.
+type CommentStore interface {
+	ListComments(ctx context.Context, username, repository string, number int) ([]*github.IssueComment, error)
+}
+
+// RenderedIssueStore returns the HTML GitHub renders for an issue. Issue
+// stores implement it to support output.images.resolve: html.
+type RenderedIssueStore interface {
+	// GetIssueHTML returns the rendered issue body, followed by the rendered
+	// comments when withComments is set. It returns an error wrapping
+	// ErrIssueNotFound when the issue does not exist.
+	GetIssueHTML(ctx context.Context, username, repository string, number int, withComments bool) (string, error)
 }
 
 type ArticleStore interface {
-	Save(ctx context.Context, article *Article, conf config.Config) error
+	Save(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error)
+}
+
+// pathClaimer is implemented by article stores that detect output path
+// collisions. The generator hands them the manifest so that paths written in
+// earlier runs count too.
+type pathClaimer interface {
+	claimManifest(manifest *Manifest)
+	releaseClaims(owner string)
 }
 
 // ArticleGenerator generates Hugo articles from GitHub issues.
@@ -31,17 +69,53 @@ type ArticleGenerator struct {
 	service        *ArticleService
 	config         config.Config
 	logger         *slog.Logger
-	onArticleSaved func(article *Article) error
+	onArticleSaved func(article *Article, output *ArticleOutput) error
+	syncState      *SyncState
+	manifest       *Manifest
+	// permissions caches the repository permissions of issue authors by
+	// "owner/repository@user".
+	permissions map[string]string
+	// now returns the current time. It is time.Now when nil.
+	now func() time.Time
 }
 
 // SetOnArticleSaved sets an optional callback that is invoked after each
 // article is successfully saved. The callback can be used to perform
-// post-processing such as OGP image generation. Return an error to
-// log a warning but continue processing remaining articles.
-func (g *ArticleGenerator) SetOnArticleSaved(fn func(article *Article) error) {
+// post-processing such as OGP image generation, and may record additional
+// generated files on output. Return an error to log a warning but continue
+// processing remaining articles.
+func (g *ArticleGenerator) SetOnArticleSaved(fn func(article *Article, output *ArticleOutput) error) {
 	g.onArticleSaved = fn
 }
 
+// SetSyncState enables incremental generation. Generate only lists issues
+// updated since the state's last successful run, skips issues whose
+// updated_at was already saved, and records its progress in state. Issues
+// whose publishDate or expiryDate passed since are processed again. The
+// caller is responsible for loading and persisting the state.
+func (g *ArticleGenerator) SetSyncState(state *SyncState) {
+	g.syncState = state
+}
+
+// SetManifest makes Generate record the files written for each issue in
+// manifest. Paths the manifest assigns to an issue are not given to another
+// one. The caller is responsible for loading and persisting it.
+func (g *ArticleGenerator) SetManifest(manifest *Manifest) {
+	g.manifest = manifest
+	if claimer, ok := g.articleRepo.(pathClaimer); ok && manifest != nil {
+		claimer.claimManifest(manifest)
+	}
+}
+
+// currentTime returns the time output.publish and the sync state are
+// evaluated at.
+func (g *ArticleGenerator) currentTime() time.Time {
+	if g.now != nil {
+		return g.now()
+	}
+	return time.Now()
+}
+
 // NewArticleGenerator creates a new ArticleGenerator.
 func NewArticleGenerator(conf config.Config, token string) (*ArticleGenerator, error) {
 	return NewArticleGeneratorWithLogger(conf, token, nil)
@@ -49,39 +123,150 @@ func NewArticleGenerator(conf config.Config, token string) (*ArticleGenerator, e
 
 // NewArticleGeneratorWithLogger creates a new ArticleGenerator with an injected logger.
 func NewArticleGeneratorWithLogger(conf config.Config, token string, logger *slog.Logger) (*ArticleGenerator, error) {
+	if token == "" {
+		return nil, fmt.Errorf("GitHub token is required")
+	}
+	return NewArticleGeneratorWithTokenSource(conf, StaticTokenSource(token), nil, logger)
+}
+
+// NewArticleGeneratorWithTokenSource creates a new ArticleGenerator that
+// authenticates with the tokens of source, such as a GitHubAppTokenSource.
+// When attachmentSource is not nil, images are downloaded with its tokens
+// instead, for example a classic personal access token for private
+// user-attachments that installation tokens cannot access.
+func NewArticleGeneratorWithTokenSource(conf config.Config, source, attachmentSource TokenSource, logger *slog.Logger) (*ArticleGenerator, error) {
+	if source == nil {
+		return nil, fmt.Errorf("GitHub token is required")
+	}
+
 	// Initialize repositories.
-	issueRepo, err := NewGitHubIssueRepositoryWithLogger(token, logger)
+	issueRepo, err := newIssueStore(conf, source, logger)
 	if err != nil {
 		return nil, err
 	}
 
-	imageRepo := NewHTTPImageRepositoryWithLogger(token, logger)
+	if attachmentSource == nil {
+		attachmentSource = source
+	}
+	imageRepo := newHTTPImageRepository(attachmentSource, trustedImageHosts(conf), NewRetryPolicy(conf), logger)
 	articleRepo := NewFileSystemArticleRepositoryWithLogger(imageRepo, logger)
 
-	// Initialize services.
-	articleService := NewArticleService(conf)
+	return newArticleGenerator(conf, issueRepo, articleRepo, logger), nil
+}
 
+// newArticleGenerator creates an ArticleGenerator that reads issues from
+// issueRepo and writes articles to articleRepo.
+func newArticleGenerator(conf config.Config, issueRepo IssueStore, articleRepo ArticleStore, logger *slog.Logger) *ArticleGenerator {
 	return &ArticleGenerator{
 		issueRepo:   issueRepo,
 		articleRepo: articleRepo,
-		service:     articleService,
+		service:     NewArticleService(conf),
 		config:      conf,
 		logger:      defaultLogger(logger),
+	}
+}
+
+// newIssueStore creates the IssueStore for the API selected in the config.
+func newIssueStore(conf config.Config, tokens TokenSource, logger *slog.Logger) (IssueStore, error) {
+	gh := conf.GitHub
+	baseURL := ""
+	if gh.IsEnterprise() {
+		baseURL = gh.BaseURL
+	}
+	switch api := gh.IssueAPI(); api {
+	case config.GitHubAPIREST:
+		return newGitHubIssueRepository(tokens, baseURL, gh.UploadBaseURL(), NewRetryPolicy(conf), logger)
+	case config.GitHubAPIGraphQL:
+		return newGitHubGraphQLIssueRepository(tokens, baseURL, NewRetryPolicy(conf), logger)
+	default:
+		return nil, fmt.Errorf("unsupported GitHub API %q", api)
+	}
+}
+
+// issueSource is a repository issues are read from together with the
+// configuration its articles are generated with.
+type issueSource struct {
+	username   string
+	repository string
+	config     config.Config
+	service    *ArticleService
+	// name is the "owner/repository" of a github.sources entry. It is empty
+	// for a single repository so that the keys of its state stay unchanged.
+	name string
+}
+
+// source returns the issueSource of the given repository. When github.sources
+// is set, the repository must be one of the sources.
+func (g *ArticleGenerator) source(username, repository string) (issueSource, error) {
+	if !g.config.GitHub.HasSources() {
+		return issueSource{username: username, repository: repository, config: g.config, service: g.service}, nil
+	}
+	source, ok := g.config.GitHub.Source(username, repository)
+	if !ok {
+		return issueSource{}, fmt.Errorf("repository %s/%s is not listed in github.sources", username, repository)
+	}
+	conf := g.config.ForSource(source)
+	return issueSource{
+		username:   source.Username,
+		repository: source.Repository,
+		config:     conf,
+		service:    NewArticleService(conf),
+		name:       source.FullName(),
 	}, nil
 }
 
+// key returns the key an issue of the source is recorded under in the
+// manifest and sync state.
+func (s issueSource) key(number int) string {
+	if s.name == "" {
+		return numberKey(number)
+	}
+	return sourceKey(s.name, number)
+}
+
+// numberOf returns the number of the issue recorded under key when the key
+// belongs to the source.
+func (s issueSource) numberOf(key string) (int, bool) {
+	prefix := ""
+	if s.name != "" {
+		prefix = s.name + "#"
+	}
+	rest, ok := strings.CutPrefix(key, prefix)
+	if !ok {
+		return 0, false
+	}
+	number, err := strconv.Atoi(rest)
+	return number, err == nil
+}
+
+// listQuery builds the query that selects every issue matching the config.
+func (s issueSource) listQuery() IssueListQuery {
+	query := IssueListQuery{
+		Username:   s.username,
+		Repository: s.repository,
+	}
+	if s.config.GitHub != nil {
+		query.Labels = s.config.GitHub.Labels
+		query.Filter = newIssueFilter(s.config.GitHub.Filter)
+	}
+	return query
+}
+
 // GetIssues retrieves all issues from the specified repository.
 func (g *ArticleGenerator) GetIssues(ctx context.Context, username, repository string) ([]*github.Issue, error) {
-	var labels []string
-	if g.config.GitHub != nil {
-		labels = g.config.GitHub.Labels
+	src, err := g.source(username, repository)
+	if err != nil {
+		return nil, err
 	}
+	return g.listIssues(ctx, src)
+}
 
-	return g.issueRepo.ListIssues(ctx, IssueListQuery{
-		Username:   username,
-		Repository: repository,
-		Labels:     labels,
-	})
+func (g *ArticleGenerator) listIssues(ctx context.Context, src issueSource) ([]*github.Issue, error) {
+	query := src.listQuery()
+	if g.syncState != nil {
+		query.Since = g.syncState.since(src.name)
+	}
+	return g.issueRepo.ListIssues(ctx, query)
 }
 
 // ConvertIssueToArticle converts an issue into an Article entity.
@@ -90,54 +275,537 @@ func (g *ArticleGenerator) ConvertIssueToArticle(issue *github.Issue) *Article {
 }
 
 // SaveArticle stores an Article in the filesystem.
-func (g *ArticleGenerator) SaveArticle(ctx context.Context, article *Article) error {
+func (g *ArticleGenerator) SaveArticle(ctx context.Context, article *Article) (*ArticleOutput, error) {
 	return g.articleRepo.Save(ctx, article, g.config)
 }
 
-// Generate fetches issues, converts them to articles, and saves them.
+// Generate fetches issues, converts them to articles, and saves them. Issues
+// that no longer produce an article, for example because they lost their
+// approval, have the outputs of earlier runs removed when a manifest is set.
+// When github.sources is set, it processes the given source and is called
+// once per source to merge every source into one content tree.
 func (g *ArticleGenerator) Generate(ctx context.Context, username, repository string) (int, error) {
+	startedAt := g.currentTime().UTC()
+	src, err := g.source(username, repository)
+	if err != nil {
+		return 0, err
+	}
+	if g.syncState != nil && g.syncState.reconcile(configFingerprint(g.config)) {
+		g.logger.Info("Configuration changed since the last sync; running a full sync")
+	}
+
 	// Fetch issues.
-	issues, err := g.GetIssues(ctx, username, repository)
+	issues, err := g.listIssues(ctx, src)
 	if err != nil {
 		return 0, err
 	}
 
 	g.logger.Info("Found issues", "count", len(issues))
 
+	// Issues whose publishDate or expiryDate passed are processed again even
+	// when they did not change, and those that are not listed are fetched.
+	due := map[int]bool{}
+	if g.syncState != nil {
+		for _, number := range g.syncState.due(src, startedAt) {
+			due[number] = true
+		}
+	}
+
 	// Convert and save articles.
 	successCount := 0
+	skippedCount := 0
 	var saveErr error
 	for _, issue := range issues {
 		if err := ctx.Err(); err != nil {
 			return successCount, err
 		}
-		article := g.ConvertIssueToArticle(issue)
-		if article == nil {
+		number := issue.GetNumber()
+		isDue := due[number]
+		delete(due, number)
+		if !isDue && g.isUpToDate(src.key(number), issue) {
+			g.logger.Debug("Skipping unchanged issue", "issue", number)
+			skippedCount++
 			continue
 		}
-
-		if err := g.SaveArticle(ctx, article); err != nil {
-			g.logger.Error("Failed to save article", "issue", issue.GetNumber(), "error", err)
-			saveErr = errors.Join(saveErr, fmt.Errorf("issue #%d: %w", issue.GetNumber(), err))
+		saved, err := g.saveOrRemoveIssue(ctx, src, issue)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return successCount, ctxErr
+			}
+			g.logger.Error("Failed to save article", "issue", number, "error", err)
+			saveErr = errors.Join(saveErr, fmt.Errorf("issue #%d: %w", number, err))
 			continue
 		}
-		if g.onArticleSaved != nil {
-			if err := g.onArticleSaved(article); err != nil {
-				// Log at Error level: the CLI's default verbosity filters
-				// out Warn, which would make hook failures (e.g. OGP image
-				// generation) completely invisible in a normal run.
-				g.logger.Error("Post-save hook failed for article", "issue", issue.GetNumber(), "error", err)
-			}
-			if err := ctx.Err(); err != nil {
-				return successCount, err
+		if saved {
+			successCount++
+		}
+	}
+	if skippedCount > 0 {
+		g.logger.Info("Skipped unchanged issues", "count", skippedCount)
+	}
+	for _, number := range slices.Sorted(maps.Keys(due)) {
+		g.logger.Info("Publish date or expiry date passed; processing the issue again", "issue", number)
+		outcome, err := g.generateIssue(ctx, src, number)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return successCount, ctxErr
 			}
+			g.logger.Error("Failed to save article", "issue", number, "error", err)
+			saveErr = errors.Join(saveErr, err)
+			continue
+		}
+		if outcome == IssueGenerated {
+			successCount++
 		}
-		successCount++
 	}
 
 	if saveErr != nil {
 		return successCount, fmt.Errorf("failed to save one or more articles: %w", saveErr)
 	}
 
+	// Only advance the since cursor after a clean run so that issues which
+	// failed to save are listed again next time.
+	if g.syncState != nil {
+		g.syncState.advance(src.name, startedAt)
+	}
+
 	return successCount, nil
 }
+
+// saveOrRemoveIssue saves a listed issue when github.approval allows it.
+// When the issue produces no article, the outputs an earlier run generated
+// for it are removed, so that an issue that lost its approval or is now
+// skipped by github.trust or output.publish does not stay published until
+// the next prune.
+func (g *ArticleGenerator) saveOrRemoveIssue(ctx context.Context, src issueSource, issue *github.Issue) (bool, error) {
+	approved, reason, err := g.isApproved(ctx, src, issue)
+	if err != nil {
+		return false, err
+	}
+	saved := false
+	if approved {
+		saved, err = g.saveIssue(ctx, src, issue)
+		if err != nil {
+			return false, err
+		}
+	} else {
+		g.logger.Info("Skipping unapproved issue", "issue", issue.GetNumber(), "reason", reason)
+	}
+	if !saved && g.manifest != nil {
+		remove := g.removeOutputs
+		if !approved {
+			remove = g.removeIssue
+		}
+		if _, err := remove(ctx, src, issue.GetNumber()); err != nil {
+			return false, err
+		}
+	}
+	return saved, nil
+}
+
+// saveIssue converts and saves one issue, runs the post-save hook, and records
+// the outputs in the manifest and sync state. It reports false without an
+// error when the issue does not produce an article.
+func (g *ArticleGenerator) saveIssue(ctx context.Context, src issueSource, issue *github.Issue) (bool, error) {
+	key := src.key(issue.GetNumber())
+	if g.syncState != nil {
+		// Recomputed below for issues output.publish decides on.
+		delete(g.syncState.Pending, key)
+	}
+	action, reason, err := g.untrustedAction(ctx, src, issue)
+	if err != nil {
+		return false, err
+	}
+	if action == config.UntrustedSkip {
+		g.logger.Info("Skipping issue of untrusted author", "issue", issue.GetNumber(), "author", issue.GetUser().GetLogin(), "reason", reason)
+		return false, nil
+	}
+
+	article, err := g.convertIssue(ctx, src, issue)
+	if err != nil {
+		return false, err
+	}
+	if article == nil {
+		return false, nil
+	}
+	article.Source = src.name
+	if action == config.UntrustedDraft {
+		g.logger.Info("Saving issue of untrusted author as a draft", "issue", issue.GetNumber(), "author", issue.GetUser().GetLogin(), "reason", reason)
+		forceDraft(article)
+	}
+	location, err := src.config.Output.Location()
+	if err != nil {
+		return false, err
+	}
+	publish, now := src.config.Output.Publish, g.currentTime()
+	if g.syncState != nil {
+		g.syncState.setPending(key, publishChangesAt(publish, location, article, now))
+	}
+	switch action, reason := publishAction(publish, location, issue, article, now); action {
+	case config.PublishSkip:
+		g.logger.Info("Skipping issue by output.publish", "issue", issue.GetNumber(), "reason", reason)
+		return false, nil
+	case config.PublishDraft:
+		g.logger.Info("Saving issue as a draft by output.publish", "issue", issue.GetNumber(), "reason", reason)
+		forceDraft(article)
+	}
+
+	output, err := g.articleRepo.Save(ctx, article, src.config)
+	if err != nil {
+		return false, err
+	}
+	if g.onArticleSaved != nil {
+		if err := g.onArticleSaved(article, output); err != nil {
+			// Log at Error level: the CLI's default verbosity filters
+			// out Warn, which would make hook failures (e.g. OGP image
+			// generation) completely invisible in a normal run.
+			g.logger.Error("Post-save hook failed for article", "issue", issue.GetNumber(), "error", err)
+		}
+		if err := ctx.Err(); err != nil {
+			return false, err
+		}
+	}
+	if g.manifest != nil {
+		g.manifest.record(key, src.name, issue, output)
+	}
+	if g.syncState != nil {
+		g.syncState.markSynced(key, issue)
+	}
+	return true, nil
+}
+
+// convertIssue converts an issue into an Article, fetching its comments when
+// output.comments is enabled and resolving signed attachment URLs when
+// output.images.resolve is html.
+func (g *ArticleGenerator) convertIssue(ctx context.Context, src issueSource, issue *github.Issue) (*Article, error) {
+	article, err := g.convertIssueContent(ctx, src, issue)
+	if err != nil || article == nil {
+		return article, err
+	}
+	if src.config.Output.Images.ResolveStrategy() == config.ImageResolveHTML && hasUserAttachments(article.Images) {
+		g.resolveAttachments(ctx, src, issue, article)
+	}
+	if err := g.resolveArticleDate(ctx, src, issue, article); err != nil {
+		return nil, err
+	}
+	return article, nil
+}
+
+// resolveArticleDate applies the parts of output.articles.date that need
+// more than the issue itself: the first close event for first_closed, and a
+// warning when front_matter finds no date in the issue body. When the first
+// close cannot be found the last close time is kept, with a warning, since a
+// reopened issue then gets a later date.
+func (g *ArticleGenerator) resolveArticleDate(ctx context.Context, src issueSource, issue *github.Issue, article *Article) error {
+	switch src.config.Output.Articles.DateSource() {
+	case config.DateSourceFirstClosed:
+		if issue.ClosedAt == nil {
+			return nil
+		}
+		store, ok := g.issueRepo.(IssueEventStore)
+		if !ok {
+			g.logger.Warn("The configured issue store cannot list issue events; using the last close time", "issue", issue.GetNumber())
+			return nil
+		}
+		events, err := store.ListIssueEvents(ctx, src.username, src.repository, issue.GetNumber())
+		if err != nil {
+			return fmt.Errorf("failed to list events: %w", err)
+		}
+		closed, ok := firstClosedAt(events)
+		if !ok {
+			g.logger.Warn("The issue has no closed event; using the last close time", "issue", issue.GetNumber())
+			return nil
+		}
+		article.Date = src.service.formatTime(closed)
+	case config.DateSourceFrontMatter:
+		if _, ok := article.FrontMatter.Values()["date"]; !ok {
+			g.logger.Warn("The issue body has no date in its front matter; using the creation time", "issue", issue.GetNumber())
+		}
+	}
+	return nil
+}
+
+// firstClosedAt returns the time of the earliest closed event.
+func firstClosedAt(events []*github.IssueEvent) (time.Time, bool) {
+	var first time.Time
+	for _, event := range events {
+		if event.GetEvent() != "closed" || event.CreatedAt == nil {
+			continue
+		}
+		if first.IsZero() || event.GetCreatedAt().Before(first) {
+			first = event.GetCreatedAt().Time
+		}
+	}
+	return first, !first.IsZero()
+}
+
+// resolveAttachments makes user-attachments images download through the
+// signed URLs in the rendered issue HTML. On failure the images are
+// downloaded from their original URLs.
+func (g *ArticleGenerator) resolveAttachments(ctx context.Context, src issueSource, issue *github.Issue, article *Article) {
+	store, ok := g.issueRepo.(RenderedIssueStore)
+	if !ok {
+		g.logger.Warn("The configured issue store cannot render issues; downloading attachments directly", "issue", issue.GetNumber())
+		return
+	}
+
+	withComments := src.config.Output.Comments.Enabled() && issue.GetComments() > 0
+	renderedHTML, err := store.GetIssueHTML(ctx, src.username, src.repository, issue.GetNumber(), withComments)
+	if err != nil {
+		g.logger.Warn("Failed to fetch rendered issue; downloading attachments directly", "issue", issue.GetNumber(), "error", err)
+		return
+	}
+	resolved := resolveSignedAttachmentURLs(article.Images, renderedHTML, signedAttachmentHosts(src.config))
+	g.logger.Debug("Resolved signed attachment URLs", "issue", issue.GetNumber(), "count", resolved)
+}
+
+// convertIssueContent converts an issue into an Article, fetching its
+// comments when output.comments is enabled.
+func (g *ArticleGenerator) convertIssueContent(ctx context.Context, src issueSource, issue *github.Issue) (*Article, error) {
+	if !src.config.Output.Comments.Enabled() {
+		return src.service.ConvertIssueToArticle(issue), nil
+	}
+
+	var comments []*github.IssueComment
+	if issue.GetComments() > 0 {
+		store, ok := g.issueRepo.(CommentStore)
+		if !ok {
+			return nil, fmt.Errorf("the configured issue store does not support comments")
+		}
+		var err error
+		comments, err = store.ListComments(ctx, src.username, src.repository, issue.GetNumber())
+		if err != nil {
+			return nil, fmt.Errorf("failed to list comments: %w", err)
+		}
+		comments, err = g.trustedComments(ctx, src, issue, comments)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return src.service.ConvertIssueToArticleWithComments(issue, comments), nil
+}
+
+// GenerateIssue fetches a single issue and saves its article. When the issue
+// no longer exists, was transferred to another repository, no longer
+// matches the configured labels, is not approved, or is skipped by
+// github.trust or output.publish, its previously generated files are removed
+// instead.
+func (g *ArticleGenerator) GenerateIssue(ctx context.Context, username, repository string, number int) (IssueOutcome, error) {
+	src, err := g.source(username, repository)
+	if err != nil {
+		return "", err
+	}
+	if g.syncState != nil && g.syncState.reconcile(configFingerprint(g.config)) {
+		g.logger.Info("Configuration changed since the last sync; the next full run rebuilds every article")
+	}
+	return g.generateIssue(ctx, src, number)
+}
+
+// generateIssue implements GenerateIssue for an issue of src.
+func (g *ArticleGenerator) generateIssue(ctx context.Context, src issueSource, number int) (IssueOutcome, error) {
+	issue, err := g.issueRepo.GetIssue(ctx, src.username, src.repository, number)
+	if errors.Is(err, ErrIssueNotFound) {
+		g.logger.Info("Issue no longer exists", "issue", number)
+		return g.removeIssueOutputs(ctx, src, number)
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if !issueBelongsTo(issue, src.username, src.repository) || !issueMatchesQuery(issue, src.listQuery()) {
+		g.logger.Info("Issue no longer matches the configured selection", "issue", number)
+		return g.removeIssueOutputs(ctx, src, number)
+	}
+	approved, reason, err := g.isApproved(ctx, src, issue)
+	if err != nil {
+		return "", fmt.Errorf("issue #%d: %w", number, err)
+	}
+	if !approved {
+		g.logger.Info("Issue is not approved", "issue", number, "reason", reason)
+		return g.removeIssueOutputs(ctx, src, number)
+	}
+
+	saved, err := g.saveIssue(ctx, src, issue)
+	if err != nil {
+		return "", fmt.Errorf("issue #%d: %w", number, err)
+	}
+	if !saved {
+		// Keep the publishDate or expiryDate saveIssue scheduled.
+		return removalOutcome(g.removeOutputs(ctx, src, number))
+	}
+	return IssueGenerated, nil
+}
+
+// RemoveIssue deletes the files generated for an issue of the given
+// repository according to the manifest and forgets the issue in the manifest
+// and sync state.
+func (g *ArticleGenerator) RemoveIssue(ctx context.Context, username, repository string, number int) ([]string, error) {
+	src, err := g.source(username, repository)
+	if err != nil {
+		return nil, err
+	}
+	return g.removeIssue(ctx, src, number)
+}
+
+// removeIssue removes the outputs of an issue and forgets when it was
+// scheduled to be processed again.
+func (g *ArticleGenerator) removeIssue(ctx context.Context, src issueSource, number int) ([]string, error) {
+	files, err := g.removeOutputs(ctx, src, number)
+	if err != nil {
+		return nil, err
+	}
+	if g.syncState != nil {
+		delete(g.syncState.Pending, src.key(number))
+	}
+	return files, nil
+}
+
+// removeOutputs removes the files the manifest records for an issue and
+// forgets the issue in the manifest and sync state. It reports the removed
+// files, or none when the issue had no outputs.
+func (g *ArticleGenerator) removeOutputs(ctx context.Context, src issueSource, number int) ([]string, error) {
+	if g.manifest == nil {
+		return nil, fmt.Errorf("removing an issue requires a manifest")
+	}
+
+	key := src.key(number)
+	entry, ok := g.manifest.Articles[key]
+	if !ok {
+		return nil, nil
+	}
+
+	plan := g.manifest.planPrune(g.manifest.liveKeysExcept(key))
+	var removeErr error
+	for _, file := range plan.Files {
+		if !slices.Contains(entry.Files(), file) {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if err := removeGeneratedFile(file); err != nil {
+			removeErr = errors.Join(removeErr, fmt.Errorf("remove %s: %w", file, err))
+			continue
+		}
+		g.logger.Info("Removed file", "issue", number, "path", file)
+	}
+	if removeErr != nil {
+		return nil, fmt.Errorf("failed to remove files of issue #%d: %w", number, removeErr)
+	}
+
+	delete(g.manifest.Articles, key)
+	if claimer, ok := g.articleRepo.(pathClaimer); ok {
+		claimer.releaseClaims(issueOwner(entry.Repository, entry.Number))
+	}
+	if g.syncState != nil {
+		delete(g.syncState.Issues, key)
+	}
+	return entry.Files(), nil
+}
+
+func (g *ArticleGenerator) removeIssueOutputs(ctx context.Context, src issueSource, number int) (IssueOutcome, error) {
+	return removalOutcome(g.removeIssue(ctx, src, number))
+}
+
+// removalOutcome reports IssueRemoved when files were removed and
+// IssueSkipped when the issue had no outputs.
+func removalOutcome(files []string, err error) (IssueOutcome, error) {
+	if err != nil {
+		return "", err
+	}
+	if len(files) == 0 {
+		return IssueSkipped, nil
+	}
+	return IssueRemoved, nil
+}
+
+// isUpToDate reports whether the issue can be skipped because its current
+// version was already saved. When a manifest is set, issues missing from it
+// are regenerated so that the manifest is always complete.
+func (g *ArticleGenerator) isUpToDate(key string, issue *github.Issue) bool {
+	if g.syncState == nil || !g.syncState.isUpToDate(key, issue) {
+		return false
+	}
+	return g.manifest == nil || g.manifest.has(key)
+}
+
+// Prune removes generated files that are no longer backed by a matching
+// issue: outputs of issues that were deleted, transferred, no longer carry
+// the configured labels, or are skipped by github.trust, github.approval or
+// output.publish, and files an issue stopped referencing (e.g. after its
+// date changed). It lists every matching issue regardless of the sync state
+// and covers every repository in github.sources. With dryRun, nothing is
+// removed and the plan is only returned.
+func (g *ArticleGenerator) Prune(ctx context.Context, dryRun bool) (PrunePlan, error) {
+	if g.manifest == nil {
+		return PrunePlan{}, fmt.Errorf("pruning requires a manifest")
+	}
+
+	live := map[string]struct{}{}
+	for _, source := range g.config.GitHub.SourceList() {
+		src, err := g.source(source.Username, source.Repository)
+		if err != nil {
+			return PrunePlan{}, err
+		}
+		issues, err := g.issueRepo.ListIssues(ctx, src.listQuery())
+		if err != nil {
+			return PrunePlan{}, err
+		}
+		issues, err = g.filterApproved(ctx, src, issues)
+		if err != nil {
+			return PrunePlan{}, err
+		}
+		for _, issue := range issues {
+			action, _, err := g.untrustedAction(ctx, src, issue)
+			if err != nil {
+				return PrunePlan{}, fmt.Errorf("issue #%d: %w", issue.GetNumber(), err)
+			}
+			if action == config.UntrustedSkip {
+				continue
+			}
+			skipped, err := skippedByPublishPolicy(src, issue, action, g.currentTime())
+			if err != nil {
+				return PrunePlan{}, err
+			}
+			if skipped {
+				continue
+			}
+			live[src.key(issue.GetNumber())] = struct{}{}
+		}
+	}
+
+	plan := g.manifest.planPrune(live)
+	if dryRun {
+		return plan, nil
+	}
+
+	var removeErr error
+	for _, file := range plan.Files {
+		if err := ctx.Err(); err != nil {
+			return plan, err
+		}
+		if err := removeGeneratedFile(file); err != nil {
+			removeErr = errors.Join(removeErr, fmt.Errorf("remove %s: %w", file, err))
+			continue
+		}
+		g.logger.Info("Pruned file", "path", file)
+	}
+	if removeErr != nil {
+		return plan, fmt.Errorf("failed to prune one or more files: %w", removeErr)
+	}
+
+	if claimer, ok := g.articleRepo.(pathClaimer); ok {
+		for _, key := range plan.Keys {
+			if entry, ok := g.manifest.Articles[key]; ok {
+				claimer.releaseClaims(issueOwner(entry.Repository, entry.Number))
+			}
+		}
+	}
+	g.manifest.applyPrune(plan)
+	if g.syncState != nil {
+		for _, key := range plan.Keys {
+			delete(g.syncState.Issues, key)
+		}
+	}
+	return plan, nil
+}
@@ -6,12 +6,15 @@ import (
 	"log/slog"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/google/go-github/v86/github"
 	"github.com/rokuosan/github-issue-cms/pkg/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewArticleGenerator(t *testing.T) {
@@ -97,8 +100,9 @@ func TestArticleGenerator_SaveArticle(t *testing.T) {
 	article := gen.ConvertIssueToArticle(issue)
 	assert.NotNil(t, article)
 
-	err = gen.SaveArticle(context.Background(), article)
+	output, err := gen.SaveArticle(context.Background(), article)
 	assert.NoError(t, err)
+	assertEqualCmp(t, tempDir+"/articles/2021-01-01.md", output.ArticlePath)
 }
 
 func TestArticleGenerator_GetIssues(t *testing.T) {
@@ -226,6 +230,124 @@ func TestArticleGenerator_Generate(t *testing.T) {
 	assertEqualCmp(t, 1, count)
 }
 
+func TestArticleGenerator_Generate_DatesArticlesByFirstClose(t *testing.T) {
+	conf := *config.NewConfig()
+	conf.Output.Articles.Date = config.DateSourceFirstClosed
+	issueRepo := &stubEventIssueStore{
+		stubIssueStore: stubIssueStore{issues: []*github.Issue{
+			{Number: Ptr(1), State: Ptr("closed"), CreatedAt: parseTime("2024-01-01T00:00:00Z"), ClosedAt: parseTime("2024-03-01T00:00:00Z")},
+			{Number: Ptr(2), State: Ptr("closed"), CreatedAt: parseTime("2024-01-02T00:00:00Z"), ClosedAt: parseTime("2024-01-05T00:00:00Z")},
+		}},
+		events: map[int][]*github.IssueEvent{
+			// Closed, reopened for an edit and closed again.
+			1: {
+				{Event: Ptr("closed"), CreatedAt: parseTime("2024-01-10T08:00:00Z")},
+				{Event: Ptr("reopened"), CreatedAt: parseTime("2024-02-28T00:00:00Z")},
+				{Event: Ptr("closed"), CreatedAt: parseTime("2024-03-01T00:00:00Z")},
+			},
+		},
+	}
+	saved := map[int]*Article{}
+	gen := &ArticleGenerator{
+		issueRepo: issueRepo,
+		articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+			saved[article.Number] = article
+			return &ArticleOutput{ArticlePath: article.Key + ".md"}, nil
+		}},
+		service: NewArticleService(conf),
+		config:  conf,
+		logger:  slog.Default(),
+	}
+
+	_, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	assertEqualCmp(t, "2024-01-10T08:00:00Z", saved[1].Date)
+	// Without a closed event the close time is kept.
+	assertEqualCmp(t, "2024-01-05T00:00:00Z", saved[2].Date)
+}
+
+func TestFirstClosedAt(t *testing.T) {
+	// Events are not assumed to be in order.
+	events := []*github.IssueEvent{
+		{Event: Ptr("reopened"), CreatedAt: parseTime("2024-02-01T00:00:00Z")},
+		{Event: Ptr("closed"), CreatedAt: parseTime("2024-03-01T00:00:00Z")},
+		{Event: Ptr("closed"), CreatedAt: parseTime("2024-01-10T08:00:00Z")},
+		{Event: Ptr("reopened"), CreatedAt: parseTime("2024-01-12T00:00:00Z")},
+		{Event: Ptr("labeled"), CreatedAt: parseTime("2024-01-01T00:00:00Z")},
+		{Event: Ptr("closed")},
+	}
+	got, ok := firstClosedAt(events)
+	require.True(t, ok)
+	assertEqualCmp(t, parseTime("2024-01-10T08:00:00Z").Time, got)
+
+	_, ok = firstClosedAt(events[:1])
+	assert.False(t, ok)
+}
+
+func TestArticleGenerator_Generate_ResolvesSignedAttachments(t *testing.T) {
+	const (
+		attachment = "https://github.com/user-attachments/assets/0f1e2d3c-aaaa-bbbb-cccc-1234567890ab"
+		signed     = "https://private-user-images.githubusercontent.com/1/2-0f1e2d3c-aaaa-bbbb-cccc-1234567890ab.png?jwt=token"
+	)
+	conf := *config.NewConfig()
+	conf.Output.Images.Resolve = config.ImageResolveHTML
+	issueRepo := &stubRenderedIssueStore{
+		stubIssueStore: stubIssueStore{issues: []*github.Issue{
+			{Number: Ptr(1), Title: Ptr("Private image"), Body: Ptr("![image](" + attachment + ")"), State: Ptr("closed"), CreatedAt: parseTime("2024-01-01T00:00:00Z")},
+		}},
+		html: map[int]string{1: `<p><img src="` + signed + `" alt="image"></p>`},
+	}
+	var saved *Article
+	gen := &ArticleGenerator{
+		issueRepo: issueRepo,
+		articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+			saved = article
+			return &ArticleOutput{ArticlePath: article.Key + ".md"}, nil
+		}},
+		service: NewArticleService(conf),
+		config:  conf,
+		logger:  slog.Default(),
+	}
+
+	_, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	require.Len(t, saved.Images, 1)
+	assertEqualCmp(t, attachment, saved.Images[0].URL)
+	assertEqualCmp(t, signed, saved.Images[0].DownloadURL)
+	assert.False(t, issueRepo.withComments)
+}
+
+func TestArticleGenerator_Generate_FetchesCommentsWhenEnabled(t *testing.T) {
+	conf := *config.NewConfig()
+	conf.Output.Comments = &config.OutputCommentsConfig{Mode: config.CommentsModeAppend}
+	issueRepo := &stubIssueStore{
+		issues: []*github.Issue{
+			{Number: Ptr(1), Title: Ptr("With comments"), Body: Ptr("Body"), State: Ptr("closed"), Comments: Ptr(1), CreatedAt: parseTime("2024-01-01T00:00:00Z")},
+			{Number: Ptr(2), Title: Ptr("Without comments"), Body: Ptr("Body"), State: Ptr("closed"), Comments: Ptr(0), CreatedAt: parseTime("2024-01-02T00:00:00Z")},
+		},
+		comments: map[int][]*github.IssueComment{
+			1: {{Body: Ptr("A comment"), User: &github.User{Login: Ptr("reader")}, CreatedAt: parseTime("2024-01-03T00:00:00Z")}},
+		},
+	}
+	saved := map[int]*Article{}
+	gen := &ArticleGenerator{
+		issueRepo: issueRepo,
+		articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+			saved[article.Number] = article
+			return &ArticleOutput{ArticlePath: article.Key + ".md"}, nil
+		}},
+		service: NewArticleService(conf),
+		config:  conf,
+		logger:  slog.Default(),
+	}
+
+	count, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	assertEqualCmp(t, 2, count)
+	assert.Contains(t, saved[1].Content, "## Comments\n\n### reader (2024-01-03)\n\nA comment\n")
+	assert.NotContains(t, saved[2].Content, "## Comments")
+}
+
 func TestArticleGenerator_Generate_ReturnsContextErrorWhenHookCancels(t *testing.T) {
 	conf := *config.NewConfig()
 	ctx, cancel := context.WithCancel(context.Background())
@@ -245,7 +367,7 @@ func TestArticleGenerator_Generate_ReturnsContextErrorWhenHookCancels(t *testing
 		config:      conf,
 		logger:      slog.Default(),
 	}
-	gen.SetOnArticleSaved(func(*Article) error {
+	gen.SetOnArticleSaved(func(*Article, *ArticleOutput) error {
 		cancel()
 		return context.Canceled
 	})
@@ -280,11 +402,11 @@ func TestArticleGenerator_Generate_ReturnsErrorWhenSaveFails(t *testing.T) {
 			},
 		},
 		articleRepo: stubArticleStore{
-			saveFn: func(ctx context.Context, article *Article, conf config.Config) error {
+			saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
 				if article.Title == "Issue 2" {
-					return fmt.Errorf("save failed")
+					return nil, fmt.Errorf("save failed")
 				}
-				return nil
+				return &ArticleOutput{}, nil
 			},
 		},
 		service: service,
@@ -299,6 +421,432 @@ func TestArticleGenerator_Generate_ReturnsErrorWhenSaveFails(t *testing.T) {
 	assert.Contains(t, err.Error(), "issue #2")
 }
 
+func TestArticleGenerator_Generate_WithSyncStateSkipsUnchangedIssues(t *testing.T) {
+	conf := *config.NewConfig()
+	unchanged := &github.Issue{
+		Number:    github.Ptr(1),
+		Title:     Ptr("Unchanged"),
+		CreatedAt: generatorParseTime("2021-01-01T00:00:00Z"),
+		UpdatedAt: generatorParseTime("2021-01-05T00:00:00Z"),
+		State:     Ptr("closed"),
+	}
+	changed := &github.Issue{
+		Number:    github.Ptr(2),
+		Title:     Ptr("Changed"),
+		CreatedAt: generatorParseTime("2021-01-02T00:00:00Z"),
+		UpdatedAt: generatorParseTime("2021-01-06T00:00:00Z"),
+		State:     Ptr("closed"),
+	}
+
+	state := NewSyncState()
+	state.Fingerprint = configFingerprint(conf)
+	state.LastSyncedAt = time.Date(2021, 1, 5, 12, 0, 0, 0, time.UTC)
+	state.markSynced(numberKey(unchanged.GetNumber()), unchanged)
+	state.Issues["2"] = time.Date(2021, 1, 3, 0, 0, 0, 0, time.UTC)
+
+	var saved []string
+	issueRepo := &stubIssueStore{issues: []*github.Issue{unchanged, changed}}
+	gen := &ArticleGenerator{
+		issueRepo: issueRepo,
+		articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+			saved = append(saved, article.Title)
+			return &ArticleOutput{}, nil
+		}},
+		service: NewArticleService(conf),
+		config:  conf,
+		logger:  slog.Default(),
+	}
+	gen.SetSyncState(state)
+
+	count, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	assert.NoError(t, err)
+	assertEqualCmp(t, 1, count)
+	assertEqualCmp(t, []string{"Changed"}, saved)
+	assertEqualCmp(t, time.Date(2021, 1, 5, 11, 59, 0, 0, time.UTC), issueRepo.lastQuery.Since)
+	assertEqualCmp(t, changed.GetUpdatedAt().Time, state.Issues["2"])
+	assert.True(t, state.LastSyncedAt.After(time.Date(2021, 1, 5, 12, 0, 0, 0, time.UTC)))
+}
+
+func TestArticleGenerator_Generate_WithSyncStateRunsFullSyncAfterConfigChange(t *testing.T) {
+	conf := *config.NewConfig()
+	issue := &github.Issue{
+		Number:    github.Ptr(1),
+		Title:     Ptr("Issue"),
+		CreatedAt: generatorParseTime("2021-01-01T00:00:00Z"),
+		UpdatedAt: generatorParseTime("2021-01-05T00:00:00Z"),
+		State:     Ptr("closed"),
+	}
+
+	state := NewSyncState()
+	state.Fingerprint = "previous-config"
+	state.LastSyncedAt = time.Date(2021, 1, 5, 12, 0, 0, 0, time.UTC)
+	state.markSynced(numberKey(issue.GetNumber()), issue)
+
+	issueRepo := &stubIssueStore{issues: []*github.Issue{issue}}
+	gen := &ArticleGenerator{
+		issueRepo:   issueRepo,
+		articleRepo: stubArticleStore{},
+		service:     NewArticleService(conf),
+		config:      conf,
+		logger:      slog.Default(),
+	}
+	gen.SetSyncState(state)
+
+	count, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	assert.NoError(t, err)
+	assertEqualCmp(t, 1, count)
+	assert.True(t, issueRepo.lastQuery.Since.IsZero())
+	assertEqualCmp(t, configFingerprint(conf), state.Fingerprint)
+}
+
+func TestArticleGenerator_Generate_WithSyncStateKeepsCursorOnFailure(t *testing.T) {
+	conf := *config.NewConfig()
+	lastSynced := time.Date(2021, 1, 5, 12, 0, 0, 0, time.UTC)
+	state := NewSyncState()
+	state.Fingerprint = configFingerprint(conf)
+	state.LastSyncedAt = lastSynced
+
+	gen := &ArticleGenerator{
+		issueRepo: &stubIssueStore{issues: []*github.Issue{
+			{
+				Number:    github.Ptr(1),
+				Title:     Ptr("Saved"),
+				CreatedAt: generatorParseTime("2021-01-01T00:00:00Z"),
+				UpdatedAt: generatorParseTime("2021-01-06T00:00:00Z"),
+				State:     Ptr("closed"),
+			},
+			{
+				Number:    github.Ptr(2),
+				Title:     Ptr("Failed"),
+				CreatedAt: generatorParseTime("2021-01-02T00:00:00Z"),
+				UpdatedAt: generatorParseTime("2021-01-06T00:00:00Z"),
+				State:     Ptr("closed"),
+			},
+		}},
+		articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+			if article.Title == "Failed" {
+				return nil, fmt.Errorf("save failed")
+			}
+			return &ArticleOutput{}, nil
+		}},
+		service: NewArticleService(conf),
+		config:  conf,
+		logger:  slog.Default(),
+	}
+	gen.SetSyncState(state)
+
+	_, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	assert.Error(t, err)
+	assertEqualCmp(t, lastSynced, state.LastSyncedAt)
+	assert.Contains(t, state.Issues, "1")
+	assert.NotContains(t, state.Issues, "2")
+}
+
+func TestArticleGenerator_Generate_RecordsManifest(t *testing.T) {
+	conf := *config.NewConfig()
+	manifest := NewManifest()
+	gen := &ArticleGenerator{
+		issueRepo: &stubIssueStore{issues: []*github.Issue{{
+			Number:    github.Ptr(5),
+			Title:     Ptr("Issue"),
+			CreatedAt: generatorParseTime("2021-01-01T00:00:00Z"),
+			State:     Ptr("closed"),
+		}}},
+		articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+			return &ArticleOutput{ArticlePath: "content/posts/5.md", ContentHash: "hash"}, nil
+		}},
+		service: NewArticleService(conf),
+		config:  conf,
+		logger:  slog.Default(),
+	}
+	gen.SetManifest(manifest)
+	gen.SetOnArticleSaved(func(article *Article, output *ArticleOutput) error {
+		output.OGPPath = "content/posts/5.ogp.jpeg"
+		return nil
+	})
+
+	_, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	assert.NoError(t, err)
+	assertEqualCmp(t, &ManifestEntry{
+		Number:      5,
+		ArticlePath: "content/posts/5.md",
+		OGPPath:     "content/posts/5.ogp.jpeg",
+		ContentHash: "hash",
+	}, manifest.Articles["5"])
+}
+
+func TestArticleGenerator_Generate_RegeneratesUpToDateIssueMissingFromManifest(t *testing.T) {
+	conf := *config.NewConfig()
+	issue := &github.Issue{
+		Number:    github.Ptr(1),
+		Title:     Ptr("Issue"),
+		CreatedAt: generatorParseTime("2021-01-01T00:00:00Z"),
+		UpdatedAt: generatorParseTime("2021-01-02T00:00:00Z"),
+		State:     Ptr("closed"),
+	}
+	state := NewSyncState()
+	state.Fingerprint = configFingerprint(conf)
+	state.markSynced(numberKey(issue.GetNumber()), issue)
+
+	gen := &ArticleGenerator{
+		issueRepo:   &stubIssueStore{issues: []*github.Issue{issue}},
+		articleRepo: stubArticleStore{},
+		service:     NewArticleService(conf),
+		config:      conf,
+		logger:      slog.Default(),
+	}
+	gen.SetSyncState(state)
+	gen.SetManifest(NewManifest())
+
+	count, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	assert.NoError(t, err)
+	assertEqualCmp(t, 1, count)
+}
+
+func TestArticleGenerator_Prune(t *testing.T) {
+	tempDir := t.TempDir()
+	livePath := filepath.Join(tempDir, "live.md")
+	gonePath := filepath.Join(tempDir, "gone", "index.md")
+	goneImage := filepath.Join(tempDir, "gone", "0.png")
+	require.NoError(t, os.MkdirAll(filepath.Dir(gonePath), 0o755))
+	for _, path := range []string{livePath, gonePath, goneImage} {
+		require.NoError(t, os.WriteFile(path, []byte("x"), 0o644))
+	}
+
+	newGenerator := func() (*ArticleGenerator, *Manifest, *SyncState) {
+		manifest := NewManifest()
+		manifest.record(numberKey(1), "", &github.Issue{Number: github.Ptr(1)}, &ArticleOutput{ArticlePath: livePath})
+		manifest.record(numberKey(2), "", &github.Issue{Number: github.Ptr(2)}, &ArticleOutput{ArticlePath: gonePath, ImagePaths: []string{goneImage}})
+		state := NewSyncState()
+		state.Issues["2"] = time.Now()
+		gen := &ArticleGenerator{
+			issueRepo: &stubIssueStore{issues: []*github.Issue{{Number: github.Ptr(1)}}},
+			config:    *config.NewConfig(),
+			logger:    slog.Default(),
+		}
+		gen.SetManifest(manifest)
+		gen.SetSyncState(state)
+		return gen, manifest, state
+	}
+
+	t.Run("dry run only reports", func(t *testing.T) {
+		gen, manifest, _ := newGenerator()
+		plan, err := gen.Prune(context.Background(), true)
+		require.NoError(t, err)
+		assertEqualCmp(t, []string{"2"}, plan.Keys)
+		assertEqualCmp(t, []string{goneImage, gonePath}, plan.Files)
+		assert.FileExists(t, gonePath)
+		assert.Contains(t, manifest.Articles, "2")
+	})
+
+	t.Run("removes stale outputs", func(t *testing.T) {
+		gen, manifest, state := newGenerator()
+		issueRepo := gen.issueRepo.(*stubIssueStore)
+		_, err := gen.Prune(context.Background(), false)
+		require.NoError(t, err)
+		assert.True(t, issueRepo.lastQuery.Since.IsZero(), "pruning must list every matching issue")
+		assert.FileExists(t, livePath)
+		assert.NoFileExists(t, gonePath)
+		assert.NoDirExists(t, filepath.Dir(gonePath))
+		assert.NotContains(t, manifest.Articles, "2")
+		assert.NotContains(t, state.Issues, "2")
+	})
+
+	t.Run("requires a manifest", func(t *testing.T) {
+		gen := &ArticleGenerator{issueRepo: &stubIssueStore{}, logger: slog.Default()}
+		_, err := gen.Prune(context.Background(), true)
+		assert.ErrorContains(t, err, "requires a manifest")
+	})
+}
+
+func TestArticleGenerator_GenerateIssue(t *testing.T) {
+	newGenerator := func(conf config.Config, issues ...*github.Issue) (*ArticleGenerator, *Manifest, *[]string) {
+		var saved []string
+		manifest := NewManifest()
+		gen := &ArticleGenerator{
+			issueRepo: &stubIssueStore{issues: issues},
+			articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+				saved = append(saved, article.Title)
+				return &ArticleOutput{ArticlePath: filepath.Join(t.TempDir(), "article.md")}, nil
+			}},
+			service: NewArticleService(conf),
+			config:  conf,
+			logger:  slog.Default(),
+		}
+		gen.SetManifest(manifest)
+		return gen, manifest, &saved
+	}
+	issue := &github.Issue{
+		Number:        github.Ptr(42),
+		Title:         Ptr("Issue"),
+		CreatedAt:     generatorParseTime("2021-01-01T00:00:00Z"),
+		State:         Ptr("closed"),
+		RepositoryURL: Ptr("https://api.github.com/repos/testuser/testrepo"),
+		Labels:        []*github.Label{{Name: Ptr("Blog")}},
+	}
+
+	t.Run("saves a matching issue", func(t *testing.T) {
+		conf := *config.NewConfig()
+		conf.GitHub.Labels = []string{"blog"}
+		gen, manifest, saved := newGenerator(conf, issue)
+
+		outcome, err := gen.GenerateIssue(context.Background(), "testuser", "testrepo", 42)
+		require.NoError(t, err)
+		assertEqualCmp(t, IssueGenerated, outcome)
+		assertEqualCmp(t, []string{"Issue"}, *saved)
+		assert.Contains(t, manifest.Articles, "42")
+	})
+
+	t.Run("removes an issue without the configured labels", func(t *testing.T) {
+		conf := *config.NewConfig()
+		conf.GitHub.Labels = []string{"blog", "published"}
+		gen, manifest, saved := newGenerator(conf, issue)
+		articlePath := filepath.Join(t.TempDir(), "42.md")
+		require.NoError(t, os.WriteFile(articlePath, []byte("x"), 0o644))
+		manifest.record(numberKey(issue.GetNumber()), "", issue, &ArticleOutput{ArticlePath: articlePath})
+
+		outcome, err := gen.GenerateIssue(context.Background(), "testuser", "testrepo", 42)
+		require.NoError(t, err)
+		assertEqualCmp(t, IssueRemoved, outcome)
+		assert.Empty(t, *saved)
+		assert.NoFileExists(t, articlePath)
+		assert.NotContains(t, manifest.Articles, "42")
+	})
+
+	t.Run("removes a missing issue", func(t *testing.T) {
+		gen, manifest, saved := newGenerator(*config.NewConfig())
+		articlePath := filepath.Join(t.TempDir(), "42.md")
+		require.NoError(t, os.WriteFile(articlePath, []byte("x"), 0o644))
+		manifest.record(numberKey(issue.GetNumber()), "", issue, &ArticleOutput{ArticlePath: articlePath})
+
+		outcome, err := gen.GenerateIssue(context.Background(), "testuser", "testrepo", 42)
+		require.NoError(t, err)
+		assertEqualCmp(t, IssueRemoved, outcome)
+		assert.NoFileExists(t, articlePath)
+		assert.Empty(t, *saved)
+	})
+
+	t.Run("keeps the files of an unreachable repository", func(t *testing.T) {
+		gen, manifest, _ := newGenerator(*config.NewConfig())
+		gen.issueRepo = &stubIssueStore{err: repositoryNotFound("testuser", "testrepo")}
+		articlePath := filepath.Join(t.TempDir(), "42.md")
+		require.NoError(t, os.WriteFile(articlePath, []byte("x"), 0o644))
+		manifest.record(numberKey(issue.GetNumber()), "", issue, &ArticleOutput{ArticlePath: articlePath})
+
+		_, err := gen.GenerateIssue(context.Background(), "testuser", "testrepo", 42)
+		require.ErrorIs(t, err, ErrRepositoryNotFound)
+		assert.FileExists(t, articlePath)
+		assert.Contains(t, manifest.Articles, "42")
+	})
+
+	t.Run("skips a transferred issue without outputs", func(t *testing.T) {
+		transferred := *issue
+		transferred.RepositoryURL = Ptr("https://api.github.com/repos/other/repo")
+		gen, _, saved := newGenerator(*config.NewConfig(), &transferred)
+
+		outcome, err := gen.GenerateIssue(context.Background(), "testuser", "testrepo", 42)
+		require.NoError(t, err)
+		assertEqualCmp(t, IssueSkipped, outcome)
+		assert.Empty(t, *saved)
+	})
+}
+
+func TestArticleGenerator_RemoveIssue_KeepsFilesSharedWithOtherIssues(t *testing.T) {
+	tempDir := t.TempDir()
+	shared := filepath.Join(tempDir, "shared.png")
+	own := filepath.Join(tempDir, "own.md")
+	require.NoError(t, os.WriteFile(shared, []byte("x"), 0o644))
+	require.NoError(t, os.WriteFile(own, []byte("x"), 0o644))
+
+	manifest := NewManifest()
+	manifest.record(numberKey(1), "", &github.Issue{Number: github.Ptr(1)}, &ArticleOutput{ArticlePath: own, ImagePaths: []string{shared}})
+	manifest.record(numberKey(2), "", &github.Issue{Number: github.Ptr(2)}, &ArticleOutput{ImagePaths: []string{shared}})
+	gen := &ArticleGenerator{logger: slog.Default()}
+	gen.SetManifest(manifest)
+
+	files, err := gen.RemoveIssue(context.Background(), "testuser", "testrepo", 1)
+	require.NoError(t, err)
+	assertEqualCmp(t, []string{own, shared}, files)
+	assert.NoFileExists(t, own)
+	assert.FileExists(t, shared)
+}
+
+func TestArticleGenerator_Generate_MergesSources(t *testing.T) {
+	conf := *config.NewConfig()
+	conf.GitHub = &config.GitHubConfig{
+		Username: "octo",
+		Labels:   []string{"blog"},
+		Sources: []config.GitHubSourceConfig{
+			{Repository: "engineering"},
+			{Repository: "design", Labels: []string{"published"}},
+		},
+	}
+	conf.Output.Images.Directory = "static/images/[:repository]/%Y"
+
+	newIssue := func(title string) *github.Issue {
+		return &github.Issue{
+			Number:    github.Ptr(1),
+			Title:     Ptr(title),
+			CreatedAt: generatorParseTime("2021-01-01T00:00:00Z"),
+			UpdatedAt: generatorParseTime("2021-01-02T00:00:00Z"),
+			State:     Ptr("closed"),
+		}
+	}
+	issueRepo := &stubSourceIssueStore{issues: map[string][]*github.Issue{
+		"octo/engineering": {newIssue("Engineering")},
+		"octo/design":      {newIssue("Design")},
+	}}
+
+	type savedArticle struct {
+		Title, Source, ImageDirectory string
+		Labels                        []string
+	}
+	var saved []savedArticle
+	gen := &ArticleGenerator{
+		issueRepo: issueRepo,
+		articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+			saved = append(saved, savedArticle{article.Title, article.Source, conf.Output.Images.Directory, conf.GitHub.Labels})
+			return &ArticleOutput{ArticlePath: article.Title + ".md"}, nil
+		}},
+		service: NewArticleService(conf),
+		config:  conf,
+		logger:  slog.Default(),
+	}
+	manifest := NewManifest()
+	state := NewSyncState()
+	gen.SetManifest(manifest)
+	gen.SetSyncState(state)
+
+	for _, source := range conf.GitHub.SourceList() {
+		count, err := gen.Generate(context.Background(), source.Username, source.Repository)
+		require.NoError(t, err)
+		assertEqualCmp(t, 1, count)
+	}
+
+	assertEqualCmp(t, []savedArticle{
+		{"Engineering", "octo/engineering", "static/images/engineering/%Y", []string{"blog"}},
+		{"Design", "octo/design", "static/images/design/%Y", []string{"published"}},
+	}, saved)
+	assert.Contains(t, manifest.Articles, "octo/engineering#1")
+	assert.Contains(t, manifest.Articles, "octo/design#1")
+	assertEqualCmp(t, "octo/design", manifest.Articles["octo/design#1"].Repository)
+	assert.Contains(t, state.Issues, "octo/engineering#1")
+	assert.Contains(t, state.Issues, "octo/design#1")
+	assert.True(t, state.LastSyncedAt.IsZero())
+	assert.False(t, state.Cursors["octo/engineering"].IsZero())
+	assert.False(t, state.Cursors["octo/design"].IsZero())
+
+	_, err := gen.Generate(context.Background(), "octo", "unknown")
+	assert.ErrorContains(t, err, "not listed in github.sources")
+
+	// Pruning covers every source: only the design issue vanished.
+	issueRepo.issues["octo/design"] = nil
+	plan, err := gen.Prune(context.Background(), true)
+	require.NoError(t, err)
+	assertEqualCmp(t, []string{"octo/design#1"}, plan.Keys)
+	assertEqualCmp(t, []string{"Design.md"}, plan.Files)
+}
+
 // Helper functions.
 
 func generatorParseTime(s string) *github.Timestamp {
@@ -359,8 +907,14 @@ func (m *mockIssueRepository) ListIssues(ctx context.Context, query IssueListQue
 	return allIssues, nil
 }
 
+func (m *mockIssueRepository) GetIssue(ctx context.Context, username, repository string, number int) (*github.Issue, error) {
+	issue, _, err := m.client.Issues.Get(ctx, username, repository, number)
+	return issue, err
+}
+
 type stubIssueStore struct {
 	issues    []*github.Issue
+	comments  map[int][]*github.IssueComment
 	err       error
 	lastQuery IssueListQuery
 }
@@ -370,13 +924,68 @@ func (s *stubIssueStore) ListIssues(ctx context.Context, query IssueListQuery) (
 	return s.issues, s.err
 }
 
+func (s *stubIssueStore) GetIssue(ctx context.Context, username, repository string, number int) (*github.Issue, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	for _, issue := range s.issues {
+		if issue.GetNumber() == number {
+			return issue, nil
+		}
+	}
+	return nil, fmt.Errorf("issue #%d: %w", number, ErrIssueNotFound)
+}
+
+func (s *stubIssueStore) ListComments(ctx context.Context, username, repository string, number int) ([]*github.IssueComment, error) {
+	return s.comments[number], s.err
+}
+
+// stubSourceIssueStore serves the issues of several repositories keyed by
+// "owner/repository".
+type stubSourceIssueStore struct {
+	issues map[string][]*github.Issue
+}
+
+func (s *stubSourceIssueStore) ListIssues(ctx context.Context, query IssueListQuery) ([]*github.Issue, error) {
+	return s.issues[query.Username+"/"+query.Repository], nil
+}
+
+func (s *stubSourceIssueStore) GetIssue(ctx context.Context, username, repository string, number int) (*github.Issue, error) {
+	for _, issue := range s.issues[username+"/"+repository] {
+		if issue.GetNumber() == number {
+			return issue, nil
+		}
+	}
+	return nil, fmt.Errorf("issue #%d: %w", number, ErrIssueNotFound)
+}
+
+type stubEventIssueStore struct {
+	stubIssueStore
+	events map[int][]*github.IssueEvent
+}
+
+func (s *stubEventIssueStore) ListIssueEvents(ctx context.Context, username, repository string, number int) ([]*github.IssueEvent, error) {
+	return s.events[number], s.err
+}
+
+type stubRenderedIssueStore struct {
+	stubIssueStore
+	html         map[int]string
+	withComments bool
+}
+
+func (s *stubRenderedIssueStore) GetIssueHTML(ctx context.Context, username, repository string, number int, withComments bool) (string, error) {
+	s.withComments = withComments
+	return s.html[number], s.err
+}
+
 type stubArticleStore struct {
-	saveFn func(ctx context.Context, article *Article, conf config.Config) error
+	saveFn func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error)
 }
 
-func (s stubArticleStore) Save(ctx context.Context, article *Article, conf config.Config) error {
+func (s stubArticleStore) Save(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
 	if s.saveFn == nil {
-		return nil
+		return &ArticleOutput{ArticlePath: article.Key + ".md"}, nil
 	}
 	return s.saveFn(ctx, article, conf)
 }
@@ -0,0 +1,292 @@
+package core
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+)
+
+// TokenSource provides the token used to authenticate GitHub requests.
+// Implementations may refresh the token between calls.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+type staticTokenSource string
+
+// StaticTokenSource returns a TokenSource that always returns token.
+func StaticTokenSource(token string) TokenSource {
+	return staticTokenSource(token)
+}
+
+func (s staticTokenSource) Token(context.Context) (string, error) {
+	return string(s), nil
+}
+
+const (
+	// appJWTLifetime is how long a GitHub App JWT is valid. GitHub accepts
+	// at most ten minutes.
+	appJWTLifetime = 9 * time.Minute
+	// appJWTClockSkew backdates the JWT issue time to tolerate clock drift.
+	appJWTClockSkew = time.Minute
+	// installationTokenRefreshMargin renews installation tokens this long
+	// before they expire so that no request is sent with an expired token.
+	installationTokenRefreshMargin = 5 * time.Minute
+)
+
+// GitHubAppCredentials identifies a GitHub App installation.
+type GitHubAppCredentials struct {
+	AppID int64
+	// InstallationID selects the installation. When zero, the installation
+	// on the configured repository is looked up.
+	InstallationID int64
+	// PrivateKey is the PEM-encoded private key of the app.
+	PrivateKey []byte
+}
+
+// Environment variables that configure GitHub App authentication. They take
+// precedence over github.app in the configuration file.
+const (
+	EnvGitHubAppID             = "GIC_APP_ID"
+	EnvGitHubAppInstallationID = "GIC_APP_INSTALLATION_ID"
+	// EnvGitHubAppPrivateKey holds the PEM-encoded private key itself.
+	EnvGitHubAppPrivateKey = "GIC_APP_PRIVATE_KEY"
+)
+
+// LoadGitHubAppCredentials resolves GitHub App credentials from the
+// environment and github.app, reading the private key from the PEM file
+// configured in github.app.privateKey unless it is set in the environment.
+// It returns nil when no app ID is configured.
+func LoadGitHubAppCredentials(conf config.Config, getenv func(string) string) (*GitHubAppCredentials, error) {
+	var creds GitHubAppCredentials
+	keyPath := ""
+	if conf.GitHub != nil && conf.GitHub.App != nil {
+		creds.AppID = conf.GitHub.App.ID
+		creds.InstallationID = conf.GitHub.App.InstallationID
+		keyPath = conf.GitHub.App.PrivateKey
+	}
+
+	if value := getenv(EnvGitHubAppID); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvGitHubAppID, err)
+		}
+		creds.AppID = id
+	}
+	if value := getenv(EnvGitHubAppInstallationID); value != "" {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", EnvGitHubAppInstallationID, err)
+		}
+		creds.InstallationID = id
+	}
+	if creds.AppID == 0 {
+		return nil, nil
+	}
+
+	if value := getenv(EnvGitHubAppPrivateKey); value != "" {
+		creds.PrivateKey = []byte(value)
+		return &creds, nil
+	}
+	if keyPath == "" {
+		return nil, fmt.Errorf("GitHub App private key is required; set github.app.privateKey or %s", EnvGitHubAppPrivateKey)
+	}
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return nil, fmt.Errorf("read GitHub App private key: %w", err)
+	}
+	creds.PrivateKey = key
+	return &creds, nil
+}
+
+// GitHubAppTokenSource mints installation tokens for a GitHub App and
+// refreshes them shortly before they expire.
+type GitHubAppTokenSource struct {
+	appID          int64
+	installationID int64
+	owner          string
+	repository     string
+	key            *rsa.PrivateKey
+	client         *github.Client
+	logger         *slog.Logger
+	now            func() time.Time
+
+	mu        sync.Mutex
+	token     string
+	expiresAt time.Time
+}
+
+// NewGitHubAppTokenSource creates a GitHubAppTokenSource for the repository
+// and GitHub host in conf.
+func NewGitHubAppTokenSource(creds GitHubAppCredentials, conf config.Config, logger *slog.Logger) (*GitHubAppTokenSource, error) {
+	if creds.AppID == 0 {
+		return nil, fmt.Errorf("GitHub App ID is required")
+	}
+	key, err := parseAppPrivateKey(creds.PrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	source := &GitHubAppTokenSource{
+		appID:          creds.AppID,
+		installationID: creds.InstallationID,
+		key:            key,
+		logger:         defaultLogger(logger),
+		now:            time.Now,
+	}
+	// One installation covers the repositories of github.sources that share
+	// an owner; the first source identifies it.
+	if sources := conf.GitHub.SourceList(); len(sources) > 0 {
+		source.owner = sources[0].Username
+		source.repository = sources[0].Repository
+	}
+
+	// Every attempt signs a fresh JWT, which may expire while waiting for a
+	// rate limit to reset.
+	authenticated := &appJWTTransport{source: source, base: http.DefaultTransport}
+	client := github.NewClient(&http.Client{Transport: newRetryTransport(NewRetryPolicy(conf), authenticated, defaultHTTPTimeout*time.Second, logger)})
+	if conf.GitHub.IsEnterprise() {
+		client, err = client.WithEnterpriseURLs(conf.GitHub.BaseURL, conf.GitHub.UploadBaseURL())
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GitHub Enterprise client: %w", err)
+		}
+	}
+	source.client = client
+	return source, nil
+}
+
+// Token returns a valid installation token, minting a new one when none was
+// issued yet or the current one is about to expire.
+func (s *GitHubAppTokenSource) Token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.token != "" && s.now().Add(installationTokenRefreshMargin).Before(s.expiresAt) {
+		return s.token, nil
+	}
+
+	if s.installationID == 0 {
+		if s.owner == "" || s.repository == "" {
+			return "", fmt.Errorf("GitHub App installation ID is required when no repository is configured")
+		}
+		installation, _, err := s.client.Apps.FindRepositoryInstallation(ctx, s.owner, s.repository)
+		if err != nil {
+			return "", fmt.Errorf("find GitHub App installation for %s/%s: %w", s.owner, s.repository, err)
+		}
+		s.installationID = installation.GetID()
+	}
+
+	token, _, err := s.client.Apps.CreateInstallationToken(ctx, s.installationID, nil)
+	if err != nil {
+		return "", fmt.Errorf("create GitHub App installation token: %w", err)
+	}
+	s.token = token.GetToken()
+	s.expiresAt = token.GetExpiresAt().Time
+	s.logger.Debug("Minted GitHub App installation token", "installation", s.installationID, "expires", s.expiresAt)
+	return s.token, nil
+}
+
+// jwt returns a JWT that authenticates as the app itself.
+func (s *GitHubAppTokenSource) jwt() (string, error) {
+	now := s.now()
+	header := map[string]string{"alg": "RS256", "typ": "JWT"}
+	claims := map[string]any{
+		"iat": now.Add(-appJWTClockSkew).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(s.appID, 10),
+	}
+
+	encodedHeader, err := encodeJWTSegment(header)
+	if err != nil {
+		return "", err
+	}
+	encodedClaims, err := encodeJWTSegment(claims)
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := encodedHeader + "." + encodedClaims
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("sign GitHub App JWT: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+func encodeJWTSegment(value any) (string, error) {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("encode GitHub App JWT: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// parseAppPrivateKey decodes a PKCS #1 or PKCS #8 PEM-encoded RSA key.
+func parseAppPrivateKey(data []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("GitHub App private key is not PEM encoded")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("parse GitHub App private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("GitHub App private key is not an RSA key")
+	}
+	return key, nil
+}
+
+// appJWTTransport authenticates requests to the app endpoints with a JWT.
+type appJWTTransport struct {
+	source *GitHubAppTokenSource
+	base   http.RoundTripper
+}
+
+func (t *appJWTTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := t.source.jwt()
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	return t.base.RoundTrip(req)
+}
+
+// tokenTransport authenticates requests with the current token of a
+// TokenSource so that refreshed tokens are picked up automatically.
+type tokenTransport struct {
+	source TokenSource
+	base   http.RoundTripper
+}
+
+func (t *tokenTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.source.Token(req.Context())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return t.base.RoundTrip(req)
+}
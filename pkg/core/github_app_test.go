@@ -0,0 +1,224 @@
+package core
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestAppKey(t *testing.T) (*rsa.PrivateKey, []byte) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	data := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	return key, data
+}
+
+// verifyAppJWT checks the RS256 signature of a JWT and returns its claims.
+func verifyAppJWT(t *testing.T, key *rsa.PublicKey, token string) map[string]any {
+	t.Helper()
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], signature))
+
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"alg": "RS256", "typ": "JWT"}`, string(header))
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims map[string]any
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	return claims
+}
+
+func TestGitHubAppTokenSource_JWT(t *testing.T) {
+	key, pemData := newTestAppKey(t)
+	source, err := NewGitHubAppTokenSource(GitHubAppCredentials{AppID: 123, PrivateKey: pemData}, *config.NewConfig(), nil)
+	require.NoError(t, err)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	source.now = func() time.Time { return now }
+
+	jwt, err := source.jwt()
+	require.NoError(t, err)
+
+	claims := verifyAppJWT(t, &key.PublicKey, jwt)
+	assert.Equal(t, "123", claims["iss"])
+	assert.Equal(t, float64(now.Add(-time.Minute).Unix()), claims["iat"])
+	assert.Equal(t, float64(now.Add(9*time.Minute).Unix()), claims["exp"])
+}
+
+func TestGitHubAppTokenSource_Token(t *testing.T) {
+	key, pemData := newTestAppKey(t)
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	var lookups, mints int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		verifyAppJWT(t, &key.PublicKey, strings.TrimPrefix(auth, "Bearer "))
+
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v3/repos/testuser/testrepo/installation":
+			lookups++
+			_, _ = w.Write([]byte(`{"id": 42}`))
+		case r.Method == http.MethodPost && r.URL.Path == "/api/v3/app/installations/42/access_tokens":
+			mints++
+			w.WriteHeader(http.StatusCreated)
+			expires := now.Add(time.Hour).Format(time.RFC3339)
+			_, _ = fmt.Fprintf(w, `{"token": "installation-token-%d", "expires_at": %q}`, mints, expires)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	conf := *config.NewConfig()
+	conf.GitHub.Username = "testuser"
+	conf.GitHub.Repository = "testrepo"
+	conf.GitHub.BaseURL = server.URL + "/"
+	source, err := NewGitHubAppTokenSource(GitHubAppCredentials{AppID: 123, PrivateKey: pemData}, conf, slog.Default())
+	require.NoError(t, err)
+	source.now = func() time.Time { return now }
+
+	token, err := source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "installation-token-1", token)
+
+	// The token is reused while it is valid.
+	now = now.Add(30 * time.Minute)
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "installation-token-1", token)
+
+	// Shortly before it expires, a new token is minted.
+	now = now.Add(26 * time.Minute)
+	token, err = source.Token(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "installation-token-2", token)
+
+	assert.Equal(t, 1, lookups)
+	assert.Equal(t, 2, mints)
+}
+
+func TestGitHubAppTokenSource_FeedsIssueRepository(t *testing.T) {
+	tokens := &countingTokenSource{}
+	var authHeaders []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeaders = append(authHeaders, r.Header.Get("Authorization"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	repo, err := newGitHubIssueRepository(tokens, server.URL+"/", "", DefaultRetryPolicy(), nil)
+	require.NoError(t, err)
+	query := IssueListQuery{Username: "testuser", Repository: "testrepo"}
+	_, err = repo.ListIssues(context.Background(), query)
+	require.NoError(t, err)
+	_, err = repo.ListIssues(context.Background(), query)
+	require.NoError(t, err)
+
+	// Every request asks the source, so refreshed tokens are picked up.
+	assertEqualCmp(t, []string{"Bearer token-1", "Bearer token-2"}, authHeaders)
+}
+
+type countingTokenSource struct {
+	calls int
+}
+
+func (s *countingTokenSource) Token(context.Context) (string, error) {
+	s.calls++
+	return fmt.Sprintf("token-%d", s.calls), nil
+}
+
+func TestLoadGitHubAppCredentials(t *testing.T) {
+	_, pemData := newTestAppKey(t)
+	keyPath := filepath.Join(t.TempDir(), "app.pem")
+	require.NoError(t, os.WriteFile(keyPath, pemData, 0o600))
+
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+
+	t.Run("not configured", func(t *testing.T) {
+		creds, err := LoadGitHubAppCredentials(*config.NewConfig(), env(nil))
+		require.NoError(t, err)
+		assert.Nil(t, creds)
+	})
+
+	t.Run("config file", func(t *testing.T) {
+		conf := *config.NewConfig()
+		conf.GitHub.App = &config.GitHubAppConfig{ID: 1, InstallationID: 2, PrivateKey: keyPath}
+
+		creds, err := LoadGitHubAppCredentials(conf, env(nil))
+		require.NoError(t, err)
+		assertEqualCmp(t, &GitHubAppCredentials{AppID: 1, InstallationID: 2, PrivateKey: pemData}, creds)
+	})
+
+	t.Run("environment takes precedence", func(t *testing.T) {
+		conf := *config.NewConfig()
+		conf.GitHub.App = &config.GitHubAppConfig{ID: 1, PrivateKey: filepath.Join(t.TempDir(), "missing.pem")}
+
+		creds, err := LoadGitHubAppCredentials(conf, env(map[string]string{
+			EnvGitHubAppID:             "10",
+			EnvGitHubAppInstallationID: "20",
+			EnvGitHubAppPrivateKey:     string(pemData),
+		}))
+		require.NoError(t, err)
+		assertEqualCmp(t, &GitHubAppCredentials{AppID: 10, InstallationID: 20, PrivateKey: pemData}, creds)
+	})
+
+	t.Run("missing private key", func(t *testing.T) {
+		_, err := LoadGitHubAppCredentials(*config.NewConfig(), env(map[string]string{EnvGitHubAppID: "10"}))
+		assert.ErrorContains(t, err, "private key is required")
+	})
+
+	t.Run("invalid app ID", func(t *testing.T) {
+		_, err := LoadGitHubAppCredentials(*config.NewConfig(), env(map[string]string{EnvGitHubAppID: "abc"}))
+		assert.ErrorContains(t, err, EnvGitHubAppID)
+	})
+}
+
+func TestParseAppPrivateKey(t *testing.T) {
+	key, pkcs1 := newTestAppKey(t)
+
+	parsed, err := parseAppPrivateKey(pkcs1)
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	parsed, err = parseAppPrivateKey(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+	require.NoError(t, err)
+	assert.True(t, key.Equal(parsed))
+
+	_, err = parseAppPrivateKey([]byte("not a key"))
+	assert.Error(t, err)
+}
@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/google/go-github/v86/github"
 )
@@ -14,6 +17,9 @@ import (
 type GitHubIssueRepository struct {
 	client *github.Client
 	logger *slog.Logger
+	// etags holds the ETag of the last IssuesChanged response by request
+	// path.
+	etags map[string]string
 }
 
 // NewGitHubIssueRepository creates a new GitHubIssueRepository.
@@ -26,10 +32,35 @@ func NewGitHubIssueRepositoryWithLogger(token string, logger *slog.Logger) (Issu
 	if token == "" {
 		return nil, fmt.Errorf("GitHub token is required")
 	}
+	return newGitHubIssueRepository(StaticTokenSource(token), "", "", DefaultRetryPolicy(), logger)
+}
 
-	client := github.NewClient(nil).WithAuthToken(token)
-	if client == nil {
-		return nil, fmt.Errorf("failed to create GitHub client")
+// NewGitHubEnterpriseIssueRepositoryWithLogger creates a new GitHubIssueRepository
+// for a GitHub Enterprise Server. An empty uploadURL defaults to baseURL.
+func NewGitHubEnterpriseIssueRepositoryWithLogger(token, baseURL, uploadURL string, logger *slog.Logger) (IssueStore, error) {
+	if token == "" {
+		return nil, fmt.Errorf("GitHub token is required")
+	}
+	return newGitHubIssueRepository(StaticTokenSource(token), baseURL, uploadURL, DefaultRetryPolicy(), logger)
+}
+
+// newGitHubIssueRepository creates a GitHubIssueRepository that authenticates
+// every request with the current token of tokens and retries failed requests
+// according to retry. A non-empty baseURL selects a GitHub Enterprise Server.
+func newGitHubIssueRepository(tokens TokenSource, baseURL, uploadURL string, retry RetryPolicy, logger *slog.Logger) (*GitHubIssueRepository, error) {
+	// Retries go through tokenTransport so that every attempt uses the
+	// current token.
+	authenticated := &tokenTransport{source: tokens, base: http.DefaultTransport}
+	client := github.NewClient(&http.Client{Transport: newRetryTransport(retry, authenticated, 0, logger)})
+	if baseURL != "" {
+		if uploadURL == "" {
+			uploadURL = baseURL
+		}
+		var err error
+		client, err = client.WithEnterpriseURLs(baseURL, uploadURL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GitHub Enterprise client: %w", err)
+		}
 	}
 
 	return &GitHubIssueRepository{
@@ -61,7 +92,7 @@ func (r *GitHubIssueRepository) ListIssues(ctx context.Context, query IssueListQ
 			return nil, normalizeGitHubIssueError(err)
 		}
 
-		issues = append(issues, filterOutPullRequests(issuesAndPRs)...)
+		issues = append(issues, filterIssues(issuesAndPRs, query.Filter)...)
 		page = resp.NextPage
 		rate = resp.Rate
 	}
@@ -72,14 +103,189 @@ func (r *GitHubIssueRepository) ListIssues(ctx context.Context, query IssueListQ
 	return issues, nil
 }
 
+// IssuesChanged reports whether any issue of the repository of query changed
+// since the previous call for the repository. It requests only the most
+// recently updated issue with If-None-Match, so a poll that finds nothing new
+// is answered with 304 Not Modified and does not count against the rate
+// limit. The labels and filter of query are not applied: an issue that stops
+// matching them, for example because a label was removed, would no longer be
+// listed and its change would go unnoticed. Deleting an issue does not update
+// any other issue, so a deletion is only noticed together with the next
+// change.
+func (r *GitHubIssueRepository) IssuesChanged(ctx context.Context, query IssueListQuery) (bool, error) {
+	if query.Username == "" || query.Repository == "" {
+		return false, fmt.Errorf("username and repository name are required")
+	}
+
+	params := url.Values{}
+	params.Set("state", "all")
+	params.Set("sort", "updated")
+	params.Set("direction", "desc")
+	params.Set("per_page", "1")
+	path := fmt.Sprintf("repos/%v/%v/issues?%s", query.Username, query.Repository, params.Encode())
+
+	req, err := r.client.NewRequest(ctx, http.MethodGet, path, nil)
+	if err != nil {
+		return false, err
+	}
+	if etag := r.etags[path]; etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	resp, err := r.client.Do(req, nil)
+	if resp != nil && resp.StatusCode == http.StatusNotModified {
+		return false, nil
+	}
+	if err != nil {
+		return false, normalizeGitHubIssueError(err)
+	}
+
+	if r.etags == nil {
+		r.etags = map[string]string{}
+	}
+	r.etags[path] = resp.Header.Get("ETag")
+	return true, nil
+}
+
+// GetIssue retrieves a single issue from the specified repository. GitHub
+// also answers 404 when the token cannot see the repository, so a missing
+// issue is only reported once the repository itself was found.
+func (r *GitHubIssueRepository) GetIssue(ctx context.Context, username, repository string, number int) (*github.Issue, error) {
+	if username == "" || repository == "" {
+		return nil, fmt.Errorf("username and repository name are required")
+	}
+
+	issue, _, err := r.client.Issues.Get(ctx, username, repository, number)
+	if isGitHubNotFound(err) {
+		if _, _, err := r.client.Repositories.Get(ctx, username, repository); err != nil {
+			if isGitHubNotFound(err) {
+				return nil, repositoryNotFound(username, repository)
+			}
+			return nil, normalizeGitHubIssueError(err)
+		}
+		return nil, fmt.Errorf("issue #%d: %w", number, ErrIssueNotFound)
+	}
+	if err != nil {
+		return nil, normalizeGitHubIssueError(err)
+	}
+	return issue, nil
+}
+
+// ListComments retrieves every comment of an issue in creation order.
+func (r *GitHubIssueRepository) ListComments(ctx context.Context, username, repository string, number int) ([]*github.IssueComment, error) {
+	var comments []*github.IssueComment
+	opts := &github.IssueListCommentsOptions{ListOptions: github.ListOptions{PerPage: 100, Page: 1}}
+	for opts.Page != 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, resp, err := r.client.Issues.ListComments(ctx, username, repository, number, opts)
+		if err != nil {
+			return nil, normalizeGitHubIssueError(err)
+		}
+		comments = append(comments, page...)
+		opts.Page = resp.NextPage
+	}
+	return comments, nil
+}
+
+// ListIssueEvents retrieves every event of an issue in chronological order.
+func (r *GitHubIssueRepository) ListIssueEvents(ctx context.Context, username, repository string, number int) ([]*github.IssueEvent, error) {
+	var events []*github.IssueEvent
+	opts := &github.ListOptions{PerPage: 100, Page: 1}
+	for opts.Page != 0 {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		page, resp, err := r.client.Issues.ListIssueEvents(ctx, username, repository, number, opts)
+		if err != nil {
+			return nil, normalizeGitHubIssueError(err)
+		}
+		events = append(events, page...)
+		opts.Page = resp.NextPage
+	}
+	return events, nil
+}
+
+// GetPermission retrieves the permission of user on the repository. Users
+// GitHub does not know have PermissionNone.
+func (r *GitHubIssueRepository) GetPermission(ctx context.Context, username, repository, user string) (string, error) {
+	level, _, err := r.client.Repositories.GetPermissionLevel(ctx, username, repository, user)
+	if isGitHubNotFound(err) {
+		return PermissionNone, nil
+	}
+	if err != nil {
+		return "", normalizeGitHubIssueError(err)
+	}
+	return level.GetPermission(), nil
+}
+
+// htmlMediaType requests the rendered body_html of issues and comments.
+const htmlMediaType = "application/vnd.github.html+json"
+
+// GetIssueHTML retrieves the rendered HTML of an issue body and, when
+// withComments is set, of its comments.
+func (r *GitHubIssueRepository) GetIssueHTML(ctx context.Context, username, repository string, number int, withComments bool) (string, error) {
+	if username == "" || repository == "" {
+		return "", fmt.Errorf("username and repository name are required")
+	}
+
+	var issue struct {
+		BodyHTML string `json:"body_html"`
+	}
+	u := fmt.Sprintf("repos/%v/%v/issues/%d", username, repository, number)
+	if _, err := r.getHTML(ctx, u, &issue); err != nil {
+		if isGitHubNotFound(err) {
+			return "", fmt.Errorf("issue #%d: %w", number, ErrIssueNotFound)
+		}
+		return "", normalizeGitHubIssueError(err)
+	}
+	if !withComments {
+		return issue.BodyHTML, nil
+	}
+
+	parts := []string{issue.BodyHTML}
+	for page := 1; page != 0; {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		var comments []struct {
+			BodyHTML string `json:"body_html"`
+		}
+		u := fmt.Sprintf("repos/%v/%v/issues/%d/comments?per_page=100&page=%d", username, repository, number, page)
+		resp, err := r.getHTML(ctx, u, &comments)
+		if err != nil {
+			return "", normalizeGitHubIssueError(err)
+		}
+		for _, comment := range comments {
+			parts = append(parts, comment.BodyHTML)
+		}
+		page = resp.NextPage
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+// getHTML sends a GET request for the HTML media type and decodes the
+// response into v.
+func (r *GitHubIssueRepository) getHTML(ctx context.Context, u string, v any) (*github.Response, error) {
+	req, err := r.client.NewRequest(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", htmlMediaType)
+	return r.client.Do(req, v)
+}
+
 func (r *GitHubIssueRepository) listIssuesPage(ctx context.Context, query IssueListQuery, page int) ([]*github.Issue, *github.Response, error) {
 	return r.client.Issues.ListByRepo(
 		ctx,
 		query.Username,
 		query.Repository,
 		&github.IssueListByRepoOptions{
-			State:  "all",
-			Labels: query.Labels,
+			State:     query.Filter.state(),
+			Labels:    query.Labels,
+			Since:     query.Filter.since(query.Since),
+			Creator:   query.Filter.creator(),
+			Milestone: query.Filter.milestoneParameter(),
 			ListOptions: github.ListOptions{
 				PerPage: 100,
 				Page:    page,
@@ -99,10 +305,40 @@ func filterOutPullRequests(items []*github.Issue) []*github.Issue {
 	return issues
 }
 
+// filterIssues drops pull requests and the issues that do not pass the parts
+// of filter the REST API cannot apply.
+func filterIssues(items []*github.Issue, filter IssueFilter) []*github.Issue {
+	issues := filterOutPullRequests(items)
+	matched := issues[:0]
+	for _, issue := range issues {
+		if filter.Matches(issue) {
+			matched = append(matched, issue)
+		}
+	}
+	return matched
+}
+
 func normalizeGitHubIssueError(err error) error {
 	var ghErr *github.ErrorResponse
 	if errors.As(err, &ghErr) && ghErr.Response != nil && ghErr.Response.StatusCode == http.StatusUnauthorized {
 		return fmt.Errorf("invalid API token; please check your GitHub token")
 	}
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		return fmt.Errorf("GitHub API rate limit exceeded until %s: %w", rateErr.Rate.Reset.Format(time.RFC3339), err)
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		return fmt.Errorf("GitHub API secondary rate limit exceeded: %w", err)
+	}
 	return err
 }
+
+// isGitHubNotFound reports whether err is a 404 Not Found or 410 Gone response.
+func isGitHubNotFound(err error) bool {
+	var ghErr *github.ErrorResponse
+	if !errors.As(err, &ghErr) || ghErr.Response == nil {
+		return false
+	}
+	return ghErr.Response.StatusCode == http.StatusNotFound || ghErr.Response.StatusCode == http.StatusGone
+}
@@ -7,9 +7,11 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/google/go-github/v86/github"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewGitHubIssueRepository(t *testing.T) {
@@ -163,6 +165,99 @@ func TestGitHubIssueRepository_ListIssues_FiltersPRs(t *testing.T) {
 	assertEqualCmp(t, []string{"Real Issue", "Another Issue"}, []string{issues[0].GetTitle(), issues[1].GetTitle()})
 }
 
+func TestFilterIssues(t *testing.T) {
+	label := func(names ...string) []*github.Label {
+		labels := make([]*github.Label, 0, len(names))
+		for _, name := range names {
+			labels = append(labels, &github.Label{Name: Ptr(name)})
+		}
+		return labels
+	}
+	issues := []*github.Issue{
+		{Number: Ptr(1), State: Ptr("closed"), User: &github.User{Login: Ptr("alice")}, Labels: label("blog"), Milestone: &github.Milestone{Number: Ptr(3), Title: Ptr("v1.0")}, CreatedAt: parseTime("2024-01-01T00:00:00Z")},
+		{Number: Ptr(2), State: Ptr("open"), User: &github.User{Login: Ptr("bob")}, Labels: label("news", "wip"), CreatedAt: parseTime("2023-12-31T23:59:59Z")},
+		{Number: Ptr(3), State: Ptr("closed"), User: &github.User{Login: Ptr("Carol")}, Labels: label("internal"), CreatedAt: parseTime("2024-02-01T00:00:00Z")},
+		{Number: Ptr(4), State: Ptr("closed"), PullRequestLinks: &github.PullRequestLinks{}, CreatedAt: parseTime("2024-01-02T00:00:00Z")},
+	}
+
+	tests := []struct {
+		name   string
+		filter IssueFilter
+		want   []int
+	}{
+		{"no filter drops pull requests", IssueFilter{}, []int{1, 2, 3}},
+		{"all states", IssueFilter{State: "all"}, []int{1, 2, 3}},
+		{"closed only", IssueFilter{State: "closed"}, []int{1, 3}},
+		{"exclude labels", IssueFilter{ExcludeLabels: []string{"WIP", "internal"}}, []int{1}},
+		{"any of labels", IssueFilter{AnyLabels: []string{"blog", "news"}}, []int{1, 2}},
+		{"authors", IssueFilter{Authors: []string{"alice", "carol"}}, []int{1, 3}},
+		{"milestone title", IssueFilter{Milestone: "V1.0"}, []int{1}},
+		{"milestone number", IssueFilter{Milestone: "3"}, []int{1}},
+		{"any milestone", IssueFilter{Milestone: "*"}, []int{1}},
+		{"no milestone", IssueFilter{Milestone: "none"}, []int{2, 3}},
+		{"created after", IssueFilter{CreatedAfter: parseTime("2024-01-01T00:00:00Z").Time}, []int{1, 3}},
+		{"created window", IssueFilter{CreatedAfter: parseTime("2023-12-01T00:00:00Z").Time, CreatedBefore: parseTime("2024-02-01T00:00:00Z").Time}, []int{1, 2}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items := append([]*github.Issue(nil), issues...)
+			var got []int
+			for _, issue := range filterIssues(items, tt.filter) {
+				got = append(got, issue.GetNumber())
+			}
+			assertEqualCmp(t, tt.want, got)
+		})
+	}
+}
+
+func TestGitHubIssueRepository_ListIssues_WithFilter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/testuser/testrepo/issues" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		// State, author, milestone number and creation date are applied
+		// server-side; the creation date through since.
+		query := r.URL.Query()
+		assert.Equal(t, "closed", query.Get("state"))
+		assert.Equal(t, "alice", query.Get("creator"))
+		assert.Equal(t, "3", query.Get("milestone"))
+		assert.Equal(t, "2024-01-01T00:00:00Z", query.Get("since"))
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`[
+			{"number": 1, "title": "Published", "state": "closed", "user": {"login": "alice"}, "milestone": {"number": 3}, "labels": [{"name": "blog"}], "created_at": "2024-01-02T00:00:00Z"},
+			{"number": 2, "title": "Draft", "state": "closed", "user": {"login": "alice"}, "milestone": {"number": 3}, "labels": [{"name": "blog"}, {"name": "wip"}], "created_at": "2024-01-03T00:00:00Z"},
+			{"number": 3, "title": "Old", "state": "closed", "user": {"login": "alice"}, "milestone": {"number": 3}, "labels": [{"name": "blog"}], "created_at": "2023-06-01T00:00:00Z"}
+		]`))
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client, err := client.WithEnterpriseURLs(server.URL, server.URL)
+	assert.NoError(t, err)
+	repo := &GitHubIssueRepository{client: client, logger: slog.Default()}
+
+	issues, err := repo.ListIssues(context.Background(), IssueListQuery{
+		Username:   "testuser",
+		Repository: "testrepo",
+		Since:      time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC),
+		Filter: IssueFilter{
+			State:         "closed",
+			ExcludeLabels: []string{"wip"},
+			Authors:       []string{"alice"},
+			Milestone:     "3",
+			CreatedAfter:  time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC),
+		},
+	})
+	assert.NoError(t, err)
+	var titles []string
+	for _, issue := range issues {
+		titles = append(titles, issue.GetTitle())
+	}
+	assertEqualCmp(t, []string{"Published"}, titles)
+}
+
 func TestGitHubIssueRepository_ListIssues_Pagination(t *testing.T) {
 	requestCount := 0
 	var serverURL string
@@ -284,3 +379,199 @@ func TestGitHubIssueRepository_ListIssues_WithLabels(t *testing.T) {
 	assert.Len(t, issues, 1)
 	assert.Equal(t, "Labeled Issue", issues[0].GetTitle())
 }
+
+func TestGitHubIssueRepository_ListIssues_WithSince(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/testuser/testrepo/issues" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		assert.Equal(t, "2024-01-02T03:04:05Z", r.URL.Query().Get("since"))
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client, err := client.WithEnterpriseURLs(server.URL, server.URL)
+	assert.NoError(t, err)
+	repo := &GitHubIssueRepository{client: client, logger: slog.Default()}
+
+	_, err = repo.ListIssues(context.Background(), IssueListQuery{
+		Username:   "testuser",
+		Repository: "testrepo",
+		Since:      time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+	})
+	assert.NoError(t, err)
+}
+
+func TestGitHubIssueRepository_GetIssue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/testuser/testrepo":
+			_, _ = w.Write([]byte(`{"id": 1, "name": "testrepo"}`))
+		case "/api/v3/repos/testuser/testrepo/issues/1":
+			_, _ = w.Write([]byte(`{"id": 1, "number": 1, "title": "Single Issue", "state": "closed"}`))
+		case "/api/v3/repos/testuser/testrepo/issues/2":
+			w.WriteHeader(http.StatusGone)
+			_, _ = w.Write([]byte(`{"message": "This issue was deleted"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client, err := client.WithEnterpriseURLs(server.URL, server.URL)
+	assert.NoError(t, err)
+	repo := &GitHubIssueRepository{client: client, logger: slog.Default()}
+
+	issue, err := repo.GetIssue(context.Background(), "testuser", "testrepo", 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Single Issue", issue.GetTitle())
+
+	_, err = repo.GetIssue(context.Background(), "testuser", "testrepo", 2)
+	assert.ErrorIs(t, err, ErrIssueNotFound)
+
+	_, err = repo.GetIssue(context.Background(), "testuser", "testrepo", 3)
+	assert.ErrorIs(t, err, ErrIssueNotFound)
+
+	_, err = repo.GetIssue(context.Background(), "testuser", "private", 1)
+	assert.ErrorIs(t, err, ErrRepositoryNotFound)
+	assert.NotErrorIs(t, err, ErrIssueNotFound)
+
+	_, err = repo.GetIssue(context.Background(), "", "testrepo", 1)
+	assert.ErrorContains(t, err, "username and repository name are required")
+}
+
+func TestGitHubIssueRepository_ListComments(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v3/repos/testuser/testrepo/issues/3/comments" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Query().Get("page") == "2" {
+			_, _ = w.Write([]byte(`[{"id": 2, "body": "second", "user": {"login": "reader"}}]`))
+			return
+		}
+		w.Header().Set("Link", `<`+"http://"+r.Host+r.URL.Path+`?page=2>; rel="next"`)
+		_, _ = w.Write([]byte(`[{"id": 1, "body": "first", "user": {"login": "testuser"}}]`))
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client, err := client.WithEnterpriseURLs(server.URL, server.URL)
+	assert.NoError(t, err)
+	repo := &GitHubIssueRepository{client: client, logger: slog.Default()}
+
+	comments, err := repo.ListComments(context.Background(), "testuser", "testrepo", 3)
+	assert.NoError(t, err)
+	assertEqualCmp(t, []string{"first", "second"}, []string{comments[0].GetBody(), comments[1].GetBody()})
+}
+
+func TestGitHubIssueRepository_GetPermission(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/testuser/testrepo/collaborators/pusher/permission":
+			_, _ = w.Write([]byte(`{"permission": "write", "role_name": "maintain", "user": {"login": "pusher"}}`))
+		case "/api/v3/repos/testuser/testrepo/collaborators/reader/permission":
+			_, _ = w.Write([]byte(`{"permission": "read", "role_name": "triage", "user": {"login": "reader"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"message": "Not Found"}`))
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client, err := client.WithEnterpriseURLs(server.URL, server.URL)
+	assert.NoError(t, err)
+	repo := &GitHubIssueRepository{client: client, logger: slog.Default()}
+
+	for user, want := range map[string]string{"pusher": PermissionWrite, "reader": PermissionRead, "ghost": PermissionNone} {
+		got, err := repo.GetPermission(context.Background(), "testuser", "testrepo", user)
+		assert.NoError(t, err)
+		assert.Equal(t, want, got, user)
+	}
+}
+
+func TestGitHubIssueRepository_IssuesChanged(t *testing.T) {
+	etag := `"v1"`
+	var queries []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		queries = append(queries, r.URL.RawQuery)
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("ETag", etag)
+		_, _ = w.Write([]byte(`[{"number": 1}]`))
+	}))
+	defer server.Close()
+
+	client, err := github.NewClient(nil).WithEnterpriseURLs(server.URL, server.URL)
+	require.NoError(t, err)
+	repo := &GitHubIssueRepository{client: client, logger: slog.Default()}
+	query := IssueListQuery{Username: "testuser", Repository: "testrepo", Labels: []string{"blog", "public"}, Filter: IssueFilter{Authors: []string{"author"}}}
+
+	for i, want := range []bool{true, false, false} {
+		changed, err := repo.IssuesChanged(context.Background(), query)
+		require.NoError(t, err)
+		assert.Equal(t, want, changed, "poll %d", i)
+	}
+
+	etag = `"v2"`
+	changed, err := repo.IssuesChanged(context.Background(), query)
+	require.NoError(t, err)
+	assert.True(t, changed, "a new ETag is a change")
+
+	// Issues that stop matching the labels or filter must still be noticed.
+	assertEqualCmp(t, "direction=desc&per_page=1&sort=updated&state=all", queries[0])
+}
+
+func TestGitHubIssueRepository_GetIssueHTML(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Accept") != htmlMediaType {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v3/repos/testuser/testrepo/issues/3":
+			_, _ = w.Write([]byte(`{"number": 3, "body_html": "<p>body</p>"}`))
+		case "/api/v3/repos/testuser/testrepo/issues/3/comments":
+			if r.URL.Query().Get("page") == "2" {
+				_, _ = w.Write([]byte(`[{"id": 2, "body_html": "<p>second</p>"}]`))
+				return
+			}
+			w.Header().Set("Link", `<`+"http://"+r.Host+r.URL.Path+`?page=2>; rel="next"`)
+			_, _ = w.Write([]byte(`[{"id": 1, "body_html": "<p>first</p>"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := github.NewClient(nil)
+	client, err := client.WithEnterpriseURLs(server.URL, server.URL)
+	assert.NoError(t, err)
+	repo := &GitHubIssueRepository{client: client, logger: slog.Default()}
+
+	body, err := repo.GetIssueHTML(context.Background(), "testuser", "testrepo", 3, false)
+	assert.NoError(t, err)
+	assertEqualCmp(t, "<p>body</p>", body)
+
+	body, err = repo.GetIssueHTML(context.Background(), "testuser", "testrepo", 3, true)
+	assert.NoError(t, err)
+	assertEqualCmp(t, "<p>body</p>\n<p>first</p>\n<p>second</p>", body)
+
+	_, err = repo.GetIssueHTML(context.Background(), "testuser", "testrepo", 99, false)
+	assert.ErrorIs(t, err, ErrIssueNotFound)
+}
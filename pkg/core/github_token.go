@@ -0,0 +1,121 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// Environment variables that hold a GitHub token, in order of precedence.
+const (
+	EnvGICGitHubToken = "GIC_GITHUB_TOKEN"
+	EnvGitHubToken    = "GITHUB_TOKEN"
+)
+
+// defaultGitHubHost is the gh CLI hosts.yml entry of github.com.
+const defaultGitHubHost = "github.com"
+
+// LoadGitHubToken resolves a GitHub token from the GIC_GITHUB_TOKEN and
+// GITHUB_TOKEN environment variables and the file in github.tokenFile, in
+// that order. It returns the token together with a description of where it
+// was found for logging, or an empty token when none is configured.
+func LoadGitHubToken(conf config.Config, getenv func(string) string) (token, source string, err error) {
+	for _, name := range []string{EnvGICGitHubToken, EnvGitHubToken} {
+		if value := strings.TrimSpace(getenv(name)); value != "" {
+			return value, "environment variable " + name, nil
+		}
+	}
+
+	if conf.GitHub == nil || conf.GitHub.TokenFile == "" {
+		return "", "", nil
+	}
+	path := conf.GitHub.TokenFile
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", "", fmt.Errorf("read github.tokenFile: %w", err)
+	}
+	token = strings.TrimSpace(string(data))
+	if token == "" {
+		return "", "", fmt.Errorf("github.tokenFile %s is empty", path)
+	}
+	return token, "token file " + path, nil
+}
+
+// ghHostsConfig is the part of the gh CLI hosts.yml that holds tokens. Newer
+// gh versions keep the token of the active user in oauth_token and every
+// logged-in user under users.
+type ghHostsConfig map[string]struct {
+	User       string `yaml:"user"`
+	OAuthToken string `yaml:"oauth_token"`
+	Users      map[string]struct {
+		OAuthToken string `yaml:"oauth_token"`
+	} `yaml:"users"`
+}
+
+// LoadGitHubCLIToken reads the token the gh CLI stored for the configured
+// GitHub host in its hosts.yml. It returns an empty token when gh is not
+// logged in to the host or keeps the token in the system keyring.
+func LoadGitHubCLIToken(conf config.Config, getenv func(string) string) (token, source string, err error) {
+	dir := ghConfigDir(getenv)
+	if dir == "" {
+		return "", "", nil
+	}
+	path := filepath.Join(dir, "hosts.yml")
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return "", "", nil
+	}
+	if err != nil {
+		return "", "", fmt.Errorf("read gh CLI config: %w", err)
+	}
+
+	var hosts ghHostsConfig
+	if err := yaml.Unmarshal(data, &hosts); err != nil {
+		return "", "", fmt.Errorf("parse gh CLI config %s: %w", path, err)
+	}
+	host := defaultGitHubHost
+	if conf.GitHub.IsEnterprise() {
+		host = conf.GitHub.EnterpriseHost()
+	}
+	for name, entry := range hosts {
+		if !strings.EqualFold(name, host) {
+			continue
+		}
+		token = entry.OAuthToken
+		if token == "" {
+			token = entry.Users[entry.User].OAuthToken
+		}
+		if token == "" {
+			return "", "", nil
+		}
+		return token, "gh CLI config " + path, nil
+	}
+	return "", "", nil
+}
+
+// ghConfigDir returns the configuration directory of the gh CLI, following
+// the same rules as gh itself.
+func ghConfigDir(getenv func(string) string) string {
+	if dir := getenv("GH_CONFIG_DIR"); dir != "" {
+		return dir
+	}
+	if dir := getenv("XDG_CONFIG_HOME"); dir != "" {
+		return filepath.Join(dir, "gh")
+	}
+	if runtime.GOOS == "windows" {
+		if dir := getenv("AppData"); dir != "" {
+			return filepath.Join(dir, "GitHub CLI")
+		}
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gh")
+}
@@ -0,0 +1,119 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadGitHubToken(t *testing.T) {
+	env := func(values map[string]string) func(string) string {
+		return func(key string) string { return values[key] }
+	}
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenFile, []byte("file-token\n"), 0o600))
+
+	tests := []struct {
+		name       string
+		env        map[string]string
+		tokenFile  string
+		wantToken  string
+		wantSource string
+	}{
+		{"not configured", nil, "", "", ""},
+		{"GIC_GITHUB_TOKEN takes precedence", map[string]string{EnvGICGitHubToken: "gic-token", EnvGitHubToken: "env-token"}, tokenFile, "gic-token", "environment variable GIC_GITHUB_TOKEN"},
+		{"GITHUB_TOKEN", map[string]string{EnvGitHubToken: "env-token"}, tokenFile, "env-token", "environment variable GITHUB_TOKEN"},
+		{"token file", nil, tokenFile, "file-token", "token file " + tokenFile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := *config.NewConfig()
+			conf.GitHub.TokenFile = tt.tokenFile
+
+			token, source, err := LoadGitHubToken(conf, env(tt.env))
+			require.NoError(t, err)
+			assertEqualCmp(t, tt.wantToken, token)
+			assertEqualCmp(t, tt.wantSource, source)
+		})
+	}
+
+	t.Run("unreadable token file", func(t *testing.T) {
+		conf := *config.NewConfig()
+		conf.GitHub.TokenFile = filepath.Join(t.TempDir(), "missing")
+		_, _, err := LoadGitHubToken(conf, env(nil))
+		assert.ErrorContains(t, err, "read github.tokenFile")
+	})
+
+	t.Run("empty token file", func(t *testing.T) {
+		empty := filepath.Join(t.TempDir(), "token")
+		require.NoError(t, os.WriteFile(empty, []byte("\n"), 0o600))
+		conf := *config.NewConfig()
+		conf.GitHub.TokenFile = empty
+		_, _, err := LoadGitHubToken(conf, env(nil))
+		assert.ErrorContains(t, err, "is empty")
+	})
+}
+
+func TestLoadGitHubCLIToken(t *testing.T) {
+	dir := t.TempDir()
+	hostsPath := filepath.Join(dir, "hosts.yml")
+	require.NoError(t, os.WriteFile(hostsPath, []byte(`github.com:
+    oauth_token: gho_legacy
+    user: octocat
+    git_protocol: https
+ghe.example.com:
+    user: hubot
+    users:
+        hubot:
+            oauth_token: gho_enterprise
+keyring.example.com:
+    user: monalisa
+    users:
+        monalisa:
+`), 0o600))
+	getenv := func(key string) string {
+		if key == "GH_CONFIG_DIR" {
+			return dir
+		}
+		return ""
+	}
+
+	tests := []struct {
+		name      string
+		baseURL   string
+		wantToken string
+	}{
+		{"github.com", "", "gho_legacy"},
+		{"enterprise host with per-user tokens", "https://GHE.example.com/api/v3/", "gho_enterprise"},
+		{"token kept in the keyring", "https://keyring.example.com/api/v3/", ""},
+		{"not logged in", "https://other.example.com/api/v3/", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conf := *config.NewConfig()
+			conf.GitHub.BaseURL = tt.baseURL
+
+			token, source, err := LoadGitHubCLIToken(conf, getenv)
+			require.NoError(t, err)
+			assertEqualCmp(t, tt.wantToken, token)
+			if tt.wantToken != "" {
+				assertEqualCmp(t, "gh CLI config "+hostsPath, source)
+			}
+		})
+	}
+
+	t.Run("no gh config", func(t *testing.T) {
+		token, _, err := LoadGitHubCLIToken(*config.NewConfig(), func(key string) string {
+			if key == "GH_CONFIG_DIR" {
+				return t.TempDir()
+			}
+			return ""
+		})
+		require.NoError(t, err)
+		assert.Empty(t, token)
+	})
+}
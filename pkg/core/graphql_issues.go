@@ -0,0 +1,758 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+)
+
+const defaultGraphQLEndpoint = "https://api.github.com/graphql"
+
+// graphQLIssuePageSize is the number of issues requested per query. It is the
+// maximum allowed by the GitHub GraphQL API.
+const graphQLIssuePageSize = 100
+
+// graphQLIssueFields selects every issue field that ArticleService consumes.
+const graphQLIssueFields = `
+fragment IssueFields on Issue {
+  id
+  databaseId
+  number
+  title
+  body
+  state
+  stateReason
+  url
+  createdAt
+  updatedAt
+  closedAt
+  authorAssociation
+  author { login }
+  milestone { number title }
+  labels(first: 100) { nodes { name } }
+  assignees(first: 100) { nodes { login } }
+  comments { totalCount }
+  reactionGroups { content reactors { totalCount } }
+  repository { nameWithOwner }
+}
+`
+
+const graphQLListIssuesQuery = `
+query ListIssues($owner: String!, $name: String!, $first: Int!, $labels: [String!], $states: [IssueState!], $createdBy: String, $since: DateTime, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    issues(first: $first, after: $cursor, states: $states, filterBy: {labels: $labels, createdBy: $createdBy, since: $since}, orderBy: {field: CREATED_AT, direction: DESC}) {
+      pageInfo { hasNextPage endCursor }
+      nodes { ...IssueFields }
+    }
+  }
+  rateLimit { limit remaining resetAt }
+}
+` + graphQLIssueFields
+
+const graphQLGetIssueQuery = `
+query GetIssue($owner: String!, $name: String!, $number: Int!) {
+  repository(owner: $owner, name: $name) {
+    issue(number: $number) { ...IssueFields }
+  }
+}
+` + graphQLIssueFields
+
+const graphQLListCommentsQuery = `
+query ListComments($owner: String!, $name: String!, $number: Int!, $first: Int!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    issue(number: $number) {
+      comments(first: $first, after: $cursor) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          databaseId
+          body
+          url
+          createdAt
+          updatedAt
+          authorAssociation
+          author { login }
+        }
+      }
+    }
+  }
+}
+`
+
+const graphQLGetIssueHTMLQuery = `
+query GetIssueHTML($owner: String!, $name: String!, $number: Int!, $first: Int!, $cursor: String, $withComments: Boolean!) {
+  repository(owner: $owner, name: $name) {
+    issue(number: $number) {
+      bodyHTML
+      comments(first: $first, after: $cursor) @include(if: $withComments) {
+        pageInfo { hasNextPage endCursor }
+        nodes { bodyHTML }
+      }
+    }
+  }
+}
+`
+
+const graphQLListIssueEventsQuery = `
+query ListIssueEvents($owner: String!, $name: String!, $number: Int!, $first: Int!, $cursor: String) {
+  repository(owner: $owner, name: $name) {
+    issue(number: $number) {
+      timelineItems(first: $first, after: $cursor, itemTypes: [LABELED_EVENT, UNLABELED_EVENT, CLOSED_EVENT]) {
+        pageInfo { hasNextPage endCursor }
+        nodes {
+          __typename
+          ... on LabeledEvent { createdAt actor { login } label { name } }
+          ... on UnlabeledEvent { createdAt actor { login } label { name } }
+          ... on ClosedEvent { createdAt actor { login } }
+        }
+      }
+    }
+  }
+}
+`
+
+const graphQLGetPermissionQuery = `
+query GetPermission($owner: String!, $name: String!, $login: String!) {
+  repository(owner: $owner, name: $name) {
+    collaborators(query: $login, first: 100) {
+      edges { permission node { login } }
+    }
+  }
+}
+`
+
+// GitHubGraphQLIssueRepository retrieves issues via the GitHub GraphQL API.
+// It returns the same github.Issue values as GitHubIssueRepository so that
+// both backends produce identical articles.
+type GitHubGraphQLIssueRepository struct {
+	endpoint string
+	tokens   TokenSource
+	client   *http.Client
+	logger   *slog.Logger
+}
+
+// NewGitHubGraphQLIssueRepository creates a new GitHubGraphQLIssueRepository.
+func NewGitHubGraphQLIssueRepository(token string) (IssueStore, error) {
+	return NewGitHubGraphQLIssueRepositoryWithLogger(token, nil)
+}
+
+// NewGitHubGraphQLIssueRepositoryWithLogger creates a new GitHubGraphQLIssueRepository with an injected logger.
+func NewGitHubGraphQLIssueRepositoryWithLogger(token string, logger *slog.Logger) (IssueStore, error) {
+	if token == "" {
+		return nil, fmt.Errorf("GitHub token is required")
+	}
+	return newGitHubGraphQLIssueRepository(StaticTokenSource(token), "", DefaultRetryPolicy(), logger)
+}
+
+// NewGitHubEnterpriseGraphQLIssueRepositoryWithLogger creates a new
+// GitHubGraphQLIssueRepository for a GitHub Enterprise Server, whose GraphQL
+// endpoint is served at /api/graphql on the host of baseURL.
+func NewGitHubEnterpriseGraphQLIssueRepositoryWithLogger(token, baseURL string, logger *slog.Logger) (IssueStore, error) {
+	if token == "" {
+		return nil, fmt.Errorf("GitHub token is required")
+	}
+	return newGitHubGraphQLIssueRepository(StaticTokenSource(token), baseURL, DefaultRetryPolicy(), logger)
+}
+
+// newGitHubGraphQLIssueRepository creates a GitHubGraphQLIssueRepository that
+// authenticates every request with the current token of tokens and retries
+// failed requests according to retry. A non-empty baseURL selects a GitHub
+// Enterprise Server.
+func newGitHubGraphQLIssueRepository(tokens TokenSource, baseURL string, retry RetryPolicy, logger *slog.Logger) (*GitHubGraphQLIssueRepository, error) {
+	endpoint := defaultGraphQLEndpoint
+	if baseURL != "" {
+		parsed, err := url.Parse(baseURL)
+		if err != nil || parsed.Host == "" {
+			return nil, fmt.Errorf("invalid GitHub Enterprise base URL %q", baseURL)
+		}
+		endpoint = parsed.Scheme + "://" + parsed.Host + "/api/graphql"
+	}
+
+	return &GitHubGraphQLIssueRepository{
+		endpoint: endpoint,
+		tokens:   tokens,
+		client:   &http.Client{Transport: newRetryTransport(retry, http.DefaultTransport, defaultHTTPTimeout*time.Second, logger)},
+		logger:   defaultLogger(logger),
+	}, nil
+}
+
+// ListIssues retrieves all issues from the specified repository.
+func (r *GitHubGraphQLIssueRepository) ListIssues(ctx context.Context, query IssueListQuery) ([]*github.Issue, error) {
+	if query.Username == "" || query.Repository == "" {
+		return nil, fmt.Errorf("username and repository name are required")
+	}
+
+	r.logger.Debug("Collecting Issues...")
+	variables := map[string]any{
+		"owner": query.Username,
+		"name":  query.Repository,
+		"first": graphQLIssuePageSize,
+	}
+	// The GraphQL label filter matches issues with ANY of the labels, while
+	// the REST API requires ALL of them. Narrow the result server-side and
+	// apply the REST semantics below. Without required labels, the filter
+	// implements the any-of labels exactly.
+	if len(query.Labels) > 0 {
+		variables["labels"] = query.Labels
+	} else if len(query.Filter.AnyLabels) > 0 {
+		variables["labels"] = query.Filter.AnyLabels
+	}
+	if state := query.Filter.state(); state != config.IssueStateAll {
+		variables["states"] = []string{strings.ToUpper(state)}
+	}
+	if creator := query.Filter.creator(); creator != "" {
+		variables["createdBy"] = creator
+	}
+	if since := query.Filter.since(query.Since); !since.IsZero() {
+		variables["since"] = since.UTC().Format(time.RFC3339)
+	}
+
+	var (
+		issues []*github.Issue
+		rate   graphQLRateLimit
+	)
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var data struct {
+			Repository *struct {
+				Issues struct {
+					PageInfo struct {
+						HasNextPage bool   `json:"hasNextPage"`
+						EndCursor   string `json:"endCursor"`
+					} `json:"pageInfo"`
+					Nodes []*graphQLIssue `json:"nodes"`
+				} `json:"issues"`
+			} `json:"repository"`
+			RateLimit graphQLRateLimit `json:"rateLimit"`
+		}
+		if err := r.execute(ctx, graphQLListIssuesQuery, variables, &data); err != nil {
+			return nil, err
+		}
+		if data.Repository == nil {
+			return nil, fmt.Errorf("repository %s/%s not found", query.Username, query.Repository)
+		}
+
+		for _, node := range data.Repository.Issues.Nodes {
+			issue := node.toIssue(r.endpoint)
+			if issueMatchesQuery(issue, query) {
+				issues = append(issues, issue)
+			}
+		}
+		rate = data.RateLimit
+
+		pageInfo := data.Repository.Issues.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = pageInfo.EndCursor
+	}
+
+	r.logger.Debug("Collected issues", "count", len(issues))
+	r.logger.Debug("GitHub rate limit", "remaining", rate.Remaining, "limit", rate.Limit, "reset", rate.ResetAt)
+
+	return issues, nil
+}
+
+// GetIssue retrieves a single issue from the specified repository.
+func (r *GitHubGraphQLIssueRepository) GetIssue(ctx context.Context, username, repository string, number int) (*github.Issue, error) {
+	if username == "" || repository == "" {
+		return nil, fmt.Errorf("username and repository name are required")
+	}
+
+	var data struct {
+		Repository *struct {
+			Issue *graphQLIssue `json:"issue"`
+		} `json:"repository"`
+	}
+	variables := map[string]any{
+		"owner":  username,
+		"name":   repository,
+		"number": number,
+	}
+	if err := r.execute(ctx, graphQLGetIssueQuery, variables, &data); err != nil {
+		return nil, err
+	}
+	if data.Repository == nil {
+		return nil, repositoryNotFound(username, repository)
+	}
+	if data.Repository.Issue == nil {
+		return nil, fmt.Errorf("issue #%d: %w", number, ErrIssueNotFound)
+	}
+	return data.Repository.Issue.toIssue(r.endpoint), nil
+}
+
+// ListComments retrieves every comment of an issue in creation order.
+func (r *GitHubGraphQLIssueRepository) ListComments(ctx context.Context, username, repository string, number int) ([]*github.IssueComment, error) {
+	variables := map[string]any{
+		"owner":  username,
+		"name":   repository,
+		"number": number,
+		"first":  graphQLIssuePageSize,
+	}
+
+	var comments []*github.IssueComment
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var data struct {
+			Repository *struct {
+				Issue *struct {
+					Comments struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []*graphQLComment `json:"nodes"`
+					} `json:"comments"`
+				} `json:"issue"`
+			} `json:"repository"`
+		}
+		if err := r.execute(ctx, graphQLListCommentsQuery, variables, &data); err != nil {
+			return nil, err
+		}
+		if data.Repository == nil || data.Repository.Issue == nil {
+			return nil, fmt.Errorf("issue #%d: %w", number, ErrIssueNotFound)
+		}
+
+		for _, node := range data.Repository.Issue.Comments.Nodes {
+			comments = append(comments, node.toIssueComment())
+		}
+
+		pageInfo := data.Repository.Issue.Comments.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = pageInfo.EndCursor
+	}
+	return comments, nil
+}
+
+// ListIssueEvents retrieves the labeled, unlabeled and closed events of an
+// issue in chronological order. Closed events date articles by their first
+// close for output.articles.date: first_closed.
+func (r *GitHubGraphQLIssueRepository) ListIssueEvents(ctx context.Context, username, repository string, number int) ([]*github.IssueEvent, error) {
+	variables := map[string]any{
+		"owner":  username,
+		"name":   repository,
+		"number": number,
+		"first":  graphQLIssuePageSize,
+	}
+
+	var events []*github.IssueEvent
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		var data struct {
+			Repository *struct {
+				Issue *struct {
+					TimelineItems struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []*graphQLIssueEvent `json:"nodes"`
+					} `json:"timelineItems"`
+				} `json:"issue"`
+			} `json:"repository"`
+		}
+		if err := r.execute(ctx, graphQLListIssueEventsQuery, variables, &data); err != nil {
+			return nil, err
+		}
+		if data.Repository == nil || data.Repository.Issue == nil {
+			return nil, fmt.Errorf("issue #%d: %w", number, ErrIssueNotFound)
+		}
+
+		for _, node := range data.Repository.Issue.TimelineItems.Nodes {
+			events = append(events, node.toIssueEvent())
+		}
+
+		pageInfo := data.Repository.Issue.TimelineItems.PageInfo
+		if !pageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = pageInfo.EndCursor
+	}
+	return events, nil
+}
+
+// GetPermission retrieves the permission of user on the repository. Users
+// who are not collaborators have PermissionNone. Listing collaborators
+// requires push access to the repository.
+func (r *GitHubGraphQLIssueRepository) GetPermission(ctx context.Context, username, repository, user string) (string, error) {
+	var data struct {
+		Repository *struct {
+			Collaborators *struct {
+				Edges []struct {
+					Permission string `json:"permission"`
+					Node       struct {
+						Login string `json:"login"`
+					} `json:"node"`
+				} `json:"edges"`
+			} `json:"collaborators"`
+		} `json:"repository"`
+	}
+	variables := map[string]any{
+		"owner": username,
+		"name":  repository,
+		"login": user,
+	}
+	if err := r.execute(ctx, graphQLGetPermissionQuery, variables, &data); err != nil {
+		return "", err
+	}
+	if data.Repository == nil || data.Repository.Collaborators == nil {
+		return "", fmt.Errorf("repository %s/%s not found", username, repository)
+	}
+	// The query matches logins and names by prefix.
+	for _, edge := range data.Repository.Collaborators.Edges {
+		if !strings.EqualFold(edge.Node.Login, user) {
+			continue
+		}
+		switch edge.Permission {
+		case "ADMIN":
+			return PermissionAdmin, nil
+		case "MAINTAIN", "WRITE":
+			return PermissionWrite, nil
+		default:
+			return PermissionRead, nil
+		}
+	}
+	return PermissionNone, nil
+}
+
+// GetIssueHTML retrieves the rendered HTML of an issue body and, when
+// withComments is set, of its comments.
+func (r *GitHubGraphQLIssueRepository) GetIssueHTML(ctx context.Context, username, repository string, number int, withComments bool) (string, error) {
+	if username == "" || repository == "" {
+		return "", fmt.Errorf("username and repository name are required")
+	}
+
+	variables := map[string]any{
+		"owner":        username,
+		"name":         repository,
+		"number":       number,
+		"first":        graphQLIssuePageSize,
+		"withComments": withComments,
+	}
+
+	var parts []string
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+
+		var data struct {
+			Repository *struct {
+				Issue *struct {
+					BodyHTML string `json:"bodyHTML"`
+					Comments struct {
+						PageInfo struct {
+							HasNextPage bool   `json:"hasNextPage"`
+							EndCursor   string `json:"endCursor"`
+						} `json:"pageInfo"`
+						Nodes []struct {
+							BodyHTML string `json:"bodyHTML"`
+						} `json:"nodes"`
+					} `json:"comments"`
+				} `json:"issue"`
+			} `json:"repository"`
+		}
+		if err := r.execute(ctx, graphQLGetIssueHTMLQuery, variables, &data); err != nil {
+			return "", err
+		}
+		if data.Repository == nil || data.Repository.Issue == nil {
+			return "", fmt.Errorf("issue #%d: %w", number, ErrIssueNotFound)
+		}
+
+		issue := data.Repository.Issue
+		if parts == nil {
+			parts = append(parts, issue.BodyHTML)
+		}
+		for _, node := range issue.Comments.Nodes {
+			parts = append(parts, node.BodyHTML)
+		}
+		if !withComments || !issue.Comments.PageInfo.HasNextPage {
+			break
+		}
+		variables["cursor"] = issue.Comments.PageInfo.EndCursor
+	}
+	return strings.Join(parts, "\n"), nil
+}
+
+type graphQLRequest struct {
+	Query     string         `json:"query"`
+	Variables map[string]any `json:"variables"`
+}
+
+type graphQLResponse struct {
+	Data   json.RawMessage `json:"data"`
+	Errors []graphQLError  `json:"errors"`
+}
+
+type graphQLError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+type graphQLRateLimit struct {
+	Limit     int       `json:"limit"`
+	Remaining int       `json:"remaining"`
+	ResetAt   time.Time `json:"resetAt"`
+}
+
+// execute sends a GraphQL query and decodes the data field into out.
+func (r *GitHubGraphQLIssueRepository) execute(ctx context.Context, query string, variables map[string]any, out any) error {
+	payload, err := json.Marshal(graphQLRequest{Query: query, Variables: variables})
+	if err != nil {
+		return fmt.Errorf("encode GraphQL request: %w", err)
+	}
+
+	token, err := r.tokens.Token(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.endpoint, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+token)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("invalid API token; please check your GitHub token")
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GraphQL request failed: status=%d", resp.StatusCode)
+	}
+
+	var body graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return fmt.Errorf("decode GraphQL response: %w", err)
+	}
+	if len(body.Errors) > 0 {
+		// A missing issue is reported as an error next to partial data.
+		// Let the caller decide based on the null field.
+		if !allGraphQLErrorsAre(body.Errors, "NOT_FOUND") {
+			return fmt.Errorf("GraphQL request failed: %s", body.Errors[0].Message)
+		}
+	}
+	if len(body.Data) == 0 || string(body.Data) == "null" {
+		return fmt.Errorf("GraphQL response contains no data")
+	}
+	if err := json.Unmarshal(body.Data, out); err != nil {
+		return fmt.Errorf("decode GraphQL data: %w", err)
+	}
+	return nil
+}
+
+func allGraphQLErrorsAre(errs []graphQLError, errorType string) bool {
+	for _, err := range errs {
+		if err.Type != errorType {
+			return false
+		}
+	}
+	return true
+}
+
+// graphQLIssue mirrors the IssueFields fragment.
+type graphQLIssue struct {
+	ID                string     `json:"id"`
+	DatabaseID        int64      `json:"databaseId"`
+	Number            int        `json:"number"`
+	Title             string     `json:"title"`
+	Body              string     `json:"body"`
+	State             string     `json:"state"`
+	StateReason       *string    `json:"stateReason"`
+	URL               string     `json:"url"`
+	CreatedAt         time.Time  `json:"createdAt"`
+	UpdatedAt         time.Time  `json:"updatedAt"`
+	ClosedAt          *time.Time `json:"closedAt"`
+	AuthorAssociation string     `json:"authorAssociation"`
+	Author            *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+	Milestone *struct {
+		Number int    `json:"number"`
+		Title  string `json:"title"`
+	} `json:"milestone"`
+	Labels struct {
+		Nodes []struct {
+			Name string `json:"name"`
+		} `json:"nodes"`
+	} `json:"labels"`
+	Assignees struct {
+		Nodes []struct {
+			Login string `json:"login"`
+		} `json:"nodes"`
+	} `json:"assignees"`
+	Comments struct {
+		TotalCount int `json:"totalCount"`
+	} `json:"comments"`
+	ReactionGroups []struct {
+		Content  string `json:"content"`
+		Reactors struct {
+			TotalCount int `json:"totalCount"`
+		} `json:"reactors"`
+	} `json:"reactionGroups"`
+	Repository struct {
+		NameWithOwner string `json:"nameWithOwner"`
+	} `json:"repository"`
+}
+
+// toIssue maps the GraphQL representation onto the REST type. Enum values
+// are lower-cased to match the REST API.
+func (n *graphQLIssue) toIssue(endpoint string) *github.Issue {
+	issue := &github.Issue{
+		ID:                github.Ptr(n.DatabaseID),
+		NodeID:            github.Ptr(n.ID),
+		Number:            github.Ptr(n.Number),
+		Title:             github.Ptr(n.Title),
+		Body:              github.Ptr(n.Body),
+		State:             github.Ptr(strings.ToLower(n.State)),
+		HTMLURL:           github.Ptr(n.URL),
+		CreatedAt:         &github.Timestamp{Time: n.CreatedAt},
+		UpdatedAt:         &github.Timestamp{Time: n.UpdatedAt},
+		AuthorAssociation: github.Ptr(n.AuthorAssociation),
+		Comments:          github.Ptr(n.Comments.TotalCount),
+	}
+	if n.StateReason != nil {
+		issue.StateReason = github.Ptr(strings.ToLower(*n.StateReason))
+	}
+	if n.ClosedAt != nil {
+		issue.ClosedAt = &github.Timestamp{Time: *n.ClosedAt}
+	}
+	if n.Author != nil {
+		issue.User = &github.User{Login: github.Ptr(n.Author.Login)}
+	}
+	if n.Milestone != nil {
+		issue.Milestone = &github.Milestone{Number: github.Ptr(n.Milestone.Number), Title: github.Ptr(n.Milestone.Title)}
+	}
+	// The REST API always returns these lists, even when they are empty.
+	issue.Labels = make([]*github.Label, 0, len(n.Labels.Nodes))
+	for _, label := range n.Labels.Nodes {
+		issue.Labels = append(issue.Labels, &github.Label{Name: github.Ptr(label.Name)})
+	}
+	issue.Assignees = make([]*github.User, 0, len(n.Assignees.Nodes))
+	for _, assignee := range n.Assignees.Nodes {
+		issue.Assignees = append(issue.Assignees, &github.User{Login: github.Ptr(assignee.Login)})
+	}
+	if n.ReactionGroups != nil {
+		issue.Reactions = &github.Reactions{}
+		total := 0
+		for _, group := range n.ReactionGroups {
+			count := group.Reactors.TotalCount
+			total += count
+			switch group.Content {
+			case "THUMBS_UP":
+				issue.Reactions.PlusOne = github.Ptr(count)
+			case "THUMBS_DOWN":
+				issue.Reactions.MinusOne = github.Ptr(count)
+			case "LAUGH":
+				issue.Reactions.Laugh = github.Ptr(count)
+			case "CONFUSED":
+				issue.Reactions.Confused = github.Ptr(count)
+			case "HEART":
+				issue.Reactions.Heart = github.Ptr(count)
+			case "HOORAY":
+				issue.Reactions.Hooray = github.Ptr(count)
+			case "ROCKET":
+				issue.Reactions.Rocket = github.Ptr(count)
+			case "EYES":
+				issue.Reactions.Eyes = github.Ptr(count)
+			}
+		}
+		issue.Reactions.TotalCount = github.Ptr(total)
+	}
+	if n.Repository.NameWithOwner != "" {
+		issue.RepositoryURL = github.Ptr(graphQLRESTBase(endpoint) + "repos/" + n.Repository.NameWithOwner)
+	}
+	return issue
+}
+
+// graphQLComment mirrors the comment fields of the ListComments query.
+type graphQLComment struct {
+	DatabaseID        int64     `json:"databaseId"`
+	Body              string    `json:"body"`
+	URL               string    `json:"url"`
+	CreatedAt         time.Time `json:"createdAt"`
+	UpdatedAt         time.Time `json:"updatedAt"`
+	AuthorAssociation string    `json:"authorAssociation"`
+	Author            *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+func (n *graphQLComment) toIssueComment() *github.IssueComment {
+	comment := &github.IssueComment{
+		ID:                github.Ptr(n.DatabaseID),
+		Body:              github.Ptr(n.Body),
+		HTMLURL:           github.Ptr(n.URL),
+		CreatedAt:         &github.Timestamp{Time: n.CreatedAt},
+		UpdatedAt:         &github.Timestamp{Time: n.UpdatedAt},
+		AuthorAssociation: github.Ptr(n.AuthorAssociation),
+	}
+	if n.Author != nil {
+		comment.User = &github.User{Login: github.Ptr(n.Author.Login)}
+	}
+	return comment
+}
+
+// graphQLIssueEvent mirrors the nodes of the ListIssueEvents query.
+type graphQLIssueEvent struct {
+	Typename  string    `json:"__typename"`
+	CreatedAt time.Time `json:"createdAt"`
+	Actor     *struct {
+		Login string `json:"login"`
+	} `json:"actor"`
+	Label *struct {
+		Name string `json:"name"`
+	} `json:"label"`
+}
+
+// toIssueEvent maps the event onto the REST type, whose event names are
+// "labeled", "unlabeled" and "closed".
+func (n *graphQLIssueEvent) toIssueEvent() *github.IssueEvent {
+	event := &github.IssueEvent{
+		Event:     github.Ptr(strings.ToLower(strings.TrimSuffix(n.Typename, "Event"))),
+		CreatedAt: &github.Timestamp{Time: n.CreatedAt},
+	}
+	if n.Label != nil {
+		event.Label = &github.Label{Name: github.Ptr(n.Label.Name)}
+	}
+	if n.Actor != nil {
+		event.Actor = &github.User{Login: github.Ptr(n.Actor.Login)}
+	}
+	return event
+}
+
+// graphQLRESTBase derives the REST API base URL from a GraphQL endpoint:
+// https://api.github.com/graphql becomes https://api.github.com/ and
+// https://HOST/api/graphql becomes https://HOST/api/v3/.
+func graphQLRESTBase(endpoint string) string {
+	base := strings.TrimSuffix(endpoint, "graphql")
+	if strings.HasSuffix(base, "/api/") {
+		return base + "v3/"
+	}
+	return base
+}
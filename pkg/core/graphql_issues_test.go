@@ -0,0 +1,376 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newGraphQLStandIn serves recorded GraphQL responses from testdata/graphql.
+// ListIssues pages are selected by cursor and GetIssue responses by number.
+func newGraphQLStandIn(t *testing.T, requests *[]graphQLRequest) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "bearer test-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var req graphQLRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		if requests != nil {
+			*requests = append(*requests, req)
+		}
+
+		var fixture string
+		switch {
+		case strings.Contains(req.Query, "query ListIssues"):
+			fixture = "list_issues_page1.json"
+			if req.Variables["cursor"] == "Y3Vyc29yOjI=" {
+				fixture = "list_issues_page2.json"
+			}
+		case strings.Contains(req.Query, "query GetIssueHTML"):
+			fixture = "get_issue_html.json"
+		case strings.Contains(req.Query, "query GetIssue"):
+			fixture = "get_issue_not_found.json"
+			if req.Variables["number"] == float64(3) {
+				fixture = "get_issue.json"
+			}
+			if req.Variables["name"] == "private" {
+				fixture = "get_issue_repository_not_found.json"
+			}
+		case strings.Contains(req.Query, "query ListComments"):
+			fixture = "list_comments.json"
+		case strings.Contains(req.Query, "query ListIssueEvents"):
+			fixture = "list_issue_events.json"
+		case strings.Contains(req.Query, "query GetPermission"):
+			fixture = "get_permission.json"
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		data, err := os.ReadFile(filepath.Join("testdata", "graphql", fixture))
+		if err != nil {
+			t.Errorf("read fixture: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+}
+
+func newTestGraphQLRepository(server *httptest.Server) *GitHubGraphQLIssueRepository {
+	return &GitHubGraphQLIssueRepository{
+		endpoint: server.URL + "/graphql",
+		tokens:   StaticTokenSource("test-token"),
+		client:   server.Client(),
+		logger:   slog.Default(),
+	}
+}
+
+func TestNewGitHubGraphQLIssueRepository(t *testing.T) {
+	repo, err := NewGitHubGraphQLIssueRepository("valid-token")
+	assert.NoError(t, err)
+	assert.NotNil(t, repo)
+
+	repo, err = NewGitHubGraphQLIssueRepository("")
+	assert.Error(t, err)
+	assert.Nil(t, repo)
+}
+
+func TestGitHubGraphQLIssueRepository_ListIssues(t *testing.T) {
+	var requests []graphQLRequest
+	server := newGraphQLStandIn(t, &requests)
+	defer server.Close()
+	repo := newTestGraphQLRepository(server)
+
+	issues, err := repo.ListIssues(context.Background(), IssueListQuery{
+		Username:   "testuser",
+		Repository: "testrepo",
+		Labels:     []string{"published", "blog"},
+	})
+	require.NoError(t, err)
+
+	// Issue #2 only carries one of the labels and is dropped like the REST
+	// API would.
+	require.Len(t, issues, 2)
+	assert.Equal(t, 3, issues[0].GetNumber())
+	assert.Equal(t, 1, issues[1].GetNumber())
+
+	issue := issues[0]
+	assert.Equal(t, int64(1003), issue.GetID())
+	assert.Equal(t, "closed", issue.GetState())
+	assert.Equal(t, "completed", issue.GetStateReason())
+	assert.Equal(t, "testuser", issue.GetUser().GetLogin())
+	assert.Equal(t, "Diary", issue.GetMilestone().GetTitle())
+	assert.Equal(t, "OWNER", issue.GetAuthorAssociation())
+	assert.Equal(t, 2, issue.GetComments())
+	assert.Equal(t, server.URL+"/repos/testuser/testrepo", issue.GetRepositoryURL())
+	assertEqualCmp(t, []string{"published", "blog"}, []string{issue.Labels[0].GetName(), issue.Labels[1].GetName()})
+
+	assert.Equal(t, "open", issues[1].GetState())
+	assert.Nil(t, issues[1].User)
+	assert.Nil(t, issues[1].ClosedAt)
+
+	require.Len(t, requests, 2)
+	assert.Equal(t, "testuser", requests[0].Variables["owner"])
+	assertEqualCmp(t, []any{"published", "blog"}, requests[0].Variables["labels"])
+	assert.NotContains(t, requests[0].Variables, "since")
+	assert.NotContains(t, requests[0].Variables, "cursor")
+	assert.Equal(t, "Y3Vyc29yOjI=", requests[1].Variables["cursor"])
+}
+
+func TestGitHubGraphQLIssueRepository_ListIssues_WithSince(t *testing.T) {
+	var requests []graphQLRequest
+	server := newGraphQLStandIn(t, &requests)
+	defer server.Close()
+	repo := newTestGraphQLRepository(server)
+
+	_, err := repo.ListIssues(context.Background(), IssueListQuery{
+		Username:   "testuser",
+		Repository: "testrepo",
+		Since:      parseTime("2024-03-01T00:00:00Z").Time,
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, requests)
+	assert.Equal(t, "2024-03-01T00:00:00Z", requests[0].Variables["since"])
+}
+
+func TestGitHubGraphQLIssueRepository_ListIssues_WithFilter(t *testing.T) {
+	var requests []graphQLRequest
+	server := newGraphQLStandIn(t, &requests)
+	defer server.Close()
+	repo := newTestGraphQLRepository(server)
+
+	issues, err := repo.ListIssues(context.Background(), IssueListQuery{
+		Username:   "testuser",
+		Repository: "testrepo",
+		Filter: IssueFilter{
+			State:        "closed",
+			AnyLabels:    []string{"published", "news"},
+			Authors:      []string{"testuser"},
+			Milestone:    "Diary",
+			CreatedAfter: parseTime("2020-01-01T00:00:00Z").Time,
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, 3, issues[0].GetNumber())
+
+	require.NotEmpty(t, requests)
+	assertEqualCmp(t, []any{"CLOSED"}, requests[0].Variables["states"])
+	assertEqualCmp(t, []any{"published", "news"}, requests[0].Variables["labels"])
+	assert.Equal(t, "testuser", requests[0].Variables["createdBy"])
+	assert.Equal(t, "2020-01-01T00:00:00Z", requests[0].Variables["since"])
+}
+
+func TestGitHubGraphQLIssueRepository_ListIssues_Errors(t *testing.T) {
+	server := newGraphQLStandIn(t, nil)
+	defer server.Close()
+
+	t.Run("invalid token", func(t *testing.T) {
+		repo := newTestGraphQLRepository(server)
+		repo.tokens = StaticTokenSource("wrong-token")
+
+		_, err := repo.ListIssues(context.Background(), IssueListQuery{Username: "testuser", Repository: "testrepo"})
+		assert.ErrorContains(t, err, "invalid API token")
+	})
+
+	t.Run("missing repository", func(t *testing.T) {
+		repo := newTestGraphQLRepository(server)
+
+		_, err := repo.ListIssues(context.Background(), IssueListQuery{Repository: "testrepo"})
+		assert.ErrorContains(t, err, "username and repository name are required")
+	})
+
+	t.Run("GraphQL error", func(t *testing.T) {
+		errServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(`{"data": null, "errors": [{"type": "RATE_LIMITED", "message": "API rate limit exceeded"}]}`))
+		}))
+		defer errServer.Close()
+		repo := newTestGraphQLRepository(errServer)
+
+		_, err := repo.ListIssues(context.Background(), IssueListQuery{Username: "testuser", Repository: "testrepo"})
+		assert.ErrorContains(t, err, "API rate limit exceeded")
+	})
+}
+
+func TestGitHubGraphQLIssueRepository_GetIssue(t *testing.T) {
+	server := newGraphQLStandIn(t, nil)
+	defer server.Close()
+	repo := newTestGraphQLRepository(server)
+
+	issue, err := repo.GetIssue(context.Background(), "testuser", "testrepo", 3)
+	require.NoError(t, err)
+	assert.Equal(t, "Hello GraphQL", issue.GetTitle())
+
+	_, err = repo.GetIssue(context.Background(), "testuser", "testrepo", 99)
+	assert.ErrorIs(t, err, ErrIssueNotFound)
+
+	_, err = repo.GetIssue(context.Background(), "testuser", "private", 3)
+	assert.ErrorIs(t, err, ErrRepositoryNotFound)
+	assert.NotErrorIs(t, err, ErrIssueNotFound)
+
+	_, err = repo.GetIssue(context.Background(), "", "testrepo", 3)
+	assert.ErrorContains(t, err, "username and repository name are required")
+}
+
+func TestGitHubGraphQLIssueRepository_ListComments(t *testing.T) {
+	server := newGraphQLStandIn(t, nil)
+	defer server.Close()
+	repo := newTestGraphQLRepository(server)
+
+	comments, err := repo.ListComments(context.Background(), "testuser", "testrepo", 3)
+	require.NoError(t, err)
+	require.Len(t, comments, 2)
+	assert.Equal(t, int64(5001), comments[0].GetID())
+	assert.Equal(t, "testuser", comments[0].GetUser().GetLogin())
+	assert.Equal(t, "reader", comments[1].GetUser().GetLogin())
+	assert.Equal(t, "Nice post!", comments[1].GetBody())
+}
+
+func TestGitHubGraphQLIssueRepository_ListIssueEvents(t *testing.T) {
+	server := newGraphQLStandIn(t, nil)
+	defer server.Close()
+	repo := newTestGraphQLRepository(server)
+
+	events, err := repo.ListIssueEvents(context.Background(), "testuser", "testrepo", 3)
+	require.NoError(t, err)
+	require.Len(t, events, 3)
+	assert.Equal(t, "labeled", events[0].GetEvent())
+	assert.Equal(t, "testuser", events[0].GetActor().GetLogin())
+	assert.Equal(t, "approved", events[0].GetLabel().GetName())
+	assert.Equal(t, "unlabeled", events[1].GetEvent())
+	assert.Nil(t, events[1].Actor)
+	assert.Equal(t, "closed", events[2].GetEvent())
+	assert.Nil(t, events[2].Label)
+	assert.Equal(t, "testuser", lastLabelActor(events[:1], "approved"))
+}
+
+func TestGitHubGraphQLIssueRepository_GetPermission(t *testing.T) {
+	server := newGraphQLStandIn(t, nil)
+	defer server.Close()
+	repo := newTestGraphQLRepository(server)
+
+	for user, want := range map[string]string{
+		"testuser":       PermissionAdmin,
+		"Maintainer":     PermissionWrite,
+		"maintainer-bot": PermissionRead,
+		"stranger":       PermissionNone,
+	} {
+		got, err := repo.GetPermission(context.Background(), "testuser", "testrepo", user)
+		require.NoError(t, err)
+		assert.Equal(t, want, got, user)
+	}
+}
+
+func TestGitHubGraphQLIssueRepository_GetIssueHTML(t *testing.T) {
+	var requests []graphQLRequest
+	server := newGraphQLStandIn(t, &requests)
+	defer server.Close()
+	repo := newTestGraphQLRepository(server)
+
+	html, err := repo.GetIssueHTML(context.Background(), "testuser", "testrepo", 3, true)
+	require.NoError(t, err)
+	assertEqualCmp(t, `<p><img src="https://private-user-images.githubusercontent.com/1/2-abc.png?jwt=token" alt="image"></p>`+"\n<p>First!</p>", html)
+	require.Len(t, requests, 1)
+	assert.Equal(t, true, requests[0].Variables["withComments"])
+}
+
+// TestGitHubGraphQLIssueRepository_MatchesREST checks that both backends
+// produce the same issues and articles for the same recorded data.
+func TestGitHubGraphQLIssueRepository_MatchesREST(t *testing.T) {
+	restServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := os.ReadFile(filepath.Join("testdata", "graphql", "list_issues_rest.json"))
+		if err != nil {
+			t.Errorf("read fixture: %v", err)
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(data)
+	}))
+	defer restServer.Close()
+	client, err := github.NewClient(nil).WithEnterpriseURLs(restServer.URL, restServer.URL)
+	require.NoError(t, err)
+	restRepo := &GitHubIssueRepository{client: client, logger: slog.Default()}
+
+	graphQLServer := newGraphQLStandIn(t, nil)
+	defer graphQLServer.Close()
+	graphQLRepo := newTestGraphQLRepository(graphQLServer)
+
+	query := IssueListQuery{Username: "testuser", Repository: "testrepo", Labels: []string{"published", "blog"}}
+	restIssues, err := restRepo.ListIssues(context.Background(), query)
+	require.NoError(t, err)
+	graphQLIssues, err := graphQLRepo.ListIssues(context.Background(), query)
+	require.NoError(t, err)
+
+	// The repository URL points at the API host, which differs between the
+	// two stand-ins.
+	assertEqualCmp(t, restIssues, graphQLIssues, cmpopts.IgnoreFields(github.Issue{}, "RepositoryURL"))
+
+	conf := *config.NewConfig()
+	conf.GitHub.Labels = query.Labels
+	service := NewArticleService(conf)
+	renderer := NewHugoArticleRenderer()
+	for i := range restIssues {
+		want, err := renderer.Render(service.ConvertIssueToArticle(restIssues[i]))
+		require.NoError(t, err)
+		got, err := renderer.Render(service.ConvertIssueToArticle(graphQLIssues[i]))
+		require.NoError(t, err)
+		assert.Equal(t, want, got)
+	}
+}
+
+func TestNewIssueStore(t *testing.T) {
+	conf := *config.NewConfig()
+
+	store, err := newIssueStore(conf, StaticTokenSource("token"), nil)
+	require.NoError(t, err)
+	assert.IsType(t, &GitHubIssueRepository{}, store)
+
+	conf.GitHub.API = config.GitHubAPIGraphQL
+	store, err = newIssueStore(conf, StaticTokenSource("token"), nil)
+	require.NoError(t, err)
+	assert.IsType(t, &GitHubGraphQLIssueRepository{}, store)
+
+	conf.GitHub.BaseURL = "https://ghe.example.com/api/v3/"
+	store, err = newIssueStore(conf, StaticTokenSource("token"), nil)
+	require.NoError(t, err)
+	assertEqualCmp(t, "https://ghe.example.com/api/graphql", store.(*GitHubGraphQLIssueRepository).endpoint)
+
+	conf.GitHub.API = config.GitHubAPIREST
+	store, err = newIssueStore(conf, StaticTokenSource("token"), nil)
+	require.NoError(t, err)
+	assertEqualCmp(t, "https://ghe.example.com/api/v3/", store.(*GitHubIssueRepository).client.BaseURL.String())
+
+	conf.GitHub.API = "soap"
+	_, err = newIssueStore(conf, StaticTokenSource("token"), nil)
+	assert.Error(t, err)
+}
+
+func TestGraphQLRESTBase(t *testing.T) {
+	assert.Equal(t, "https://api.github.com/", graphQLRESTBase("https://api.github.com/graphql"))
+	assert.Equal(t, "https://ghe.example.com/api/v3/", graphQLRESTBase("https://ghe.example.com/api/graphql"))
+}
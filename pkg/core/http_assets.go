@@ -9,18 +9,31 @@ import (
 	"mime"
 	"net/http"
 	"net/url"
+	"path"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/rokuosan/github-issue-cms/pkg/config"
 )
 
 const defaultHTTPTimeout = 30
 const maxRedirects = 10
 
+// defaultTrustedImageHosts are the hosts the GitHub token is sent to when
+// downloading images. Patterns use path.Match syntax.
+var defaultTrustedImageHosts = []string{
+	"github.com",
+	"*.github.com",
+	"*.githubusercontent.com",
+}
+
 // HTTPImageRepository downloads images over HTTP.
 type HTTPImageRepository struct {
-	token  string
-	logger *slog.Logger
-	client *http.Client
+	tokens       TokenSource
+	trustedHosts []string
+	logger       *slog.Logger
+	client       *http.Client
 }
 
 // NewHTTPImageRepository creates a new HTTPImageRepository.
@@ -30,15 +43,46 @@ func NewHTTPImageRepository(token string) AssetFetcher {
 
 // NewHTTPImageRepositoryWithLogger creates a new HTTPImageRepository with an injected logger.
 func NewHTTPImageRepositoryWithLogger(token string, logger *slog.Logger) AssetFetcher {
+	var tokens TokenSource
+	if token != "" {
+		tokens = StaticTokenSource(token)
+	}
+	return newHTTPImageRepository(tokens, defaultTrustedImageHosts, DefaultRetryPolicy(), logger)
+}
+
+// newHTTPImageRepository creates an HTTPImageRepository that sends the current
+// token of tokens to trusted hosts and retries failed downloads according to
+// retry. A nil tokens disables authentication.
+func newHTTPImageRepository(tokens TokenSource, trustedHosts []string, retry RetryPolicy, logger *slog.Logger) *HTTPImageRepository {
 	return &HTTPImageRepository{
-		token:  token,
-		logger: defaultLogger(logger),
-		client: &http.Client{Timeout: defaultHTTPTimeout * time.Second},
+		tokens:       tokens,
+		trustedHosts: trustedHosts,
+		logger:       defaultLogger(logger),
+		client:       &http.Client{Transport: newRetryTransport(retry, http.DefaultTransport, defaultHTTPTimeout*time.Second, logger)},
 	}
 }
 
+// trustedImageHosts returns the default trusted hosts extended with the host
+// of a configured GitHub Enterprise Server and its media subdomain.
+func trustedImageHosts(conf config.Config) []string {
+	host := conf.GitHub.EnterpriseHost()
+	if host == "" {
+		return defaultTrustedImageHosts
+	}
+	return slices.Concat(defaultTrustedImageHosts, []string{host, "media." + host})
+}
+
 // Fetch retrieves an image stream over HTTP.
 func (r *HTTPImageRepository) Fetch(ctx context.Context, image *Image) (*ImageAsset, error) {
+	if image.DownloadURL != "" {
+		// Signed URLs carry their own credentials, so no token is sent.
+		body, contentType, err := r.sendRequest(ctx, image.DownloadURL, "")
+		if err == nil {
+			return &ImageAsset{Body: body, ContentType: contentType}, nil
+		}
+		r.logger.Warn("signed image download failed; downloading from the original URL", "url", image.URL, "error", err)
+	}
+
 	body, contentType, err := r.downloadImage(ctx, image.URL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to download image from %s: %w", image.URL, err)
@@ -52,14 +96,18 @@ func (r *HTTPImageRepository) Fetch(ctx context.Context, image *Image) (*ImageAs
 
 // downloadImage downloads an image over HTTP.
 func (r *HTTPImageRepository) downloadImage(ctx context.Context, imageURL string) (io.ReadCloser, string, error) {
-	// Only send the token over HTTPS to prevent leaking credentials.
-	if r.token != "" && isHTTPS(imageURL) {
-		if body, contentType, err := r.sendRequest(ctx, imageURL, true); err == nil {
+	// Only send the token over HTTPS to trusted hosts to prevent leaking credentials.
+	if r.tokens != nil && isHTTPS(imageURL) && r.isTrustedHost(imageURL) {
+		token, err := r.tokens.Token(ctx)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to obtain token: %w", err)
+		}
+		if body, contentType, err := r.sendRequest(ctx, imageURL, token); err == nil {
 			return body, contentType, nil
 		} else {
 			r.logger.Warn("authenticated image download failed; retrying without token", "url", imageURL, "error", err)
 
-			body, contentType, fallbackErr := r.sendRequest(ctx, imageURL, false)
+			body, contentType, fallbackErr := r.sendRequest(ctx, imageURL, "")
 			if fallbackErr == nil {
 				return body, contentType, nil
 			}
@@ -70,20 +118,20 @@ func (r *HTTPImageRepository) downloadImage(ctx context.Context, imageURL string
 		}
 	}
 
-	// No token was configured or the URL is not HTTPS — only an unauthenticated request is possible.
-	return r.sendRequest(ctx, imageURL, false)
+	// No token was configured or the URL is not HTTPS on a trusted host — only an unauthenticated request is possible.
+	return r.sendRequest(ctx, imageURL, "")
 }
 
-// sendRequest sends an HTTP request.
-func (r *HTTPImageRepository) sendRequest(ctx context.Context, url string, includeToken bool) (io.ReadCloser, string, error) {
+// sendRequest sends an HTTP request, authenticated when token is not empty.
+func (r *HTTPImageRepository) sendRequest(ctx context.Context, url string, token string) (io.ReadCloser, string, error) {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
 	if err != nil {
 		return nil, "", err
 	}
 
 	client := r.client
-	if includeToken && r.token != "" {
-		req.Header.Set("Authorization", "token "+r.token)
+	if token != "" {
+		req.Header.Set("Authorization", "token "+token)
 		// Prevent the token from leaking to a redirect destination.
 		client = r.clientWithRedirectGuard()
 	}
@@ -120,6 +168,23 @@ func (r *HTTPImageRepository) clientWithRedirectGuard() *http.Client {
 	return &c
 }
 
+// isTrustedHost reports whether the URL's host matches a trusted host.
+func (r *HTTPImageRepository) isTrustedHost(rawURL string) bool {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return false
+	}
+	for _, pattern := range r.trustedHosts {
+		if strings.EqualFold(pattern, parsed.Host) {
+			return true
+		}
+		if matched, err := path.Match(strings.ToLower(pattern), strings.ToLower(parsed.Hostname())); err == nil && matched {
+			return true
+		}
+	}
+	return false
+}
+
 func isHTTPS(rawURL string) bool {
 	parsed, err := url.Parse(rawURL)
 	if err != nil {
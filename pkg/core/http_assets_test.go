@@ -9,7 +9,9 @@ import (
 	"net/http/httptest"
 	"testing"
 
+	"github.com/rokuosan/github-issue-cms/pkg/config"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewHTTPImageRepository(t *testing.T) {
@@ -116,6 +118,7 @@ func TestHTTPImageRepository_Download(t *testing.T) {
 
 		repo := NewHTTPImageRepository("test-token").(*HTTPImageRepository)
 		repo.client = server.Client()
+		repo.trustedHosts = []string{"127.0.0.1"}
 		image := &Image{
 			URL:  server.URL,
 			Time: "2021-01-01_000000",
@@ -162,6 +165,7 @@ func TestHTTPImageRepository_Download(t *testing.T) {
 
 		repo := NewHTTPImageRepository("test-token").(*HTTPImageRepository)
 		repo.client = httpsServer.Client()
+		repo.trustedHosts = []string{"127.0.0.1"}
 		asset, err := repo.Fetch(context.Background(), NewImage(httpsServer.URL, "2021-01-01_000000", 0))
 		assert.NoError(t, err)
 		defer asset.Body.Close()
@@ -186,6 +190,7 @@ func TestHTTPImageRepository_Download(t *testing.T) {
 
 		repo := NewHTTPImageRepository("test-token").(*HTTPImageRepository)
 		repo.client = server.Client()
+		repo.trustedHosts = []string{"127.0.0.1"}
 		asset, err := repo.Fetch(context.Background(), NewImage(server.URL+"/redirect", "2021-01-01_000000", 0))
 		assert.NoError(t, err)
 		defer asset.Body.Close()
@@ -204,6 +209,7 @@ func TestHTTPImageRepository_Fetch_ReportsAuthenticatedAndFallbackFailures(t *te
 
 	repo := NewHTTPImageRepositoryWithLogger("test-token", logger).(*HTTPImageRepository)
 	repo.client = server.Client()
+	repo.trustedHosts = []string{"127.0.0.1"}
 	_, err := repo.Fetch(context.Background(), NewImage(server.URL, "2021-01-01_000000", 0))
 
 	assert.Error(t, err)
@@ -213,6 +219,39 @@ func TestHTTPImageRepository_Fetch_ReportsAuthenticatedAndFallbackFailures(t *te
 	assert.NotContains(t, logs.String(), "test-token")
 }
 
+func TestHTTPImageRepository_Fetch_PrefersDownloadURL(t *testing.T) {
+	var requests []string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.Path+" "+r.Header.Get("Authorization"))
+		if r.URL.Path == "/expired.png" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("PNG"))
+	}))
+	defer server.Close()
+
+	repo := NewHTTPImageRepository("test-token").(*HTTPImageRepository)
+	repo.client = server.Client()
+	repo.trustedHosts = []string{"127.0.0.1"}
+
+	image := NewImage(server.URL+"/original.png", "2021-01-01_000000", 0)
+	image.DownloadURL = server.URL + "/signed.png?jwt=abc"
+	asset, err := repo.Fetch(context.Background(), image)
+	require.NoError(t, err)
+	asset.Body.Close()
+	// Signed URLs are fetched without the token.
+	assertEqualCmp(t, []string{"/signed.png "}, requests)
+
+	requests = nil
+	image.DownloadURL = server.URL + "/expired.png"
+	asset, err = repo.Fetch(context.Background(), image)
+	require.NoError(t, err)
+	asset.Body.Close()
+	assertEqualCmp(t, []string{"/expired.png ", "/original.png token test-token"}, requests)
+}
+
 func TestHTTPImageRepository_Download_InvalidURL(t *testing.T) {
 	repo := NewHTTPImageRepository("")
 
@@ -225,3 +264,44 @@ func TestHTTPImageRepository_Download_InvalidURL(t *testing.T) {
 	_, err := repo.Fetch(context.Background(), image)
 	assert.Error(t, err)
 }
+
+func TestHTTPImageRepository_Download_OnlySendsTokenToTrustedHosts(t *testing.T) {
+	var authHeader string
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authHeader = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "image/png")
+		_, _ = w.Write([]byte("PNG"))
+	}))
+	defer server.Close()
+
+	repo := NewHTTPImageRepository("test-token").(*HTTPImageRepository)
+	repo.client = server.Client()
+	asset, err := repo.Fetch(context.Background(), NewImage(server.URL, "2021-01-01_000000", 0))
+	require.NoError(t, err)
+	defer asset.Body.Close()
+	assert.Empty(t, authHeader)
+}
+
+func TestHTTPImageRepository_IsTrustedHost(t *testing.T) {
+	conf := *config.NewConfig()
+	conf.GitHub.BaseURL = "https://ghe.example.com/api/v3/"
+	repo := newHTTPImageRepository(StaticTokenSource("test-token"), trustedImageHosts(conf), DefaultRetryPolicy(), nil)
+
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://github.com/user-attachments/assets/1", true},
+		{"https://private-user-images.githubusercontent.com/1.png", true},
+		{"https://ghe.example.com/storage/user/1/files/2", true},
+		{"https://media.ghe.example.com/user/1/files/2", true},
+		{"https://GHE.example.com/user-attachments/assets/1", true},
+		{"https://example.com/image.png", false},
+		{"https://github.com.example.com/image.png", false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, repo.isTrustedHost(tt.url), tt.url)
+	}
+
+	assertEqualCmp(t, defaultTrustedImageHosts, trustedImageHosts(*config.NewConfig()))
+}
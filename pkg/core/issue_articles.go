@@ -5,7 +5,9 @@ import (
 	"net/url"
 	"path"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/google/go-github/v86/github"
 	"github.com/pelletier/go-toml/v2"
@@ -74,7 +76,7 @@ func (s *ArticleService) ConvertIssueToArticle(issue *github.Issue) *Article {
 	content = strings.TrimLeft(content, "\n")
 
 	time := issue.GetCreatedAt().Format("2006-01-02_150405")
-	images := extractTargetImages(content, time, s.config.Output.Images.TargetURLs())
+	images := extractTargetImages(content, time, s.config.ImageTargetURLs())
 
 	var tags []string
 	for _, label := range issue.Labels {
@@ -84,7 +86,7 @@ func (s *ArticleService) ConvertIssueToArticle(issue *github.Issue) *Article {
 	article := &Article{
 		Author:      issue.GetUser().GetLogin(),
 		Title:       issue.GetTitle(),
-		Date:        issue.GetCreatedAt().Format("2006-01-02T15:04:05Z"),
+		Date:        s.formatTime(s.articleDate(issue)),
 		Category:    issue.GetMilestone().GetTitle(),
 		Draft:       issue.GetState() == "open",
 		Content:     content,
@@ -92,25 +94,181 @@ func (s *ArticleService) ConvertIssueToArticle(issue *github.Issue) *Article {
 		Tags:        tags,
 		Key:         time,
 		Images:      images,
+		Number:      issue.GetNumber(),
+		Fields:      s.issueFrontMatterFields(issue),
+	}
+	if s.config.Output != nil {
+		article.Mapping = s.config.Output.FrontMatter
 	}
 	FilterArticleTags(article, s.config)
 	return article
 }
 
-// FilterArticleTags removes labels used to select issues from article tags.
+// articleDate returns the issue time output.articles.date selects. Issues
+// that are not closed are dated by their creation time. For first_closed this
+// is the last close time, which the generator replaces with the time of the
+// first closed event.
+func (s *ArticleService) articleDate(issue *github.Issue) time.Time {
+	var articles *config.OutputArticlesConfig
+	if s.config.Output != nil {
+		articles = s.config.Output.Articles
+	}
+	switch articles.DateSource() {
+	case config.DateSourceClosed, config.DateSourceFirstClosed:
+		if issue.ClosedAt != nil {
+			return issue.GetClosedAt().Time
+		}
+	}
+	return issue.GetCreatedAt().Time
+}
+
+// formatTime formats an issue time as an RFC 3339 date in output.timezone,
+// or in UTC when it is not set. Loading the configuration rejects unknown
+// zones, and Save fails on them.
+func (s *ArticleService) formatTime(t time.Time) string {
+	location, _ := s.config.Output.Location()
+	if location == nil {
+		location = time.UTC
+	}
+	return t.In(location).Format(time.RFC3339)
+}
+
+// ConvertIssueToArticleWithComments converts a GitHub issue and its comments
+// into an Article according to output.comments. Images attached in comments
+// are collected together with those of the body.
+func (s *ArticleService) ConvertIssueToArticleWithComments(issue *github.Issue, comments []*github.IssueComment) *Article {
+	article := s.ConvertIssueToArticle(issue)
+	commentsConf := s.config.Output.Comments
+	if article == nil || !commentsConf.Enabled() {
+		return article
+	}
+
+	for _, comment := range comments {
+		if commentsConf.AuthorOnly && !isIssueAuthor(issue, comment) {
+			continue
+		}
+		article.Comments = append(article.Comments, &Comment{
+			ID:      comment.GetID(),
+			Author:  comment.GetUser().GetLogin(),
+			Date:    s.formatTime(comment.GetCreatedAt().Time),
+			URL:     comment.GetHTMLURL(),
+			Content: strings.TrimSpace(removeCR(comment.GetBody())),
+		})
+	}
+
+	if commentsConf.Mode == config.CommentsModeAppend && len(article.Comments) > 0 {
+		article.Content = appendComments(article.Content, commentsConf.HeadingText(), article.Comments)
+	}
+
+	sources := []string{article.Content}
+	for _, comment := range article.Comments {
+		sources = append(sources, comment.Content)
+	}
+	article.Images = extractTargetImages(strings.Join(sources, "\n"), article.Key, s.config.ImageTargetURLs())
+	return article
+}
+
+// issueFrontMatterFields returns the issue fields that only
+// output.frontMatter.mapping writes to the front matter.
+func (s *ArticleService) issueFrontMatterFields(issue *github.Issue) map[string]any {
+	assignees := []string{}
+	for _, assignee := range issue.Assignees {
+		assignees = append(assignees, assignee.GetLogin())
+	}
+	fields := map[string]any{
+		config.FrontMatterFieldNumber:    issue.GetNumber(),
+		config.FrontMatterFieldURL:       issue.GetHTMLURL(),
+		config.FrontMatterFieldAssignees: assignees,
+		config.FrontMatterFieldCreated:   s.formatTime(issue.GetCreatedAt().Time),
+	}
+	if issue.UpdatedAt != nil {
+		fields[config.FrontMatterFieldUpdated] = s.formatTime(issue.GetUpdatedAt().Time)
+	}
+	if reason := issue.GetStateReason(); reason != "" {
+		fields[config.FrontMatterFieldStateReason] = reason
+	}
+	if issue.ClosedAt != nil {
+		fields[config.FrontMatterFieldClosed] = s.formatTime(issue.GetClosedAt().Time)
+	}
+	if reactions := issue.Reactions; reactions != nil {
+		fields[config.FrontMatterFieldReactions] = map[string]any{
+			"total_count": reactions.GetTotalCount(),
+			"+1":          reactions.GetPlusOne(),
+			"-1":          reactions.GetMinusOne(),
+			"laugh":       reactions.GetLaugh(),
+			"confused":    reactions.GetConfused(),
+			"heart":       reactions.GetHeart(),
+			"hooray":      reactions.GetHooray(),
+			"rocket":      reactions.GetRocket(),
+			"eyes":        reactions.GetEyes(),
+		}
+	}
+	return fields
+}
+
+func isIssueAuthor(issue *github.Issue, comment *github.IssueComment) bool {
+	author := issue.GetUser().GetLogin()
+	return author != "" && comment.GetUser().GetLogin() == author
+}
+
+// appendComments renders comments below the article content.
+func appendComments(content, heading string, comments []*Comment) string {
+	var b strings.Builder
+	b.WriteString(content)
+	b.WriteString("\n## " + heading + "\n")
+	for _, comment := range comments {
+		date := comment.Date
+		if len(date) >= len("2006-01-02") {
+			date = date[:len("2006-01-02")]
+		}
+		fmt.Fprintf(&b, "\n### %s (%s)\n\n%s\n", comment.Author, date, comment.Content)
+	}
+	return b.String()
+}
+
+// FilterArticleTags removes labels used to select issues from article tags,
+// then routes the remaining labels by output.frontMatter.taxonomies: labels
+// matching a rule move to the taxonomy of the rule without their prefix or
+// are dropped, and other labels stay in the tags.
 func FilterArticleTags(article *Article, conf config.Config) {
-	if article == nil || conf.GitHub == nil || len(article.Tags) == 0 || len(conf.GitHub.Labels) == 0 {
+	if article == nil || len(article.Tags) == 0 {
 		return
 	}
 
-	excluded := make(map[string]struct{}, len(conf.GitHub.Labels))
-	for _, label := range conf.GitHub.Labels {
-		excluded[label] = struct{}{}
+	excluded := map[string]struct{}{}
+	if conf.GitHub != nil {
+		for _, label := range conf.GitHub.Labels {
+			excluded[label] = struct{}{}
+		}
+	}
+	var frontMatter *config.OutputFrontMatterConfig
+	if conf.Output != nil {
+		frontMatter = conf.Output.FrontMatter
 	}
+	labelsKey, _ := frontMatter.Key(config.FrontMatterFieldLabels)
+
 	filtered := article.Tags[:0]
 	for _, tag := range article.Tags {
-		if _, ok := excluded[tag]; !ok {
-			filtered = append(filtered, tag)
+		if _, ok := excluded[tag]; ok {
+			continue
+		}
+		taxonomy, value, ok := frontMatter.Taxonomy(tag)
+		if !ok {
+			taxonomy.Key, value = labelsKey, tag
+		}
+		switch {
+		case taxonomy.Key == "" || value == "":
+		case taxonomy.Key == labelsKey:
+			if !slices.Contains(filtered, value) {
+				filtered = append(filtered, value)
+			}
+		default:
+			if article.Taxonomies == nil {
+				article.Taxonomies = map[string][]string{}
+			}
+			if !slices.Contains(article.Taxonomies[taxonomy.Key], value) {
+				article.Taxonomies[taxonomy.Key] = append(article.Taxonomies[taxonomy.Key], value)
+			}
 		}
 	}
 	article.Tags = filtered
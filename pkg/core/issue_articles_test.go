@@ -371,6 +371,74 @@ func TestArticleService_ConvertIssueToArticle_CRRemoval(t *testing.T) {
 	assertEqualCmp(t, "Line1\nLine2\nLine3\n", got.Content)
 }
 
+func TestArticleService_ConvertIssueToArticleWithComments(t *testing.T) {
+	issue := &github.Issue{
+		Number:    Ptr(3),
+		Title:     Ptr("Test"),
+		Body:      Ptr("![body](https://github.com/user-attachments/assets/body)"),
+		CreatedAt: parseTime("2021-01-01T00:00:00Z"),
+		User:      &github.User{Login: Ptr("author")},
+		State:     Ptr("closed"),
+	}
+	comments := []*github.IssueComment{
+		{
+			ID:        Ptr(int64(10)),
+			Body:      Ptr("Update\r\n\r\n![shot](https://github.com/user-attachments/assets/comment)\n"),
+			User:      &github.User{Login: Ptr("author")},
+			CreatedAt: parseTime("2021-01-02T03:04:05Z"),
+			HTMLURL:   Ptr("https://github.com/o/r/issues/3#issuecomment-10"),
+		},
+		{
+			ID:        Ptr(int64(11)),
+			Body:      Ptr("Nice! ![body](https://github.com/user-attachments/assets/body)"),
+			User:      &github.User{Login: Ptr("reader")},
+			CreatedAt: parseTime("2021-01-03T00:00:00Z"),
+		},
+	}
+
+	t.Run("disabled", func(t *testing.T) {
+		service := NewArticleService(*config.NewConfig())
+
+		got := service.ConvertIssueToArticleWithComments(issue, comments)
+		assert.Nil(t, got.Comments)
+		assert.Len(t, got.Images, 1)
+	})
+
+	t.Run("append", func(t *testing.T) {
+		conf := *config.NewConfig()
+		conf.Output.Comments = &config.OutputCommentsConfig{Mode: config.CommentsModeAppend, Heading: "Discussion"}
+		service := NewArticleService(conf)
+
+		got := service.ConvertIssueToArticleWithComments(issue, comments)
+		assertEqualCmp(t, "![body](https://github.com/user-attachments/assets/body)\n"+
+			"\n## Discussion\n"+
+			"\n### author (2021-01-02)\n\nUpdate\n\n![shot](https://github.com/user-attachments/assets/comment)\n"+
+			"\n### reader (2021-01-03)\n\nNice! ![body](https://github.com/user-attachments/assets/body)\n", got.Content)
+		// Body images keep their IDs and duplicates are downloaded once.
+		require.Len(t, got.Images, 2)
+		assertEqualCmp(t, "https://github.com/user-attachments/assets/body", got.Images[0].URL)
+		assertEqualCmp(t, "https://github.com/user-attachments/assets/comment", got.Images[1].URL)
+		assertEqualCmp(t, 1, got.Images[1].ID)
+	})
+
+	t.Run("data, author only", func(t *testing.T) {
+		conf := *config.NewConfig()
+		conf.Output.Comments = &config.OutputCommentsConfig{Mode: config.CommentsModeData, AuthorOnly: true}
+		service := NewArticleService(conf)
+
+		got := service.ConvertIssueToArticleWithComments(issue, comments)
+		assertEqualCmp(t, "![body](https://github.com/user-attachments/assets/body)\n", got.Content)
+		assertEqualCmp(t, []*Comment{{
+			ID:      10,
+			Author:  "author",
+			Date:    "2021-01-02T03:04:05Z",
+			URL:     "https://github.com/o/r/issues/3#issuecomment-10",
+			Content: "Update\n\n![shot](https://github.com/user-attachments/assets/comment)",
+		}}, got.Comments)
+		assert.Len(t, got.Images, 2)
+	})
+}
+
 func TestMetadataParser_Parse(t *testing.T) {
 	parser := newMetadataParser()
 
@@ -440,3 +508,122 @@ func parseTime(s string) *github.Timestamp {
 	}
 	return &github.Timestamp{Time: t}
 }
+
+func TestFilterArticleTags_Taxonomies(t *testing.T) {
+	conf := *config.NewConfig()
+	conf.GitHub.Labels = []string{"published"}
+	conf.Output.FrontMatter = &config.OutputFrontMatterConfig{
+		Mapping: map[string]string{config.FrontMatterFieldMilestone: ""},
+		Taxonomies: []config.OutputTaxonomyConfig{
+			{Prefix: "category:", Key: "categories"},
+			{Prefix: "series:", Key: "series"},
+			{Prefix: "tag:", Key: "tags"},
+			{Prefix: "status:", Key: ""},
+		},
+	}
+	issue := &github.Issue{
+		Number: Ptr(1), Title: Ptr("Go tips"), Body: Ptr("Body"), State: Ptr("closed"),
+		User: &github.User{Login: Ptr("testuser")}, CreatedAt: parseTime("2024-01-01T00:00:00Z"),
+		Labels: []*github.Label{
+			{Name: Ptr("published")}, {Name: Ptr("Category:Backend")}, {Name: Ptr("series: go-tips")},
+			{Name: Ptr("tag:go")}, {Name: Ptr("go")}, {Name: Ptr("hugo")}, {Name: Ptr("status:reviewed")},
+		},
+	}
+
+	article := NewArticleService(conf).ConvertIssueToArticle(issue)
+	assertEqualCmp(t, []string{"go", "hugo"}, article.Tags)
+	assertEqualCmp(t, map[string][]string{"categories": {"Backend"}, "series": {"go-tips"}}, article.Taxonomies)
+
+	got, err := NewHugoArticleRenderer().Render(article)
+	require.NoError(t, err)
+	assertEqualCmp(t, `---
+author: testuser
+title: Go tips
+date: "2024-01-01T00:00:00Z"
+tags:
+    - go
+    - hugo
+categories:
+    - Backend
+series:
+    - go-tips
+draft: false
+---
+
+Body
+
+`, got)
+
+	t.Run("is idempotent", func(t *testing.T) {
+		again := article.Clone()
+		FilterArticleTags(again, conf)
+		assertEqualCmp(t, article.Tags, again.Tags)
+		assertEqualCmp(t, article.Taxonomies, again.Taxonomies)
+	})
+}
+
+func TestArticleService_ConvertIssueToArticle_DateSource(t *testing.T) {
+	closed := &github.Issue{
+		Title:     Ptr("Closed"),
+		State:     Ptr("closed"),
+		CreatedAt: parseTime("2024-01-01T09:00:00Z"),
+		ClosedAt:  parseTime("2024-01-03T18:30:00Z"),
+	}
+	open := &github.Issue{
+		Title:     Ptr("Open"),
+		State:     Ptr("open"),
+		CreatedAt: parseTime("2024-01-01T09:00:00Z"),
+	}
+	tests := []struct {
+		source string
+		issue  *github.Issue
+		want   string
+	}{
+		{source: "", issue: closed, want: "2024-01-01T09:00:00Z"},
+		{source: config.DateSourceCreated, issue: closed, want: "2024-01-01T09:00:00Z"},
+		{source: config.DateSourceClosed, issue: closed, want: "2024-01-03T18:30:00Z"},
+		{source: config.DateSourceClosed, issue: open, want: "2024-01-01T09:00:00Z"},
+		{source: config.DateSourceFrontMatter, issue: closed, want: "2024-01-01T09:00:00Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source+"/"+tt.issue.GetTitle(), func(t *testing.T) {
+			conf := *config.NewConfig()
+			conf.Output.Articles.Date = tt.source
+			article := NewArticleService(conf).ConvertIssueToArticle(tt.issue)
+			assertEqualCmp(t, tt.want, article.Date)
+		})
+	}
+}
+
+func TestArticleService_ConvertIssueToArticle_Timezone(t *testing.T) {
+	issue := &github.Issue{
+		Title:     Ptr("Morning post"),
+		Body:      Ptr("Body"),
+		State:     Ptr("closed"),
+		CreatedAt: parseTime("2024-01-14T23:30:00Z"),
+		UpdatedAt: parseTime("2024-01-15T15:00:00Z"),
+		ClosedAt:  parseTime("2024-01-15T15:00:00Z"),
+	}
+	tests := []struct {
+		timezone string
+		date     string
+		updated  string
+	}{
+		{timezone: "", date: "2024-01-14T23:30:00Z", updated: "2024-01-15T15:00:00Z"},
+		{timezone: "UTC", date: "2024-01-14T23:30:00Z", updated: "2024-01-15T15:00:00Z"},
+		{timezone: "Asia/Tokyo", date: "2024-01-15T08:30:00+09:00", updated: "2024-01-16T00:00:00+09:00"},
+		{timezone: "America/New_York", date: "2024-01-14T18:30:00-05:00", updated: "2024-01-15T10:00:00-05:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.timezone, func(t *testing.T) {
+			conf := *config.NewConfig()
+			conf.Output.Timezone = tt.timezone
+			article := NewArticleService(conf).ConvertIssueToArticle(issue)
+			assertEqualCmp(t, tt.date, article.Date)
+			assertEqualCmp(t, tt.updated, article.Fields[config.FrontMatterFieldUpdated])
+			assertEqualCmp(t, tt.updated, article.Fields[config.FrontMatterFieldClosed])
+			// Images and comment data files keep their UTC names.
+			assertEqualCmp(t, "2024-01-14_233000", article.Key)
+		})
+	}
+}
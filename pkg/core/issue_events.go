@@ -0,0 +1,122 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/google/go-github/v86/github"
+)
+
+// ErrIssueNotFound is returned by IssueStore.GetIssue when the issue does not
+// exist or was deleted.
+var ErrIssueNotFound = errors.New("issue not found")
+
+// ErrRepositoryNotFound is returned by IssueStore.GetIssue when the
+// repository itself cannot be found, for example because its name is wrong or
+// the token cannot access it. Unlike ErrIssueNotFound, it says nothing about
+// whether the issue still exists.
+var ErrRepositoryNotFound = errors.New("repository not found")
+
+// repositoryNotFound returns an error wrapping ErrRepositoryNotFound.
+func repositoryNotFound(username, repository string) error {
+	return fmt.Errorf("%w: %s/%s does not exist or is not accessible with the configured token", ErrRepositoryNotFound, username, repository)
+}
+
+// IssueOutcome describes what processing a single issue did.
+type IssueOutcome string
+
+const (
+	// IssueGenerated means the issue's article was written.
+	IssueGenerated IssueOutcome = "generated"
+	// IssueRemoved means the issue's previously generated files were removed.
+	IssueRemoved IssueOutcome = "removed"
+	// IssueSkipped means the issue produces no article and no files of it
+	// had been generated, so nothing changed.
+	IssueSkipped IssueOutcome = "skipped"
+)
+
+// LoadIssuesEvent reads a GitHub "issues" event payload, such as the file
+// referenced by GITHUB_EVENT_PATH in GitHub Actions.
+func LoadIssuesEvent(path string) (*github.IssuesEvent, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read event payload %s: %w", path, err)
+	}
+	return ParseIssuesEvent(data)
+}
+
+// ParseIssuesEvent decodes a GitHub "issues" event payload.
+func ParseIssuesEvent(data []byte) (*github.IssuesEvent, error) {
+	var event github.IssuesEvent
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, fmt.Errorf("parse event payload: %w", err)
+	}
+	if event.GetIssue().GetNumber() == 0 {
+		return nil, fmt.Errorf("event payload does not contain an issue; only issues events are supported")
+	}
+	return &event, nil
+}
+
+// HandleIssuesEvent regenerates or removes the article of the issue an
+// "issues" event refers to. Deleted and transferred issues are removed
+// without calling the API; every other action re-fetches the issue so that
+// the decision is based on its current state.
+func (g *ArticleGenerator) HandleIssuesEvent(ctx context.Context, username, repository string, event *github.IssuesEvent) (IssueOutcome, error) {
+	if event == nil || event.Issue == nil {
+		return "", fmt.Errorf("event does not contain an issue")
+	}
+	if repo := event.GetRepo(); repo != nil && !strings.EqualFold(repo.GetFullName(), username+"/"+repository) {
+		return "", fmt.Errorf("event for repository %s does not match the configured repository %s/%s", repo.GetFullName(), username, repository)
+	}
+
+	src, err := g.source(username, repository)
+	if err != nil {
+		return "", err
+	}
+
+	number := event.GetIssue().GetNumber()
+	switch event.GetAction() {
+	case "deleted", "transferred":
+		g.logger.Info("Removing article of "+event.GetAction()+" issue", "issue", number)
+		return g.removeIssueOutputs(ctx, src, number)
+	default:
+		return g.GenerateIssue(ctx, username, repository, number)
+	}
+}
+
+// issueBelongsTo reports whether the issue is still in the given repository.
+// Transferred issues are served from their new repository.
+func issueBelongsTo(issue *github.Issue, username, repository string) bool {
+	repoURL := issue.GetRepositoryURL()
+	if repoURL == "" {
+		return true
+	}
+	return strings.HasSuffix(strings.ToLower(repoURL), strings.ToLower("/repos/"+username+"/"+repository))
+}
+
+// issueMatchesQuery reports whether a fetched issue would be returned by
+// ListIssues for the query.
+func issueMatchesQuery(issue *github.Issue, query IssueListQuery) bool {
+	if issue.IsPullRequest() {
+		return false
+	}
+	for _, want := range query.Labels {
+		if !issueHasLabel(issue, want) {
+			return false
+		}
+	}
+	return query.Filter.Matches(issue)
+}
+
+func issueHasLabel(issue *github.Issue, name string) bool {
+	for _, label := range issue.Labels {
+		if strings.EqualFold(label.GetName(), name) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const deletedIssuesEventPayload = `{
+	"action": "deleted",
+	"issue": {"number": 7, "title": "Removed post", "state": "open"},
+	"repository": {"name": "testrepo", "full_name": "testuser/testrepo", "owner": {"login": "testuser"}}
+}`
+
+func TestParseIssuesEvent(t *testing.T) {
+	event, err := ParseIssuesEvent([]byte(deletedIssuesEventPayload))
+	require.NoError(t, err)
+	assertEqualCmp(t, "deleted", event.GetAction())
+	assertEqualCmp(t, 7, event.GetIssue().GetNumber())
+
+	_, err = ParseIssuesEvent([]byte(`{"action": "created", "comment": {"id": 1}}`))
+	assert.ErrorContains(t, err, "does not contain an issue")
+
+	_, err = ParseIssuesEvent([]byte(`{`))
+	assert.ErrorContains(t, err, "parse event payload")
+}
+
+func TestLoadIssuesEvent(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "event.json")
+	require.NoError(t, os.WriteFile(path, []byte(deletedIssuesEventPayload), 0o644))
+
+	event, err := LoadIssuesEvent(path)
+	require.NoError(t, err)
+	assertEqualCmp(t, 7, event.GetIssue().GetNumber())
+
+	_, err = LoadIssuesEvent(filepath.Join(t.TempDir(), "missing.json"))
+	assert.ErrorContains(t, err, "read event payload")
+}
+
+func TestArticleGenerator_HandleIssuesEvent(t *testing.T) {
+	t.Run("deleted issue removes generated files without fetching", func(t *testing.T) {
+		articlePath := filepath.Join(t.TempDir(), "7.md")
+		require.NoError(t, os.WriteFile(articlePath, []byte("x"), 0o644))
+		manifest := NewManifest()
+		manifest.record(numberKey(7), "", &github.Issue{Number: github.Ptr(7)}, &ArticleOutput{ArticlePath: articlePath})
+
+		gen := &ArticleGenerator{
+			issueRepo: &stubIssueStore{err: assert.AnError},
+			config:    *config.NewConfig(),
+			logger:    slog.Default(),
+		}
+		gen.SetManifest(manifest)
+
+		event, err := ParseIssuesEvent([]byte(deletedIssuesEventPayload))
+		require.NoError(t, err)
+		outcome, err := gen.HandleIssuesEvent(context.Background(), "testuser", "testrepo", event)
+		require.NoError(t, err)
+		assertEqualCmp(t, IssueRemoved, outcome)
+		assert.NoFileExists(t, articlePath)
+	})
+
+	t.Run("other actions regenerate the current issue", func(t *testing.T) {
+		conf := *config.NewConfig()
+		var saved []string
+		gen := &ArticleGenerator{
+			issueRepo: &stubIssueStore{issues: []*github.Issue{{
+				Number:    github.Ptr(7),
+				Title:     Ptr("Current title"),
+				CreatedAt: generatorParseTime("2021-01-01T00:00:00Z"),
+				State:     Ptr("closed"),
+			}}},
+			articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+				saved = append(saved, article.Title)
+				return &ArticleOutput{}, nil
+			}},
+			service: NewArticleService(conf),
+			config:  conf,
+			logger:  slog.Default(),
+		}
+
+		event := &github.IssuesEvent{
+			Action: Ptr("edited"),
+			Issue:  &github.Issue{Number: github.Ptr(7), Title: Ptr("Stale title from payload")},
+		}
+		outcome, err := gen.HandleIssuesEvent(context.Background(), "testuser", "testrepo", event)
+		require.NoError(t, err)
+		assertEqualCmp(t, IssueGenerated, outcome)
+		assertEqualCmp(t, []string{"Current title"}, saved)
+	})
+
+	t.Run("rejects events from another repository", func(t *testing.T) {
+		gen := &ArticleGenerator{issueRepo: &stubIssueStore{}, logger: slog.Default()}
+		event := &github.IssuesEvent{
+			Action: Ptr("closed"),
+			Issue:  &github.Issue{Number: github.Ptr(7)},
+			Repo:   &github.Repository{FullName: Ptr("someone/else")},
+		}
+		_, err := gen.HandleIssuesEvent(context.Background(), "testuser", "testrepo", event)
+		assert.ErrorContains(t, err, "does not match the configured repository")
+	})
+}
+
+func TestIssueMatchesQuery(t *testing.T) {
+	issue := &github.Issue{Labels: []*github.Label{{Name: Ptr("Blog")}, {Name: Ptr("go")}}}
+
+	assert.True(t, issueMatchesQuery(issue, IssueListQuery{}))
+	assert.True(t, issueMatchesQuery(issue, IssueListQuery{Labels: []string{"blog", "go"}}))
+	assert.False(t, issueMatchesQuery(issue, IssueListQuery{Labels: []string{"blog", "draft"}}))
+	assert.False(t, issueMatchesQuery(&github.Issue{PullRequestLinks: &github.PullRequestLinks{}}, IssueListQuery{}))
+}
+
+func TestIssueBelongsTo(t *testing.T) {
+	assert.True(t, issueBelongsTo(&github.Issue{}, "testuser", "testrepo"))
+	assert.True(t, issueBelongsTo(&github.Issue{RepositoryURL: Ptr("https://api.github.com/repos/TestUser/TestRepo")}, "testuser", "testrepo"))
+	assert.False(t, issueBelongsTo(&github.Issue{RepositoryURL: Ptr("https://api.github.com/repos/other/testrepo")}, "testuser", "testrepo"))
+}
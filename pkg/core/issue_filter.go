@@ -0,0 +1,148 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+)
+
+// IssueFilter narrows the issues of an IssueListQuery beyond its labels.
+// Issue stores apply what their API supports server-side and check the rest
+// with Matches.
+type IssueFilter struct {
+	// State selects "open" or "closed" issues. Empty or "all" selects both.
+	State string
+	// ExcludeLabels drops issues carrying any of these labels.
+	ExcludeLabels []string
+	// AnyLabels keeps issues carrying at least one of these labels.
+	AnyLabels []string
+	// Authors keeps issues opened by one of these users.
+	Authors []string
+	// Milestone keeps issues of the milestone with this title or number.
+	// "*" selects issues with any milestone and "none" issues without one.
+	Milestone string
+	// CreatedAfter keeps issues created at or after this time.
+	CreatedAfter time.Time
+	// CreatedBefore keeps issues created before this time.
+	CreatedBefore time.Time
+}
+
+// newIssueFilter converts github.filter into an IssueFilter.
+func newIssueFilter(conf *config.GitHubFilterConfig) IssueFilter {
+	if conf == nil {
+		return IssueFilter{}
+	}
+	return IssueFilter{
+		State:         conf.IssueState(),
+		ExcludeLabels: conf.ExcludeLabels,
+		AnyLabels:     conf.AnyLabels,
+		Authors:       conf.Authors,
+		Milestone:     conf.Milestone,
+		CreatedAfter:  conf.CreatedAfterTime(),
+		CreatedBefore: conf.CreatedBeforeTime(),
+	}
+}
+
+// Matches reports whether the issue passes every condition of the filter.
+func (f IssueFilter) Matches(issue *github.Issue) bool {
+	if state := f.state(); state != config.IssueStateAll && !strings.EqualFold(issue.GetState(), state) {
+		return false
+	}
+	for _, label := range f.ExcludeLabels {
+		if issueHasLabel(issue, label) {
+			return false
+		}
+	}
+	if len(f.AnyLabels) > 0 && !issueHasAnyLabel(issue, f.AnyLabels) {
+		return false
+	}
+	if len(f.Authors) > 0 && !containsFold(f.Authors, issue.GetUser().GetLogin()) {
+		return false
+	}
+	if f.Milestone != "" && !f.matchesMilestone(issue.GetMilestone()) {
+		return false
+	}
+	created := issue.GetCreatedAt().Time
+	if !f.CreatedAfter.IsZero() && created.Before(f.CreatedAfter) {
+		return false
+	}
+	if !f.CreatedBefore.IsZero() && !created.Before(f.CreatedBefore) {
+		return false
+	}
+	return true
+}
+
+func (f IssueFilter) matchesMilestone(milestone *github.Milestone) bool {
+	switch f.Milestone {
+	case "*":
+		return milestone != nil
+	case "none":
+		return milestone == nil
+	}
+	if milestone == nil {
+		return false
+	}
+	if number, err := strconv.Atoi(f.Milestone); err == nil {
+		return milestone.GetNumber() == number
+	}
+	return strings.EqualFold(milestone.GetTitle(), f.Milestone)
+}
+
+// state returns the selected state, defaulting to all.
+func (f IssueFilter) state() string {
+	if f.State == "" {
+		return config.IssueStateAll
+	}
+	return strings.ToLower(f.State)
+}
+
+// since returns the since parameter that lists at least every issue created
+// after CreatedAfter: an issue is never updated before it was created.
+func (f IssueFilter) since(since time.Time) time.Time {
+	if f.CreatedAfter.After(since) {
+		return f.CreatedAfter
+	}
+	return since
+}
+
+// creator returns the author to filter by server-side. The APIs accept a
+// single author only.
+func (f IssueFilter) creator() string {
+	if len(f.Authors) != 1 {
+		return ""
+	}
+	return f.Authors[0]
+}
+
+// milestoneParameter returns the milestone parameter of the REST API, which
+// accepts a milestone number, "*" or "none" but no title.
+func (f IssueFilter) milestoneParameter() string {
+	if f.Milestone == "*" || f.Milestone == "none" {
+		return f.Milestone
+	}
+	if _, err := strconv.Atoi(f.Milestone); err == nil {
+		return f.Milestone
+	}
+	return ""
+}
+
+func issueHasAnyLabel(issue *github.Issue, names []string) bool {
+	for _, name := range names {
+		if issueHasLabel(issue, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsFold(values []string, value string) bool {
+	for _, v := range values {
+		if strings.EqualFold(v, value) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+)
+
+// ManifestFilename is the name of the generation manifest inside the
+// configured state directory.
+const ManifestFilename = "manifest.json"
+
+// Manifest records which files were generated for which issue so that
+// outputs whose issue disappeared can be pruned later.
+type Manifest struct {
+	// Articles maps an issue key to the files generated for it.
+	Articles map[string]*ManifestEntry `json:"articles"`
+	// Orphans lists files that an issue produced in an earlier run but no
+	// longer references, for example after its date changed.
+	Orphans []string `json:"orphans,omitempty"`
+}
+
+// ManifestEntry describes the outputs of a single issue.
+type ManifestEntry struct {
+	Number int `json:"number"`
+	// Repository is the "owner/repository" of the issue when github.sources
+	// is set.
+	Repository  string   `json:"repository,omitempty"`
+	ArticlePath string   `json:"article"`
+	ImagePaths  []string `json:"images,omitempty"`
+	DataPaths   []string `json:"data,omitempty"`
+	OGPPath     string   `json:"ogp,omitempty"`
+	ContentHash string   `json:"contentHash,omitempty"`
+}
+
+// NewManifest creates an empty Manifest.
+func NewManifest() *Manifest {
+	return &Manifest{Articles: map[string]*ManifestEntry{}}
+}
+
+// ManifestPath returns the path of the manifest file for the configuration.
+func ManifestPath(conf config.Config) string {
+	return filepath.Join(conf.Output.StateDirectory(), ManifestFilename)
+}
+
+// LoadManifest reads a manifest file. A missing file yields an empty manifest.
+func LoadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewManifest(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read manifest %s: %w", path, err)
+	}
+
+	manifest := NewManifest()
+	if err := json.Unmarshal(data, manifest); err != nil {
+		return nil, fmt.Errorf("parse manifest %s: %w", path, err)
+	}
+	if manifest.Articles == nil {
+		manifest.Articles = map[string]*ManifestEntry{}
+	}
+	return manifest, nil
+}
+
+// Save writes the manifest to path, creating parent directories as needed.
+func (m *Manifest) Save(path string) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	if err := createDirectoryIfNotExist(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("create manifest directory: %w", err)
+	}
+	if err := createFileAndWrite(path, string(data)+"\n"); err != nil {
+		return fmt.Errorf("write manifest %s: %w", path, err)
+	}
+	return nil
+}
+
+// Files returns every generated file path in the entry.
+func (e *ManifestEntry) Files() []string {
+	output := ArticleOutput{ArticlePath: e.ArticlePath, ImagePaths: e.ImagePaths, DataPaths: e.DataPaths, OGPPath: e.OGPPath}
+	return output.Files()
+}
+
+func (m *Manifest) has(key string) bool {
+	_, ok := m.Articles[key]
+	return ok
+}
+
+// record stores the outputs of an issue under key. Files of the previous
+// entry that the new output no longer references are remembered as orphans.
+func (m *Manifest) record(key, repository string, issue *github.Issue, output *ArticleOutput) {
+	current := output.Files()
+	if previous, ok := m.Articles[key]; ok {
+		for _, file := range previous.Files() {
+			if !slices.Contains(current, file) && !slices.Contains(m.Orphans, file) {
+				m.Orphans = append(m.Orphans, file)
+			}
+		}
+	}
+	m.Orphans = slices.DeleteFunc(m.Orphans, func(file string) bool {
+		return slices.Contains(current, file)
+	})
+
+	m.Articles[key] = &ManifestEntry{
+		Number:      issue.GetNumber(),
+		Repository:  repository,
+		ArticlePath: output.ArticlePath,
+		ImagePaths:  append([]string(nil), output.ImagePaths...),
+		DataPaths:   append([]string(nil), output.DataPaths...),
+		OGPPath:     output.OGPPath,
+		ContentHash: output.ContentHash,
+	}
+}
+
+// PrunePlan lists manifest entries and files that are no longer backed by a
+// matching issue.
+type PrunePlan struct {
+	Keys  []string
+	Files []string
+}
+
+// planPrune compares the manifest with the keys of all currently matching
+// issues. Files still referenced by a live entry are never scheduled for
+// removal, even if a stale entry also lists them.
+func (m *Manifest) planPrune(live map[string]struct{}) PrunePlan {
+	referenced := map[string]struct{}{}
+	var plan PrunePlan
+	for key, entry := range m.Articles {
+		if _, ok := live[key]; ok {
+			for _, file := range entry.Files() {
+				referenced[file] = struct{}{}
+			}
+			continue
+		}
+		plan.Keys = append(plan.Keys, key)
+	}
+
+	seen := map[string]struct{}{}
+	addFile := func(file string) {
+		if _, ok := referenced[file]; ok {
+			return
+		}
+		if _, ok := seen[file]; ok {
+			return
+		}
+		seen[file] = struct{}{}
+		plan.Files = append(plan.Files, file)
+	}
+	for _, key := range plan.Keys {
+		for _, file := range m.Articles[key].Files() {
+			addFile(file)
+		}
+	}
+	for _, file := range m.Orphans {
+		addFile(file)
+	}
+
+	sort.Strings(plan.Keys)
+	sort.Strings(plan.Files)
+	return plan
+}
+
+// liveKeysExcept returns every recorded key except the given one.
+func (m *Manifest) liveKeysExcept(key string) map[string]struct{} {
+	live := make(map[string]struct{}, len(m.Articles))
+	for k := range m.Articles {
+		if k != key {
+			live[k] = struct{}{}
+		}
+	}
+	return live
+}
+
+// applyPrune removes the planned entries and orphans from the manifest.
+func (m *Manifest) applyPrune(plan PrunePlan) {
+	for _, key := range plan.Keys {
+		delete(m.Articles, key)
+	}
+	m.Orphans = nil
+}
+
+// removeGeneratedFile deletes a generated file and its parent directory when
+// the directory is left empty. Missing files are not an error.
+func removeGeneratedFile(path string) error {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	dir := filepath.Dir(path)
+	if dir == "." || dir == string(filepath.Separator) {
+		return nil
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil || len(entries) > 0 {
+		return nil
+	}
+	if err := os.Remove(dir); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadManifest_MissingFileReturnsEmptyManifest(t *testing.T) {
+	manifest, err := LoadManifest(filepath.Join(t.TempDir(), ManifestFilename))
+	require.NoError(t, err)
+	assert.NotNil(t, manifest.Articles)
+	assert.Empty(t, manifest.Articles)
+}
+
+func TestManifest_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gic", ManifestFilename)
+	manifest := NewManifest()
+	manifest.record(numberKey(3), "", &github.Issue{Number: github.Ptr(3)}, &ArticleOutput{
+		ArticlePath: "content/posts/a.md",
+		ImagePaths:  []string{"static/images/a/0.png"},
+		OGPPath:     "content/posts/a.ogp.jpeg",
+		ContentHash: "hash",
+	})
+
+	require.NoError(t, manifest.Save(path))
+	loaded, err := LoadManifest(path)
+	require.NoError(t, err)
+	assertEqualCmp(t, manifest, loaded)
+}
+
+func TestManifest_RecordTracksFilesNoLongerReferenced(t *testing.T) {
+	manifest := NewManifest()
+	issue := &github.Issue{Number: github.Ptr(1)}
+	manifest.record(numberKey(issue.GetNumber()), "", issue, &ArticleOutput{
+		ArticlePath: "content/posts/2021-01-01.md",
+		ImagePaths:  []string{"static/images/2021-01-01/0.png", "static/images/shared.png"},
+	})
+
+	manifest.record(numberKey(issue.GetNumber()), "", issue, &ArticleOutput{
+		ArticlePath: "content/posts/2021-02-01.md",
+		ImagePaths:  []string{"static/images/shared.png"},
+	})
+	assertEqualCmp(t, []string{"content/posts/2021-01-01.md", "static/images/2021-01-01/0.png"}, manifest.Orphans)
+
+	// Writing an orphaned path again makes it live.
+	manifest.record(numberKey(issue.GetNumber()), "", issue, &ArticleOutput{ArticlePath: "content/posts/2021-01-01.md"})
+	assert.NotContains(t, manifest.Orphans, "content/posts/2021-01-01.md")
+	assert.Contains(t, manifest.Orphans, "content/posts/2021-02-01.md")
+}
+
+func TestManifest_PlanPrune(t *testing.T) {
+	manifest := NewManifest()
+	manifest.record(numberKey(1), "", &github.Issue{Number: github.Ptr(1)}, &ArticleOutput{
+		ArticlePath: "content/posts/live.md",
+		ImagePaths:  []string{"static/images/shared.png"},
+	})
+	manifest.record(numberKey(2), "", &github.Issue{Number: github.Ptr(2)}, &ArticleOutput{
+		ArticlePath: "content/posts/gone.md",
+		ImagePaths:  []string{"static/images/gone/0.png", "static/images/shared.png"},
+		OGPPath:     "content/posts/gone.ogp.jpeg",
+	})
+	manifest.Orphans = []string{"content/posts/old.md"}
+
+	plan := manifest.planPrune(map[string]struct{}{"1": {}})
+
+	assertEqualCmp(t, []string{"2"}, plan.Keys)
+	assertEqualCmp(t, []string{
+		"content/posts/gone.md",
+		"content/posts/gone.ogp.jpeg",
+		"content/posts/old.md",
+		"static/images/gone/0.png",
+	}, plan.Files)
+
+	manifest.applyPrune(plan)
+	assert.NotContains(t, manifest.Articles, "2")
+	assert.Contains(t, manifest.Articles, "1")
+	assert.Empty(t, manifest.Orphans)
+}
+
+func TestRemoveGeneratedFile_RemovesEmptyParentDirectory(t *testing.T) {
+	tempDir := t.TempDir()
+	imageDir := filepath.Join(tempDir, "images", "2021-01-01")
+	require.NoError(t, os.MkdirAll(imageDir, 0o755))
+	first := filepath.Join(imageDir, "0.png")
+	second := filepath.Join(imageDir, "1.png")
+	require.NoError(t, os.WriteFile(first, []byte("0"), 0o644))
+	require.NoError(t, os.WriteFile(second, []byte("1"), 0o644))
+
+	require.NoError(t, removeGeneratedFile(first))
+	_, err := os.Stat(imageDir)
+	require.NoError(t, err, "directory with remaining files must be kept")
+
+	require.NoError(t, removeGeneratedFile(second))
+	_, err = os.Stat(imageDir)
+	assert.True(t, os.IsNotExist(err))
+	_, err = os.Stat(filepath.Join(tempDir, "images"))
+	require.NoError(t, err, "only the immediate parent directory is removed")
+
+	require.NoError(t, removeGeneratedFile(filepath.Join(tempDir, "missing.md")))
+}
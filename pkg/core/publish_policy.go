@@ -0,0 +1,121 @@
+package core
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+)
+
+// Front-matter keys of the issue body that schedule an article.
+const (
+	publishDateKey = "publishDate"
+	expiryDateKey  = "expiryDate"
+)
+
+// publishAction returns what output.publish does with the article of the
+// issue at now, reading front-matter dates without an offset in loc:
+// config.PublishPublish, config.PublishDraft or
+// config.PublishSkip, together with the reason when the article is not
+// published as usual. Skipping wins over saving as a draft, and with
+// output.publish.drafts set to skip, drafts are skipped.
+func publishAction(conf *config.OutputPublishConfig, loc *time.Location, issue *github.Issue, article *Article, now time.Time) (string, string) {
+	action, reason := config.PublishPublish, ""
+	apply := func(candidate, why string) {
+		if candidate == config.PublishSkip || (candidate == config.PublishDraft && action == config.PublishPublish) {
+			action, reason = candidate, why
+		}
+	}
+
+	if stateReason := issue.GetStateReason(); stateReason != "" {
+		apply(conf.StateReasonAction(stateReason), fmt.Sprintf("the issue was closed as %s", stateReason))
+	}
+	if conf != nil {
+		for _, label := range conf.DraftLabels {
+			if issueHasLabel(issue, label) {
+				apply(config.PublishDraft, fmt.Sprintf("label %s is applied", label))
+				break
+			}
+		}
+	}
+	values := article.FrontMatter.Values()
+	if publishDate, ok := frontMatterTime(values[publishDateKey], loc); ok && publishDate.After(now) {
+		apply(conf.ScheduledAction(), fmt.Sprintf("%s %s is in the future", publishDateKey, publishDate.Format(time.RFC3339)))
+	}
+	if expiryDate, ok := frontMatterTime(values[expiryDateKey], loc); ok && !expiryDate.After(now) {
+		apply(conf.ExpiredAction(), fmt.Sprintf("%s %s has passed", expiryDateKey, expiryDate.Format(time.RFC3339)))
+	}
+
+	if action != config.PublishSkip && conf.DraftsPolicy() == config.DraftsSkip {
+		switch {
+		case action == config.PublishDraft:
+			return config.PublishSkip, reason + " and drafts are not written"
+		case isDraft(article):
+			return config.PublishSkip, "the article is a draft and drafts are not written"
+		}
+	}
+	return action, reason
+}
+
+// publishChangesAt returns the earliest publishDate or expiryDate of the
+// article after now whose passing changes what publishAction returns, or the
+// zero time when there is none.
+func publishChangesAt(conf *config.OutputPublishConfig, loc *time.Location, article *Article, now time.Time) time.Time {
+	values := article.FrontMatter.Values()
+	var next time.Time
+	consider := func(key, action string) {
+		if action == config.PublishPublish {
+			return
+		}
+		if t, ok := frontMatterTime(values[key], loc); ok && t.After(now) && (next.IsZero() || t.Before(next)) {
+			next = t
+		}
+	}
+	consider(publishDateKey, conf.ScheduledAction())
+	consider(expiryDateKey, conf.ExpiredAction())
+	return next
+}
+
+// skippedByPublishPolicy reports whether output.publish skips the issue at
+// now, given the action github.trust takes for it. Prune uses it to find the
+// issues whose articles are no longer generated.
+func skippedByPublishPolicy(src issueSource, issue *github.Issue, untrusted string, now time.Time) (bool, error) {
+	if src.config.Output.Publish == nil {
+		return false, nil
+	}
+	location, err := src.config.Output.Location()
+	if err != nil {
+		return false, err
+	}
+	article := src.service.ConvertIssueToArticle(issue)
+	if article == nil {
+		return false, nil
+	}
+	if untrusted == config.UntrustedDraft {
+		forceDraft(article)
+	}
+	action, _ := publishAction(src.config.Output.Publish, location, issue, article, now)
+	return action == config.PublishSkip, nil
+}
+
+// frontMatterTime parses a date of the issue body front matter, written as a
+// string or as a YAML timestamp. A date without an offset is read in loc.
+func frontMatterTime(value any, loc *time.Location) (time.Time, bool) {
+	date, ok := dateValue(value)
+	if !ok {
+		return time.Time{}, false
+	}
+	t, err := parseDate(date, loc)
+	return t, err == nil
+}
+
+// isDraft reports whether the article is saved as a draft, taking a draft
+// value in the front matter of the issue body into account.
+func isDraft(article *Article) bool {
+	key, _ := article.Mapping.Key(config.FrontMatterFieldDraft)
+	if draft, ok := boolValue(article.FrontMatter.Values()[key]); ok {
+		return draft
+	}
+	return article.Draft
+}
@@ -0,0 +1,294 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPublishAction(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	policy := &config.OutputPublishConfig{
+		StateReasons: map[string]string{"not_planned": config.PublishSkip, "Duplicate": config.PublishDraft},
+		DraftLabels:  []string{"draft"},
+		Scheduled:    config.PublishDraft,
+		Expired:      config.PublishSkip,
+	}
+	tests := []struct {
+		name        string
+		conf        *config.OutputPublishConfig
+		stateReason string
+		labels      []string
+		state       string
+		frontMatter map[string]any
+		want        string
+	}{
+		{name: "no policy", conf: nil, stateReason: "not_planned", labels: []string{"draft"}, want: config.PublishPublish},
+		{name: "completed", conf: policy, stateReason: "completed", want: config.PublishPublish},
+		{name: "not planned", conf: policy, stateReason: "not_planned", want: config.PublishSkip},
+		{name: "duplicate", conf: policy, stateReason: "duplicate", want: config.PublishDraft},
+		{name: "draft label", conf: policy, labels: []string{"Draft"}, want: config.PublishDraft},
+		{name: "skip wins over draft", conf: policy, stateReason: "not_planned", labels: []string{"draft"}, want: config.PublishSkip},
+		{name: "scheduled", conf: policy, frontMatter: map[string]any{"publishDate": "2024-07-01"}, want: config.PublishDraft},
+		{name: "published", conf: policy, frontMatter: map[string]any{"publishDate": "2024-05-01"}, want: config.PublishPublish},
+		{name: "expired timestamp", conf: policy, frontMatter: map[string]any{"expiryDate": now.Add(-time.Hour)}, want: config.PublishSkip},
+		{name: "expires now in another zone", conf: policy, frontMatter: map[string]any{"expiryDate": "2024-06-01T09:00:00+09:00"}, want: config.PublishSkip},
+		{name: "expires later", conf: policy, frontMatter: map[string]any{"expiryDate": "2024-06-02"}, want: config.PublishPublish},
+		{name: "drafts skipped", conf: &config.OutputPublishConfig{Drafts: config.DraftsSkip}, state: "open", want: config.PublishSkip},
+		{name: "body draft skipped", conf: &config.OutputPublishConfig{Drafts: config.DraftsSkip}, frontMatter: map[string]any{"draft": true}, want: config.PublishSkip},
+		{name: "body publishes open issue", conf: &config.OutputPublishConfig{Drafts: config.DraftsSkip}, state: "open", frontMatter: map[string]any{"draft": false}, want: config.PublishPublish},
+		{name: "forced draft skipped", conf: &config.OutputPublishConfig{DraftLabels: []string{"draft"}, Drafts: config.DraftsSkip}, labels: []string{"draft"}, want: config.PublishSkip},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := "closed"
+			if tt.state != "" {
+				state = tt.state
+			}
+			issue := &github.Issue{State: Ptr(state)}
+			if tt.stateReason != "" {
+				issue.StateReason = Ptr(tt.stateReason)
+			}
+			for _, label := range tt.labels {
+				issue.Labels = append(issue.Labels, &github.Label{Name: Ptr(label)})
+			}
+			article := &Article{Draft: state == "open", FrontMatter: NewFrontMatter(tt.frontMatter)}
+
+			got, reason := publishAction(tt.conf, nil, issue, article, now)
+			assertEqualCmp(t, tt.want, got)
+			assertEqualCmp(t, got != config.PublishPublish, reason != "")
+		})
+	}
+}
+
+func TestArticleGenerator_Generate_AppliesPublishPolicy(t *testing.T) {
+	conf := *config.NewConfig()
+	conf.Output.Publish = &config.OutputPublishConfig{
+		StateReasons: map[string]string{"not_planned": config.PublishSkip},
+		DraftLabels:  []string{"wip"},
+		Drafts:       config.DraftsSkip,
+	}
+	issue := func(number int, state, stateReason string, labels ...string) *github.Issue {
+		issue := &github.Issue{
+			Number:    Ptr(number),
+			Title:     Ptr("Issue"),
+			Body:      Ptr("Body"),
+			State:     Ptr(state),
+			CreatedAt: parseTime("2024-01-01T00:00:00Z"),
+		}
+		if stateReason != "" {
+			issue.StateReason = Ptr(stateReason)
+		}
+		for _, label := range labels {
+			issue.Labels = append(issue.Labels, &github.Label{Name: Ptr(label)})
+		}
+		return issue
+	}
+	issues := []*github.Issue{
+		issue(1, "closed", "completed"),
+		issue(2, "closed", "not_planned"),
+		issue(3, "open", ""),
+		issue(4, "closed", "completed", "wip"),
+	}
+	saved := map[int]*Article{}
+	gen := &ArticleGenerator{
+		issueRepo: &stubIssueStore{issues: issues},
+		articleRepo: stubArticleStore{saveFn: func(ctx context.Context, article *Article, conf config.Config) (*ArticleOutput, error) {
+			saved[article.Number] = article
+			return &ArticleOutput{ArticlePath: t.TempDir() + "/index.md"}, nil
+		}},
+		service: NewArticleService(conf),
+		config:  conf,
+		logger:  slog.Default(),
+	}
+
+	count, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	assertEqualCmp(t, 1, count)
+	assert.Contains(t, saved, 1)
+
+	// Articles saved before the policy was set are pruned.
+	manifest := NewManifest()
+	for _, issue := range issues {
+		manifest.record(numberKey(issue.GetNumber()), "", issue, &ArticleOutput{ArticlePath: t.TempDir() + "/index.md"})
+	}
+	gen.SetManifest(manifest)
+	plan, err := gen.Prune(context.Background(), true)
+	require.NoError(t, err)
+	assertEqualCmp(t, []string{"2", "3", "4"}, plan.Keys)
+}
+
+// sinceIssueStore lists only the issues updated at or after the since
+// parameter, like the GitHub API, and reports no changes to watchers.
+type sinceIssueStore struct {
+	stubIssueStore
+}
+
+func (s *sinceIssueStore) IssuesChanged(ctx context.Context, query IssueListQuery) (bool, error) {
+	return false, nil
+}
+
+func (s *sinceIssueStore) ListIssues(ctx context.Context, query IssueListQuery) ([]*github.Issue, error) {
+	s.lastQuery = query
+	var issues []*github.Issue
+	for _, issue := range s.issues {
+		if query.Since.IsZero() || !issue.GetUpdatedAt().Before(query.Since) {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+func TestArticleGenerator_Generate_RevisitsScheduledIssues(t *testing.T) {
+	dir := t.TempDir()
+	conf := *config.NewConfig()
+	conf.Output.Articles.Directory = filepath.Join(dir, "content")
+	conf.Output.Articles.Filename = "[:number].md"
+	conf.Output.Images.Directory = filepath.Join(dir, "images")
+	conf.Output.Publish = &config.OutputPublishConfig{
+		Scheduled: config.PublishSkip,
+		Expired:   config.PublishSkip,
+	}
+	issue := func(number int, frontMatter string) *github.Issue {
+		return &github.Issue{
+			Number:    Ptr(number),
+			Title:     Ptr("Issue"),
+			Body:      Ptr("---\n" + frontMatter + "\n---\nBody"),
+			State:     Ptr("closed"),
+			CreatedAt: parseTime("2024-05-01T00:00:00Z"),
+			UpdatedAt: parseTime("2024-05-01T00:00:00Z"),
+		}
+	}
+	issueRepo := &sinceIssueStore{stubIssueStore{issues: []*github.Issue{
+		issue(1, "publishDate: 2024-06-01T12:00:00Z"),
+		issue(2, "expiryDate: 2024-06-01T12:00:00Z"),
+		issue(3, "publishDate: 2024-07-01T00:00:00Z"),
+	}}}
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	gen := newArticleGenerator(conf, issueRepo, NewFileSystemArticleRepository(nil), slog.Default())
+	gen.now = func() time.Time { return now }
+	state := NewSyncState()
+	gen.SetSyncState(state)
+	gen.SetManifest(NewManifest())
+	articlePath := func(number int) string {
+		return filepath.Join(dir, "content", strconv.Itoa(number)+".md")
+	}
+
+	count, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	assertEqualCmp(t, 1, count)
+	changed, err := gen.IssuesChanged(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	assert.False(t, changed)
+	assert.NoFileExists(t, articlePath(1))
+	assert.FileExists(t, articlePath(2))
+	assertEqualCmp(t, map[string]time.Time{
+		"1": time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		"2": time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC),
+		"3": time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC),
+	}, state.Pending)
+
+	// The next run lists no issue since none was updated, but the publish
+	// date of #1 and the expiry date of #2 have passed.
+	now = time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+	changed, err = gen.IssuesChanged(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	assert.True(t, changed)
+	count, err = gen.Generate(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	assert.False(t, issueRepo.lastQuery.Since.IsZero())
+	assertEqualCmp(t, 1, count)
+	assert.FileExists(t, articlePath(1))
+	assert.NoFileExists(t, articlePath(2))
+	assert.NoFileExists(t, articlePath(3))
+	assertEqualCmp(t, map[string]time.Time{"3": time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)}, state.Pending)
+}
+
+// approvalSinceIssueStore adds the issue events and permissions github.approval
+// reads to sinceIssueStore.
+type approvalSinceIssueStore struct {
+	sinceIssueStore
+	events      map[int][]*github.IssueEvent
+	permissions map[string]string
+}
+
+func (s *approvalSinceIssueStore) ListIssueEvents(ctx context.Context, username, repository string, number int) ([]*github.IssueEvent, error) {
+	return s.events[number], nil
+}
+
+func (s *approvalSinceIssueStore) GetPermission(ctx context.Context, username, repository, user string) (string, error) {
+	return s.permissions[user], nil
+}
+
+func TestArticleGenerator_Generate_RemovesIssuesThatStopProducingArticles(t *testing.T) {
+	dir := t.TempDir()
+	conf := *config.NewConfig()
+	conf.Output.Articles.Directory = filepath.Join(dir, "content")
+	conf.Output.Articles.Filename = "[:number].md"
+	conf.Output.Images.Directory = filepath.Join(dir, "images")
+	conf.GitHub.Approval = &config.GitHubApprovalConfig{Label: "approved"}
+	conf.Output.Publish = &config.OutputPublishConfig{
+		StateReasons: map[string]string{"not_planned": config.PublishSkip},
+		Scheduled:    config.PublishSkip,
+	}
+	issue := func(number int) *github.Issue {
+		return &github.Issue{
+			Number:    Ptr(number),
+			Title:     Ptr("Issue"),
+			Body:      Ptr("Body"),
+			State:     Ptr("closed"),
+			Labels:    []*github.Label{{Name: Ptr("approved")}},
+			CreatedAt: parseTime("2024-05-01T00:00:00Z"),
+			UpdatedAt: parseTime("2024-05-01T00:00:00Z"),
+		}
+	}
+	issues := []*github.Issue{issue(1), issue(2), issue(3), issue(4)}
+	approved := []*github.IssueEvent{{Event: Ptr("labeled"), Actor: &github.User{Login: Ptr("maintainer")}, Label: &github.Label{Name: Ptr("approved")}}}
+	issueRepo := &approvalSinceIssueStore{
+		sinceIssueStore: sinceIssueStore{stubIssueStore{issues: issues}},
+		events:          map[int][]*github.IssueEvent{1: approved, 2: approved, 3: approved, 4: approved},
+		permissions:     map[string]string{"maintainer": PermissionWrite},
+	}
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	gen := newArticleGenerator(conf, issueRepo, NewFileSystemArticleRepository(nil), slog.Default())
+	gen.now = func() time.Time { return now }
+	state := NewSyncState()
+	gen.SetSyncState(state)
+	manifest := NewManifest()
+	gen.SetManifest(manifest)
+	articlePath := func(number int) string {
+		return filepath.Join(dir, "content", strconv.Itoa(number)+".md")
+	}
+
+	count, err := gen.Generate(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	assertEqualCmp(t, 4, count)
+
+	// #1 loses its approval, #2 is closed as not planned and #3 is
+	// rescheduled. Only the updated issues are listed by the next run.
+	now = time.Date(2024, 6, 2, 0, 0, 0, 0, time.UTC)
+	for _, issue := range issues[:3] {
+		issue.UpdatedAt = parseTime("2024-06-01T12:00:00Z")
+	}
+	issues[0].Labels = nil
+	issues[1].StateReason = Ptr("not_planned")
+	issues[2].Body = Ptr("---\npublishDate: 2024-07-01T00:00:00Z\n---\nBody")
+	count, err = gen.Generate(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	assert.False(t, issueRepo.lastQuery.Since.IsZero())
+	assertEqualCmp(t, 0, count)
+	for _, number := range []int{1, 2, 3} {
+		assert.NoFileExists(t, articlePath(number))
+		assert.NotContains(t, manifest.Articles, strconv.Itoa(number))
+	}
+	assert.FileExists(t, articlePath(4))
+	assertEqualCmp(t, map[string]time.Time{"3": time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)}, state.Pending)
+}
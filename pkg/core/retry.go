@@ -0,0 +1,250 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+)
+
+// RetryPolicy controls how failed requests to GitHub are retried. It is
+// shared by the issue stores and the image downloader.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries after the first attempt. Zero
+	// disables retries.
+	MaxRetries int
+	// InitialDelay is the backoff before the first retry. It doubles with
+	// every retry up to MaxDelay.
+	InitialDelay time.Duration
+	MaxDelay     time.Duration
+	// WaitOnRateLimit waits until an exceeded rate limit resets instead of
+	// failing.
+	WaitOnRateLimit bool
+	// MaxWait is the longest wait for a rate limit reset. Requests whose rate
+	// limit resets later fail immediately.
+	MaxWait time.Duration
+}
+
+// NewRetryPolicy creates the RetryPolicy configured in github.retry.
+func NewRetryPolicy(conf config.Config) RetryPolicy {
+	var retry *config.GitHubRetryConfig
+	if conf.GitHub != nil {
+		retry = conf.GitHub.Retry
+	}
+	return RetryPolicy{
+		MaxRetries:      retry.Retries(),
+		InitialDelay:    retry.InitialDelayDuration(),
+		MaxDelay:        retry.MaxDelayDuration(),
+		WaitOnRateLimit: retry.RateLimitPolicy() == config.RateLimitWait,
+		MaxWait:         retry.MaxWaitDuration(),
+	}
+}
+
+// DefaultRetryPolicy returns the RetryPolicy used when github.retry is not set.
+func DefaultRetryPolicy() RetryPolicy {
+	return NewRetryPolicy(config.Config{})
+}
+
+// backoff returns the exponential backoff before the given retry, starting at
+// zero, with equal jitter so that concurrent clients do not retry in lockstep.
+func (p RetryPolicy) backoff(retry int, jitter func(time.Duration) time.Duration) time.Duration {
+	delay := p.InitialDelay
+	for range retry {
+		if delay >= p.MaxDelay {
+			break
+		}
+		delay *= 2
+	}
+	delay = min(delay, p.MaxDelay)
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + jitter(delay/2)
+}
+
+// retryTransport retries requests that failed with a network error, a server
+// error, or an exceeded rate limit according to a RetryPolicy. Each attempt,
+// including reading its response body, is limited to timeout so that waiting
+// between attempts does not count against it.
+type retryTransport struct {
+	policy  RetryPolicy
+	base    http.RoundTripper
+	timeout time.Duration
+	logger  *slog.Logger
+	now     func() time.Time
+	sleep   func(ctx context.Context, d time.Duration) error
+	jitter  func(time.Duration) time.Duration
+}
+
+// newRetryTransport creates a retryTransport. A zero timeout does not limit
+// attempts.
+func newRetryTransport(policy RetryPolicy, base http.RoundTripper, timeout time.Duration, logger *slog.Logger) *retryTransport {
+	return &retryTransport{
+		policy:  policy,
+		base:    base,
+		timeout: timeout,
+		logger:  defaultLogger(logger),
+		now:     time.Now,
+		sleep:   sleepContext,
+		jitter:  func(d time.Duration) time.Duration { return rand.N(d + 1) },
+	}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for retry := 0; ; retry++ {
+		resp, err := t.roundTripOnce(req, retry)
+		if retry >= t.policy.MaxRetries || req.Context().Err() != nil || !isRewindable(req) {
+			return resp, err
+		}
+
+		delay, ok := t.retryDelay(req, resp, err, retry)
+		if !ok {
+			return resp, err
+		}
+		if resp != nil {
+			// Drain the body so that the connection can be reused.
+			_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<16))
+			resp.Body.Close()
+		}
+		if err := t.sleep(req.Context(), delay); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// roundTripOnce sends one attempt. The attempt's deadline is released when
+// the response body is closed.
+func (t *retryTransport) roundTripOnce(req *http.Request, retry int) (*http.Response, error) {
+	attempt, err := rewindRequest(req, retry)
+	if err != nil {
+		return nil, err
+	}
+	if t.timeout <= 0 {
+		return t.base.RoundTrip(attempt)
+	}
+
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.base.RoundTrip(attempt.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// cancelOnClose releases a context when the body it guards is closed.
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	defer b.cancel()
+	return b.ReadCloser.Close()
+}
+
+// retryDelay reports whether the outcome of an attempt is worth retrying and
+// how long to wait before doing so.
+func (t *retryTransport) retryDelay(req *http.Request, resp *http.Response, err error, retry int) (time.Duration, bool) {
+	if err != nil {
+		t.logger.Warn("Request failed; retrying", "url", redactURL(req), "retry", retry+1, "error", err)
+		return t.policy.backoff(retry, t.jitter), true
+	}
+
+	if wait, limited := rateLimitWait(resp, t.now()); limited {
+		if !t.policy.WaitOnRateLimit {
+			t.logger.Error("GitHub rate limit exceeded", "url", redactURL(req), "retryAfter", wait)
+			return 0, false
+		}
+		if wait > t.policy.MaxWait {
+			t.logger.Error("GitHub rate limit exceeded; the reset is later than github.retry.maxWait", "url", redactURL(req), "retryAfter", wait, "maxWait", t.policy.MaxWait)
+			return 0, false
+		}
+		if wait <= 0 {
+			wait = t.policy.backoff(retry, t.jitter)
+		}
+		t.logger.Warn("GitHub rate limit exceeded; waiting", "url", redactURL(req), "retry", retry+1, "wait", wait)
+		return wait, true
+	}
+
+	switch resp.StatusCode {
+	case http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		t.logger.Warn("Server error; retrying", "url", redactURL(req), "status", resp.StatusCode, "retry", retry+1)
+		return t.policy.backoff(retry, t.jitter), true
+	default:
+		return 0, false
+	}
+}
+
+// rateLimitWait reports whether resp signals an exceeded primary or secondary
+// rate limit and how long to wait according to Retry-After or
+// X-RateLimit-Reset. A zero wait means that the headers do not tell.
+func rateLimitWait(resp *http.Response, now time.Time) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if value := resp.Header.Get("Retry-After"); value != "" {
+		if seconds, err := strconv.Atoi(value); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+		if at, err := http.ParseTime(value); err == nil {
+			return max(at.Sub(now), 0), true
+		}
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+			return max(time.Unix(reset, 0).Sub(now), 0), true
+		}
+		return 0, true
+	}
+
+	// A 403 without rate limit headers is a permission error.
+	return 0, resp.StatusCode == http.StatusTooManyRequests
+}
+
+// isRewindable reports whether the body of req can be sent again.
+func isRewindable(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// rewindRequest returns the request to send for the given retry, restoring
+// the body of requests that have one.
+func rewindRequest(req *http.Request, retry int) (*http.Request, error) {
+	if retry == 0 || req.Body == nil || req.Body == http.NoBody {
+		return req, nil
+	}
+	body, err := req.GetBody()
+	if err != nil {
+		return nil, fmt.Errorf("rewind request body: %w", err)
+	}
+	attempt := req.Clone(req.Context())
+	attempt.Body = body
+	return attempt, nil
+}
+
+// redactURL returns the request URL without its query, which may carry
+// credentials such as the JWT of a signed attachment URL.
+func redactURL(req *http.Request) string {
+	u := *req.URL
+	u.RawQuery = ""
+	return u.String()
+}
+
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
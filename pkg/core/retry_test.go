@@ -0,0 +1,311 @@
+package core
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// scriptedServer replies with the given responses in order and with the last
+// one once they run out.
+type scriptedResponse struct {
+	status  int
+	headers map[string]string
+	body    string
+}
+
+func newScriptedServer(t *testing.T, responses ...scriptedResponse) (*httptest.Server, *int) {
+	t.Helper()
+	calls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp := responses[min(calls, len(responses)-1)]
+		calls++
+		for key, value := range resp.headers {
+			w.Header().Set(key, value)
+		}
+		w.WriteHeader(resp.status)
+		_, _ = w.Write([]byte(resp.body))
+	}))
+	t.Cleanup(server.Close)
+	return server, &calls
+}
+
+func newTestRetryTransport(policy RetryPolicy, now time.Time, waits *[]time.Duration) *retryTransport {
+	transport := newRetryTransport(policy, http.DefaultTransport, 0, nil)
+	transport.now = func() time.Time { return now }
+	transport.jitter = func(time.Duration) time.Duration { return 0 }
+	transport.sleep = func(ctx context.Context, d time.Duration) error {
+		*waits = append(*waits, d)
+		return ctx.Err()
+	}
+	return transport
+}
+
+func TestRetryTransport(t *testing.T) {
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	resetIn := func(d time.Duration) string { return strconv.FormatInt(now.Add(d).Unix(), 10) }
+	policy := RetryPolicy{MaxRetries: 3, InitialDelay: 2 * time.Second, MaxDelay: 5 * time.Second, WaitOnRateLimit: true, MaxWait: 10 * time.Minute}
+	ok := scriptedResponse{status: http.StatusOK, body: "ok"}
+
+	tests := []struct {
+		name       string
+		policy     RetryPolicy
+		responses  []scriptedResponse
+		wantStatus int
+		wantCalls  int
+		wantWaits  []time.Duration
+	}{
+		{
+			name:       "server errors back off exponentially",
+			policy:     policy,
+			responses:  []scriptedResponse{{status: http.StatusBadGateway}, {status: http.StatusServiceUnavailable}, {status: http.StatusGatewayTimeout}, ok},
+			wantStatus: http.StatusOK,
+			wantCalls:  4,
+			wantWaits:  []time.Duration{time.Second, 2 * time.Second, 5 * time.Second / 2},
+		},
+		{
+			name:       "gives up after max retries",
+			policy:     policy,
+			responses:  []scriptedResponse{{status: http.StatusInternalServerError}},
+			wantStatus: http.StatusInternalServerError,
+			wantCalls:  4,
+			wantWaits:  []time.Duration{time.Second, 2 * time.Second, 5 * time.Second / 2},
+		},
+		{
+			name:       "429 honours Retry-After",
+			policy:     policy,
+			responses:  []scriptedResponse{{status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "7"}}, ok},
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantWaits:  []time.Duration{7 * time.Second},
+		},
+		{
+			name:       "429 without headers backs off",
+			policy:     policy,
+			responses:  []scriptedResponse{{status: http.StatusTooManyRequests}, ok},
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantWaits:  []time.Duration{time.Second},
+		},
+		{
+			name:   "primary rate limit waits for X-RateLimit-Reset",
+			policy: policy,
+			responses: []scriptedResponse{
+				{status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": resetIn(time.Minute)}},
+				ok,
+			},
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantWaits:  []time.Duration{time.Minute},
+		},
+		{
+			name:       "secondary rate limit honours Retry-After on 403",
+			policy:     policy,
+			responses:  []scriptedResponse{{status: http.StatusForbidden, headers: map[string]string{"Retry-After": "30"}}, ok},
+			wantStatus: http.StatusOK,
+			wantCalls:  2,
+			wantWaits:  []time.Duration{30 * time.Second},
+		},
+		{
+			name:       "403 without rate limit headers is not retried",
+			policy:     policy,
+			responses:  []scriptedResponse{{status: http.StatusForbidden}},
+			wantStatus: http.StatusForbidden,
+			wantCalls:  1,
+		},
+		{
+			name:       "404 is not retried",
+			policy:     policy,
+			responses:  []scriptedResponse{{status: http.StatusNotFound}},
+			wantStatus: http.StatusNotFound,
+			wantCalls:  1,
+		},
+		{
+			name:   "rate limit fails when configured",
+			policy: RetryPolicy{MaxRetries: 3, InitialDelay: time.Second, MaxDelay: time.Second, WaitOnRateLimit: false, MaxWait: time.Hour},
+			responses: []scriptedResponse{
+				{status: http.StatusTooManyRequests, headers: map[string]string{"Retry-After": "1"}},
+				ok,
+			},
+			wantStatus: http.StatusTooManyRequests,
+			wantCalls:  1,
+		},
+		{
+			name:   "rate limit fails when the reset is later than max wait",
+			policy: policy,
+			responses: []scriptedResponse{
+				{status: http.StatusForbidden, headers: map[string]string{"X-RateLimit-Remaining": "0", "X-RateLimit-Reset": resetIn(time.Hour)}},
+				ok,
+			},
+			wantStatus: http.StatusForbidden,
+			wantCalls:  1,
+		},
+		{
+			name:       "retries can be disabled",
+			policy:     RetryPolicy{MaxRetries: 0},
+			responses:  []scriptedResponse{{status: http.StatusBadGateway}, ok},
+			wantStatus: http.StatusBadGateway,
+			wantCalls:  1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, calls := newScriptedServer(t, tt.responses...)
+			var waits []time.Duration
+			client := &http.Client{Transport: newTestRetryTransport(tt.policy, now, &waits)}
+
+			resp, err := client.Get(server.URL)
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tt.wantStatus, resp.StatusCode)
+			assert.Equal(t, tt.wantCalls, *calls)
+			assertEqualCmp(t, tt.wantWaits, waits)
+		})
+	}
+}
+
+func TestRetryTransport_ReplaysRequestBody(t *testing.T) {
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if len(bodies) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	var waits []time.Duration
+	client := &http.Client{Transport: newTestRetryTransport(DefaultRetryPolicy(), time.Now(), &waits)}
+	resp, err := client.Post(server.URL, "application/json", strings.NewReader(`{"query": "{}"}`))
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	assertEqualCmp(t, []string{`{"query": "{}"}`, `{"query": "{}"}`}, bodies)
+}
+
+func TestRetryTransport_StopsWhenContextIsCanceled(t *testing.T) {
+	server, calls := newScriptedServer(t, scriptedResponse{status: http.StatusServiceUnavailable})
+	transport := newRetryTransport(RetryPolicy{MaxRetries: 3, InitialDelay: time.Hour, MaxDelay: time.Hour}, http.DefaultTransport, 0, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	transport.sleep = func(context.Context, time.Duration) error {
+		cancel()
+		return ctx.Err()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+
+	_, err = (&http.Client{Transport: transport}).Do(req)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, *calls)
+}
+
+func TestRetryTransport_AttemptTimeoutCoversBody(t *testing.T) {
+	server, _ := newScriptedServer(t, scriptedResponse{status: http.StatusOK, body: "image"})
+	transport := newRetryTransport(DefaultRetryPolicy(), http.DefaultTransport, time.Minute, nil)
+
+	resp, err := (&http.Client{Transport: transport}).Get(server.URL)
+	require.NoError(t, err)
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, "image", string(body))
+}
+
+func TestNewRetryPolicy(t *testing.T) {
+	assertEqualCmp(t, RetryPolicy{
+		MaxRetries:      config.DefaultMaxRetries,
+		InitialDelay:    config.DefaultRetryInitialDelay,
+		MaxDelay:        config.DefaultRetryMaxDelay,
+		WaitOnRateLimit: true,
+		MaxWait:         config.DefaultRateLimitMaxWait,
+	}, NewRetryPolicy(*config.NewConfig()))
+
+	conf := *config.NewConfig()
+	conf.GitHub.Retry = &config.GitHubRetryConfig{
+		MaxRetries:   Ptr(5),
+		InitialDelay: "500ms",
+		MaxDelay:     "1m",
+		OnRateLimit:  config.RateLimitFail,
+		MaxWait:      "1h",
+	}
+	assertEqualCmp(t, RetryPolicy{
+		MaxRetries:      5,
+		InitialDelay:    500 * time.Millisecond,
+		MaxDelay:        time.Minute,
+		WaitOnRateLimit: false,
+		MaxWait:         time.Hour,
+	}, NewRetryPolicy(conf))
+}
+
+// fastRetryPolicy retries without noticeable delays.
+var fastRetryPolicy = RetryPolicy{MaxRetries: 2, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond, WaitOnRateLimit: true, MaxWait: time.Second}
+
+func TestGitHubIssueRepository_ListIssues_Retries(t *testing.T) {
+	server, calls := newScriptedServer(t,
+		scriptedResponse{status: http.StatusBadGateway},
+		scriptedResponse{status: http.StatusForbidden, headers: map[string]string{"Retry-After": "0"}},
+		scriptedResponse{status: http.StatusOK, headers: map[string]string{"Content-Type": "application/json"}, body: `[{"number": 1, "title": "Recovered"}]`},
+	)
+	repo, err := newGitHubIssueRepository(StaticTokenSource("test-token"), server.URL+"/", "", fastRetryPolicy, nil)
+	require.NoError(t, err)
+
+	issues, err := repo.ListIssues(context.Background(), IssueListQuery{Username: "testuser", Repository: "testrepo"})
+	require.NoError(t, err)
+	require.Len(t, issues, 1)
+	assert.Equal(t, "Recovered", issues[0].GetTitle())
+	assert.Equal(t, 3, *calls)
+}
+
+func TestGitHubIssueRepository_ListIssues_RateLimitExceeded(t *testing.T) {
+	reset := time.Now().Add(time.Hour).Unix()
+	server, calls := newScriptedServer(t, scriptedResponse{
+		status: http.StatusForbidden,
+		headers: map[string]string{
+			"Content-Type":          "application/json",
+			"X-RateLimit-Limit":     "5000",
+			"X-RateLimit-Remaining": "0",
+			"X-RateLimit-Reset":     strconv.FormatInt(reset, 10),
+		},
+		body: `{"message": "API rate limit exceeded"}`,
+	})
+	repo, err := newGitHubIssueRepository(StaticTokenSource("test-token"), server.URL+"/", "", fastRetryPolicy, nil)
+	require.NoError(t, err)
+
+	_, err = repo.ListIssues(context.Background(), IssueListQuery{Username: "testuser", Repository: "testrepo"})
+	assert.ErrorContains(t, err, "GitHub API rate limit exceeded until "+time.Unix(reset, 0).Format(time.RFC3339))
+	// The reset is later than MaxWait, so the request fails right away.
+	assert.Equal(t, 1, *calls)
+}
+
+func TestHTTPImageRepository_Fetch_Retries(t *testing.T) {
+	server, calls := newScriptedServer(t,
+		scriptedResponse{status: http.StatusTooManyRequests},
+		scriptedResponse{status: http.StatusServiceUnavailable},
+		scriptedResponse{status: http.StatusOK, headers: map[string]string{"Content-Type": "image/png"}, body: "PNG"},
+	)
+	repo := newHTTPImageRepository(nil, defaultTrustedImageHosts, fastRetryPolicy, nil)
+
+	asset, err := repo.Fetch(context.Background(), NewImage(server.URL+"/image.png", "2021-01-01_000000", 0))
+	require.NoError(t, err)
+	defer asset.Body.Close()
+	body, err := io.ReadAll(asset.Body)
+	require.NoError(t, err)
+	assert.Equal(t, "PNG", string(body))
+	assert.Equal(t, 3, *calls)
+}
@@ -0,0 +1,186 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// maxSlugLength is the maximum number of characters in a slug, which keeps
+// file names well below the usual 255-byte limit for multi-byte titles.
+const maxSlugLength = 80
+
+// slugClass is the script of a run of slug characters. A hyphen is inserted
+// where the script changes, such as between Latin letters and romanized kana.
+type slugClass int
+
+const (
+	slugSeparator slugClass = iota
+	slugLatin
+	slugKana
+)
+
+// slugLatinLetters transliterates Latin letters that do not decompose into a
+// base letter and combining marks.
+var slugLatinLetters = map[rune]string{
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'ø': "o", 'đ': "d", 'ð': "d", 'ł': "l", 'þ': "th", 'ı': "i",
+}
+
+// slugify turns s into a lowercase path segment made of letters, digits and
+// hyphens. Latin letters lose their diacritics and kana are romanized with
+// Hepburn. Other letters such as kanji cannot be transliterated, so s has no
+// slug if it contains any; dropping them would give different titles the
+// same slug.
+func slugify(s string) string {
+	var b strings.Builder
+	length := 0
+	previous := slugSeparator
+	write := func(class slugClass, text string) {
+		if text == "" || length >= maxSlugLength {
+			return
+		}
+		if length > 0 && class != previous {
+			b.WriteByte('-')
+			length++
+		}
+		for _, r := range text {
+			if length >= maxSlugLength {
+				break
+			}
+			b.WriteRune(r)
+			length++
+		}
+		previous = class
+	}
+
+	runes := []rune(norm.NFKC.String(s))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case isKana(r):
+			end := i
+			for end < len(runes) && isKana(runes[end]) {
+				end++
+			}
+			write(slugKana, romanizeKana(runes[i:end]))
+			i = end - 1
+		case r == '\'' || r == '’':
+			// Keep words like "don't" together.
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			for _, d := range norm.NFD.String(string(unicode.ToLower(r))) {
+				switch {
+				case unicode.Is(unicode.Mn, d):
+				case d < unicode.MaxASCII:
+					write(slugLatin, string(d))
+				case slugLatinLetters[d] != "":
+					write(slugLatin, slugLatinLetters[d])
+				default:
+					return ""
+				}
+			}
+		default:
+			previous = slugSeparator
+		}
+	}
+	return strings.TrimRight(b.String(), "-")
+}
+
+// isKana reports whether r is a hiragana or katakana letter, or the long
+// vowel mark.
+func isKana(r rune) bool {
+	return (r >= 'ぁ' && r <= 'ゖ') || (r >= 'ァ' && r <= 'ヺ') || r == 'ー'
+}
+
+// hiraganaRomaji maps hiragana to their Hepburn romanization. Katakana are
+// looked up as the matching hiragana.
+var hiraganaRomaji = map[rune]string{
+	'あ': "a", 'い': "i", 'う': "u", 'え': "e", 'お': "o",
+	'か': "ka", 'き': "ki", 'く': "ku", 'け': "ke", 'こ': "ko",
+	'が': "ga", 'ぎ': "gi", 'ぐ': "gu", 'げ': "ge", 'ご': "go",
+	'さ': "sa", 'し': "shi", 'す': "su", 'せ': "se", 'そ': "so",
+	'ざ': "za", 'じ': "ji", 'ず': "zu", 'ぜ': "ze", 'ぞ': "zo",
+	'た': "ta", 'ち': "chi", 'つ': "tsu", 'て': "te", 'と': "to",
+	'だ': "da", 'ぢ': "ji", 'づ': "zu", 'で': "de", 'ど': "do",
+	'な': "na", 'に': "ni", 'ぬ': "nu", 'ね': "ne", 'の': "no",
+	'は': "ha", 'ひ': "hi", 'ふ': "fu", 'へ': "he", 'ほ': "ho",
+	'ば': "ba", 'び': "bi", 'ぶ': "bu", 'べ': "be", 'ぼ': "bo",
+	'ぱ': "pa", 'ぴ': "pi", 'ぷ': "pu", 'ぺ': "pe", 'ぽ': "po",
+	'ま': "ma", 'み': "mi", 'む': "mu", 'め': "me", 'も': "mo",
+	'や': "ya", 'ゆ': "yu", 'よ': "yo",
+	'ら': "ra", 'り': "ri", 'る': "ru", 'れ': "re", 'ろ': "ro",
+	'わ': "wa", 'ゐ': "i", 'ゑ': "e", 'を': "o", 'ん': "n",
+	'ゔ': "vu", 'ゕ': "ka", 'ゖ': "ke", 'ゎ': "wa",
+	'ヷ': "va", 'ヸ': "vi", 'ヹ': "ve", 'ヺ': "vo",
+}
+
+// smallKanaVowels are the vowels of small kana that combine with the
+// preceding kana, as in しゃ (sha) or ファ (fa).
+var smallKanaVowels = map[rune]string{
+	'ぁ': "a", 'ぃ': "i", 'ぅ': "u", 'ぇ': "e", 'ぉ': "o",
+	'ゃ': "a", 'ゅ': "u", 'ょ': "o",
+}
+
+// romanizeKana romanizes a run of kana with Hepburn: small ya, yu and yo
+// form combined syllables, the small tsu doubles the next consonant and the
+// long vowel mark repeats the previous vowel.
+func romanizeKana(run []rune) string {
+	var syllables []string
+	doubleNext := false
+	for _, r := range run {
+		if r >= 'ァ' && r <= 'ヶ' {
+			r -= 'ァ' - 'ぁ'
+		}
+		last := ""
+		if len(syllables) > 0 {
+			last = syllables[len(syllables)-1]
+		}
+
+		switch vowel, small := smallKanaVowels[r]; {
+		case r == 'っ':
+			doubleNext = true
+			continue
+		case r == 'ー':
+			if last != "" && strings.ContainsAny(last[len(last)-1:], "aiueo") {
+				syllables[len(syllables)-1] += last[len(last)-1:]
+			}
+			continue
+		case small && (r == 'ゃ' || r == 'ゅ' || r == 'ょ'):
+			if len(last) > 1 && strings.HasSuffix(last, "i") {
+				base := strings.TrimSuffix(last, "i")
+				if strings.HasSuffix(base, "sh") || strings.HasSuffix(base, "ch") || base == "j" {
+					syllables[len(syllables)-1] = base + vowel
+				} else {
+					syllables[len(syllables)-1] = base + "y" + vowel
+				}
+				continue
+			}
+			syllables = append(syllables, "y"+vowel)
+		case small:
+			switch {
+			case last == "u":
+				syllables[len(syllables)-1] = "w" + vowel
+				continue
+			case len(last) > 1 && strings.ContainsAny(last[len(last)-1:], "aiueo"):
+				syllables[len(syllables)-1] = last[:len(last)-1] + vowel
+				continue
+			}
+			syllables = append(syllables, vowel)
+		default:
+			syllable := hiraganaRomaji[r]
+			if syllable == "" {
+				continue
+			}
+			if doubleNext {
+				if strings.HasPrefix(syllable, "ch") {
+					syllable = "t" + syllable
+				} else if !strings.ContainsAny(syllable[:1], "aiueon") {
+					syllable = syllable[:1] + syllable
+				}
+			}
+			syllables = append(syllables, syllable)
+		}
+		doubleNext = false
+	}
+	return strings.Join(syllables, "")
+}
@@ -0,0 +1,50 @@
+package core
+
+import "testing"
+
+func TestSlugify(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"ascii", "Hello, World!", "hello-world"},
+		{"apostrophes", "Don't panic", "dont-panic"},
+		{"diacritics", "Café Crème à la Ünïcode", "cafe-creme-a-la-unicode"},
+		{"special latin letters", "Straße Ærø", "strasse-aero"},
+		{"full-width", "ＧｏでＣＬＩ", "go-de-cli"},
+		{"hiragana", "こんにちは", "konnichiha"},
+		{"katakana", "テスト", "tesuto"},
+		{"half-width katakana", "ﾃｽﾄ", "tesuto"},
+		{"youon", "きょうのしゃしん", "kyounoshashin"},
+		{"sokuon", "ちょっとまって", "chottomatte"},
+		{"sokuon before ch", "マッチャ", "matcha"},
+		{"long vowel mark", "サーバー", "saabaa"},
+		{"small vowels", "ファイルとパーティー", "fairutopaatii"},
+		{"latin and kana", "GoのテストとCLI", "go-notesutoto-cli"},
+		{"kanji", "Goの並行処理入門", ""},
+		{"kanji only", "日本語", ""},
+		{"other scripts", "Привет", ""},
+		{"symbols only", "!!!", ""},
+		{"separators", "  --Go__1.22--  ", "go-1-22"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertEqualCmp(t, tt.want, slugify(tt.input))
+		})
+	}
+}
+
+func TestSlugify_LimitsLength(t *testing.T) {
+	long := ""
+	for range 30 {
+		long += "テスト "
+	}
+	got := []rune(slugify(long))
+	if len(got) > maxSlugLength {
+		t.Fatalf("slug has %d characters, want at most %d", len(got), maxSlugLength)
+	}
+	if got[len(got)-1] == '-' {
+		t.Fatalf("slug %q ends with a hyphen", string(got))
+	}
+}
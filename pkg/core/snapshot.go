@@ -0,0 +1,466 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+)
+
+const (
+	// SnapshotVersion is the format version of the snapshots this build
+	// writes and reads.
+	SnapshotVersion = 1
+	// snapshotFilename is the file in a snapshot directory that holds
+	// everything but the image bytes.
+	snapshotFilename = "snapshot.json"
+	// snapshotAssetsDirectory is the directory in a snapshot directory that
+	// holds the image bytes, one file per URL.
+	snapshotAssetsDirectory = "assets"
+)
+
+// Snapshot is an offline copy of the issues, comments, rendered HTML, events,
+// permissions and images a generation run reads. Generating from a snapshot
+// with the configuration it was recorded with reproduces the articles of the
+// run without network access.
+type Snapshot struct {
+	Version   int       `json:"version"`
+	CreatedAt time.Time `json:"createdAt"`
+	// Repositories holds the recorded data by lower-cased "owner/repository".
+	Repositories map[string]*SnapshotRepository `json:"repositories"`
+	// Assets maps image URLs to the files in the assets directory.
+	Assets map[string]SnapshotAsset `json:"assets,omitempty"`
+
+	// dir is the directory the snapshot is stored in.
+	dir string
+}
+
+// SnapshotRepository holds the recorded data of one repository.
+type SnapshotRepository struct {
+	Issues   []*github.Issue                `json:"issues"`
+	Comments map[int][]*github.IssueComment `json:"comments,omitempty"`
+	// HTML holds rendered issues by issue number, suffixed with "+comments"
+	// when the rendered comments are included.
+	HTML        map[string]string            `json:"html,omitempty"`
+	Events      map[int][]*github.IssueEvent `json:"events,omitempty"`
+	Permissions map[string]string            `json:"permissions,omitempty"`
+}
+
+// SnapshotAsset is a recorded image.
+type SnapshotAsset struct {
+	File        string `json:"file"`
+	ContentType string `json:"contentType,omitempty"`
+}
+
+// NewSnapshot creates an empty snapshot stored in dir.
+func NewSnapshot(dir string) *Snapshot {
+	return &Snapshot{
+		Version:      SnapshotVersion,
+		CreatedAt:    time.Now().UTC(),
+		Repositories: map[string]*SnapshotRepository{},
+		Assets:       map[string]SnapshotAsset{},
+		dir:          dir,
+	}
+}
+
+// LoadSnapshot reads the snapshot stored in dir.
+func LoadSnapshot(dir string) (*Snapshot, error) {
+	data, err := os.ReadFile(filepath.Join(dir, snapshotFilename))
+	if err != nil {
+		return nil, fmt.Errorf("read snapshot: %w", err)
+	}
+	var snapshot Snapshot
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return nil, fmt.Errorf("parse snapshot %s: %w", dir, err)
+	}
+	if snapshot.Version != SnapshotVersion {
+		return nil, fmt.Errorf("unsupported snapshot version %d in %s", snapshot.Version, dir)
+	}
+	if snapshot.Repositories == nil {
+		snapshot.Repositories = map[string]*SnapshotRepository{}
+	}
+	snapshot.dir = dir
+	return &snapshot, nil
+}
+
+// Save writes the snapshot into its directory. Image bytes are written as
+// they are recorded.
+func (s *Snapshot) Save() error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode snapshot: %w", err)
+	}
+	if err := createDirectoryIfNotExist(s.dir); err != nil {
+		return fmt.Errorf("create snapshot directory: %w", err)
+	}
+	path := filepath.Join(s.dir, snapshotFilename)
+	if err := createFileAndWrite(path, string(data)+"\n"); err != nil {
+		return fmt.Errorf("write snapshot %s: %w", path, err)
+	}
+	return nil
+}
+
+// repository returns the recorded data of a repository, creating it when
+// create is set.
+func (s *Snapshot) repository(username, repository string, create bool) *SnapshotRepository {
+	key := strings.ToLower(username + "/" + repository)
+	repo := s.Repositories[key]
+	if repo == nil && create {
+		repo = &SnapshotRepository{}
+		s.Repositories[key] = repo
+	}
+	return repo
+}
+
+// recordAsset stores the bytes of an image under a name derived from its URL.
+func (s *Snapshot) recordAsset(url string, data []byte, contentType string) error {
+	sum := sha256.Sum256([]byte(url))
+	file := filepath.ToSlash(filepath.Join(snapshotAssetsDirectory, hex.EncodeToString(sum[:])))
+	if err := createDirectoryIfNotExist(filepath.Join(s.dir, snapshotAssetsDirectory)); err != nil {
+		return fmt.Errorf("create snapshot assets directory: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(s.dir, filepath.FromSlash(file)), data, 0o644); err != nil {
+		return fmt.Errorf("write snapshot asset: %w", err)
+	}
+	if s.Assets == nil {
+		s.Assets = map[string]SnapshotAsset{}
+	}
+	s.Assets[url] = SnapshotAsset{File: file, ContentType: contentType}
+	return nil
+}
+
+// NewArticleGeneratorFromSnapshot creates an ArticleGenerator that reads
+// issues and images from snapshot instead of GitHub and writes articles to
+// the filesystem. output.publish is evaluated at the time the snapshot was
+// recorded, so that replaying it later gives the same articles.
+func NewArticleGeneratorFromSnapshot(conf config.Config, snapshot *Snapshot, logger *slog.Logger) *ArticleGenerator {
+	articleRepo := NewFileSystemArticleRepositoryWithLogger(NewSnapshotAssetFetcher(snapshot), logger)
+	gen := newArticleGenerator(conf, NewSnapshotIssueStore(snapshot), articleRepo, logger)
+	gen.now = snapshot.now
+	return gen
+}
+
+// NewSnapshotRecorder creates an ArticleGenerator that reads issues and
+// images from GitHub like NewArticleGeneratorWithTokenSource, but records
+// them into snapshot instead of writing articles. Call Generate for every
+// source and then snapshot.Save.
+func NewSnapshotRecorder(conf config.Config, source, attachmentSource TokenSource, snapshot *Snapshot, logger *slog.Logger) (*ArticleGenerator, error) {
+	if source == nil {
+		return nil, fmt.Errorf("GitHub token is required")
+	}
+	issueRepo, err := newIssueStore(conf, source, logger)
+	if err != nil {
+		return nil, err
+	}
+	if attachmentSource == nil {
+		attachmentSource = source
+	}
+	recorder := &snapshotRecorder{
+		issues:   issueRepo,
+		assets:   newHTTPImageRepository(attachmentSource, trustedImageHosts(conf), NewRetryPolicy(conf), logger),
+		snapshot: snapshot,
+	}
+	articleRepo := snapshotArticleStore{assets: recorder, logger: defaultLogger(logger)}
+	gen := newArticleGenerator(conf, recorder, articleRepo, logger)
+	gen.now = snapshot.now
+	return gen, nil
+}
+
+// now returns the time the snapshot was recorded. Generators recording or
+// replaying the snapshot evaluate output.publish at this time.
+func (s *Snapshot) now() time.Time {
+	return s.CreatedAt
+}
+
+func snapshotHTMLKey(number int, withComments bool) string {
+	key := strconv.Itoa(number)
+	if withComments {
+		key += "+comments"
+	}
+	return key
+}
+
+// SnapshotIssueStore serves issues from a snapshot. It supports everything
+// the GitHub issue stores do, limited to the data that was recorded.
+type SnapshotIssueStore struct {
+	snapshot *Snapshot
+}
+
+// NewSnapshotIssueStore creates an issue store that reads from snapshot.
+func NewSnapshotIssueStore(snapshot *Snapshot) *SnapshotIssueStore {
+	return &SnapshotIssueStore{snapshot: snapshot}
+}
+
+// ListIssues returns the recorded issues that match the query.
+func (s *SnapshotIssueStore) ListIssues(ctx context.Context, query IssueListQuery) ([]*github.Issue, error) {
+	repo := s.snapshot.repository(query.Username, query.Repository, false)
+	if repo == nil {
+		return nil, fmt.Errorf("repository %s/%s is not in the snapshot", query.Username, query.Repository)
+	}
+	var issues []*github.Issue
+	for _, issue := range repo.Issues {
+		if !query.Since.IsZero() && issue.GetUpdatedAt().Time.Before(query.Since) {
+			continue
+		}
+		if issueMatchesQuery(issue, query) {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// GetIssue returns a recorded issue. Issues that were not recorded are
+// reported as not found.
+func (s *SnapshotIssueStore) GetIssue(ctx context.Context, username, repository string, number int) (*github.Issue, error) {
+	if repo := s.snapshot.repository(username, repository, false); repo != nil {
+		for _, issue := range repo.Issues {
+			if issue.GetNumber() == number {
+				return issue, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("issue #%d: %w", number, ErrIssueNotFound)
+}
+
+// ListComments returns the recorded comments of an issue.
+func (s *SnapshotIssueStore) ListComments(ctx context.Context, username, repository string, number int) ([]*github.IssueComment, error) {
+	repo := s.snapshot.repository(username, repository, false)
+	if repo == nil || repo.Comments[number] == nil {
+		return nil, fmt.Errorf("comments of issue #%d are not in the snapshot", number)
+	}
+	return repo.Comments[number], nil
+}
+
+// GetIssueHTML returns the recorded rendered HTML of an issue.
+func (s *SnapshotIssueStore) GetIssueHTML(ctx context.Context, username, repository string, number int, withComments bool) (string, error) {
+	repo := s.snapshot.repository(username, repository, false)
+	if repo == nil {
+		return "", fmt.Errorf("rendered issue #%d is not in the snapshot", number)
+	}
+	html, ok := repo.HTML[snapshotHTMLKey(number, withComments)]
+	if !ok {
+		return "", fmt.Errorf("rendered issue #%d is not in the snapshot", number)
+	}
+	return html, nil
+}
+
+// GetPermission returns the recorded permission of a user.
+func (s *SnapshotIssueStore) GetPermission(ctx context.Context, username, repository, user string) (string, error) {
+	repo := s.snapshot.repository(username, repository, false)
+	if repo == nil {
+		return "", fmt.Errorf("permission of %s is not in the snapshot", user)
+	}
+	permission, ok := repo.Permissions[strings.ToLower(user)]
+	if !ok {
+		return "", fmt.Errorf("permission of %s is not in the snapshot", user)
+	}
+	return permission, nil
+}
+
+// ListIssueEvents returns the recorded events of an issue.
+func (s *SnapshotIssueStore) ListIssueEvents(ctx context.Context, username, repository string, number int) ([]*github.IssueEvent, error) {
+	repo := s.snapshot.repository(username, repository, false)
+	if repo == nil || repo.Events[number] == nil {
+		return nil, fmt.Errorf("events of issue #%d are not in the snapshot", number)
+	}
+	return repo.Events[number], nil
+}
+
+// SnapshotAssetFetcher serves images from a snapshot.
+type SnapshotAssetFetcher struct {
+	snapshot *Snapshot
+}
+
+// NewSnapshotAssetFetcher creates an AssetFetcher that reads from snapshot.
+func NewSnapshotAssetFetcher(snapshot *Snapshot) AssetFetcher {
+	return &SnapshotAssetFetcher{snapshot: snapshot}
+}
+
+// Fetch opens the recorded bytes of the image. Images are looked up by the
+// URL in the issue, so signed download URLs need not match.
+func (f *SnapshotAssetFetcher) Fetch(ctx context.Context, image *Image) (*ImageAsset, error) {
+	asset, ok := f.snapshot.Assets[image.URL]
+	if !ok {
+		return nil, fmt.Errorf("image %s is not in the snapshot", image.URL)
+	}
+	body, err := os.Open(filepath.Join(f.snapshot.dir, filepath.FromSlash(asset.File)))
+	if err != nil {
+		return nil, fmt.Errorf("open snapshot asset: %w", err)
+	}
+	return &ImageAsset{Body: body, ContentType: asset.ContentType}, nil
+}
+
+// snapshotRecorder wraps the GitHub issue store and image fetcher of a run
+// and records every response into a snapshot.
+type snapshotRecorder struct {
+	issues   IssueStore
+	assets   AssetFetcher
+	snapshot *Snapshot
+}
+
+// ListIssues lists issues and records them.
+func (r *snapshotRecorder) ListIssues(ctx context.Context, query IssueListQuery) ([]*github.Issue, error) {
+	issues, err := r.issues.ListIssues(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	repo := r.snapshot.repository(query.Username, query.Repository, true)
+	for _, issue := range issues {
+		repo.addIssue(issue)
+	}
+	return issues, nil
+}
+
+// GetIssue fetches an issue and records it.
+func (r *snapshotRecorder) GetIssue(ctx context.Context, username, repository string, number int) (*github.Issue, error) {
+	issue, err := r.issues.GetIssue(ctx, username, repository, number)
+	if err != nil {
+		return nil, err
+	}
+	r.snapshot.repository(username, repository, true).addIssue(issue)
+	return issue, nil
+}
+
+// ListComments lists the comments of an issue and records them.
+func (r *snapshotRecorder) ListComments(ctx context.Context, username, repository string, number int) ([]*github.IssueComment, error) {
+	store, ok := r.issues.(CommentStore)
+	if !ok {
+		return nil, fmt.Errorf("the configured issue store does not support comments")
+	}
+	comments, err := store.ListComments(ctx, username, repository, number)
+	if err != nil {
+		return nil, err
+	}
+	if comments == nil {
+		comments = []*github.IssueComment{}
+	}
+	repo := r.snapshot.repository(username, repository, true)
+	if repo.Comments == nil {
+		repo.Comments = map[int][]*github.IssueComment{}
+	}
+	repo.Comments[number] = comments
+	return comments, nil
+}
+
+// GetIssueHTML fetches the rendered HTML of an issue and records it.
+func (r *snapshotRecorder) GetIssueHTML(ctx context.Context, username, repository string, number int, withComments bool) (string, error) {
+	store, ok := r.issues.(RenderedIssueStore)
+	if !ok {
+		return "", fmt.Errorf("the configured issue store cannot render issues")
+	}
+	html, err := store.GetIssueHTML(ctx, username, repository, number, withComments)
+	if err != nil {
+		return "", err
+	}
+	repo := r.snapshot.repository(username, repository, true)
+	if repo.HTML == nil {
+		repo.HTML = map[string]string{}
+	}
+	repo.HTML[snapshotHTMLKey(number, withComments)] = html
+	return html, nil
+}
+
+// GetPermission fetches the permission of a user and records it.
+func (r *snapshotRecorder) GetPermission(ctx context.Context, username, repository, user string) (string, error) {
+	store, ok := r.issues.(PermissionStore)
+	if !ok {
+		return "", fmt.Errorf("the configured issue store cannot check permissions")
+	}
+	permission, err := store.GetPermission(ctx, username, repository, user)
+	if err != nil {
+		return "", err
+	}
+	repo := r.snapshot.repository(username, repository, true)
+	if repo.Permissions == nil {
+		repo.Permissions = map[string]string{}
+	}
+	repo.Permissions[strings.ToLower(user)] = permission
+	return permission, nil
+}
+
+// ListIssueEvents lists the events of an issue and records them.
+func (r *snapshotRecorder) ListIssueEvents(ctx context.Context, username, repository string, number int) ([]*github.IssueEvent, error) {
+	store, ok := r.issues.(IssueEventStore)
+	if !ok {
+		return nil, fmt.Errorf("the configured issue store cannot list issue events")
+	}
+	events, err := store.ListIssueEvents(ctx, username, repository, number)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []*github.IssueEvent{}
+	}
+	repo := r.snapshot.repository(username, repository, true)
+	if repo.Events == nil {
+		repo.Events = map[int][]*github.IssueEvent{}
+	}
+	repo.Events[number] = events
+	return events, nil
+}
+
+// Fetch downloads an image and records its bytes.
+func (r *snapshotRecorder) Fetch(ctx context.Context, image *Image) (*ImageAsset, error) {
+	asset, err := r.assets.Fetch(ctx, image)
+	if err != nil {
+		return nil, err
+	}
+	defer asset.Body.Close()
+	data, err := io.ReadAll(asset.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read image %s: %w", image.URL, err)
+	}
+	if err := r.snapshot.recordAsset(image.URL, data, asset.ContentType); err != nil {
+		return nil, err
+	}
+	return &ImageAsset{Body: io.NopCloser(bytes.NewReader(data)), ContentType: asset.ContentType}, nil
+}
+
+// addIssue records an issue, replacing an earlier version of it.
+func (r *SnapshotRepository) addIssue(issue *github.Issue) {
+	for i, recorded := range r.Issues {
+		if recorded.GetNumber() == issue.GetNumber() {
+			r.Issues[i] = issue
+			return
+		}
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// snapshotArticleStore stands in for the article store while recording. It
+// downloads the images of each article so that they are recorded, but
+// writes no files. Like FileSystemArticleRepository, it logs images that
+// fail to download and continues, so that generating from the snapshot
+// reproduces the same output.
+type snapshotArticleStore struct {
+	assets AssetFetcher
+	logger *slog.Logger
+}
+
+// Save downloads the images of the article.
+func (s snapshotArticleStore) Save(ctx context.Context, article *Article, _ config.Config) (*ArticleOutput, error) {
+	for _, image := range article.Images {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		asset, err := s.assets.Fetch(ctx, image)
+		if err != nil {
+			s.logger.Error("Failed to download image", "url", image.URL, "error", err)
+			continue
+		}
+		_ = asset.Body.Close()
+	}
+	return &ArticleOutput{}, nil
+}
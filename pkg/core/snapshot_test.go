@@ -0,0 +1,183 @@
+package core
+
+import (
+	"context"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func snapshotTestConfig(dir string) config.Config {
+	conf := *config.NewConfig()
+	conf.Output.Articles.Directory = filepath.Join(dir, "content")
+	conf.Output.Articles.Filename = "%Y-%m-%d.md"
+	conf.Output.Images.Directory = filepath.Join(dir, "images")
+	conf.Output.Images.Filename = "[:id]"
+	conf.Output.Comments = &config.OutputCommentsConfig{Mode: config.CommentsModeAppend}
+	conf.GitHub.Trust = &config.GitHubTrustConfig{Untrusted: config.UntrustedDraft, CheckPermission: true}
+	return conf
+}
+
+func snapshotTestIssueStore() *stubPermissionIssueStore {
+	const image = "https://github.com/user-attachments/assets/0f1e2d3c-aaaa-bbbb-cccc-1234567890ab"
+	return &stubPermissionIssueStore{
+		stubIssueStore: stubIssueStore{
+			issues: []*github.Issue{
+				{
+					Number: Ptr(1), Title: Ptr("With image"), Body: Ptr("![image](" + image + ")"), State: Ptr("closed"),
+					User: &github.User{Login: Ptr("owner")}, AuthorAssociation: Ptr("OWNER"), Comments: Ptr(1),
+					CreatedAt: parseTime("2024-01-01T00:00:00Z"), UpdatedAt: parseTime("2024-01-05T00:00:00Z"),
+				},
+				{
+					Number: Ptr(2), Title: Ptr("From a guest"), Body: Ptr("Hello"), State: Ptr("closed"),
+					User: &github.User{Login: Ptr("guest")}, AuthorAssociation: Ptr("NONE"),
+					CreatedAt: parseTime("2024-01-02T00:00:00Z"), UpdatedAt: parseTime("2024-01-03T00:00:00Z"),
+				},
+			},
+			comments: map[int][]*github.IssueComment{
+				1: {{Body: Ptr("Nice"), User: &github.User{Login: Ptr("reader")}, CreatedAt: parseTime("2024-01-04T00:00:00Z")}},
+			},
+		},
+		permissions: map[string]string{"guest": PermissionRead},
+	}
+}
+
+// readTree returns the files below dir by their path relative to dir.
+func readTree(t *testing.T, dir string) map[string]string {
+	t.Helper()
+	files := map[string]string{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[rel] = string(data)
+		return nil
+	})
+	require.NoError(t, err)
+	return files
+}
+
+func TestSnapshot_ReproducesGeneratedArticles(t *testing.T) {
+	ctx := context.Background()
+	images := &fakeImageRepository{contentType: "image/png", body: "png-bytes"}
+
+	// Generate from the live stores.
+	liveDir := t.TempDir()
+	liveConf := snapshotTestConfig(liveDir)
+	live := newArticleGenerator(liveConf, snapshotTestIssueStore(), NewFileSystemArticleRepository(images), slog.Default())
+	liveCount, err := live.Generate(ctx, "testuser", "testrepo")
+	require.NoError(t, err)
+
+	// Record a snapshot from the same stores.
+	snapshotDir := filepath.Join(t.TempDir(), "snapshot")
+	snapshot := NewSnapshot(snapshotDir)
+	recorder := &snapshotRecorder{issues: snapshotTestIssueStore(), assets: images, snapshot: snapshot}
+	recording := newArticleGenerator(liveConf, recorder, snapshotArticleStore{assets: recorder, logger: slog.Default()}, slog.Default())
+	_, err = recording.Generate(ctx, "testuser", "testrepo")
+	require.NoError(t, err)
+	require.NoError(t, snapshot.Save())
+
+	// Generate from the snapshot alone.
+	loaded, err := LoadSnapshot(snapshotDir)
+	require.NoError(t, err)
+	replayDir := t.TempDir()
+	replay := NewArticleGeneratorFromSnapshot(snapshotTestConfig(replayDir), loaded, slog.Default())
+	replayCount, err := replay.Generate(ctx, "TestUser", "testrepo")
+	require.NoError(t, err)
+
+	assertEqualCmp(t, liveCount, replayCount)
+	liveFiles := readTree(t, liveDir)
+	assertEqualCmp(t, liveFiles, readTree(t, replayDir))
+	assert.Len(t, liveFiles, 3, "two articles and one image")
+}
+
+func TestSnapshot_ReplaysAtTheRecordingTime(t *testing.T) {
+	snapshot := NewSnapshot(t.TempDir())
+	snapshot.CreatedAt = time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+	snapshot.Repositories["testuser/testrepo"] = &SnapshotRepository{Issues: []*github.Issue{{
+		Number: Ptr(1), Title: Ptr("Limited offer"), Body: Ptr("---\nexpiryDate: 2024-03-01\n---\nBody"), State: Ptr("closed"),
+		User: &github.User{Login: Ptr("owner")}, AuthorAssociation: Ptr("OWNER"), CreatedAt: parseTime("2024-01-01T00:00:00Z"),
+	}}}
+	dir := t.TempDir()
+	conf := snapshotTestConfig(dir)
+	conf.Output.Publish = &config.OutputPublishConfig{Expired: config.PublishSkip}
+
+	// The expiry date has passed by now, but not when the snapshot was taken.
+	count, err := NewArticleGeneratorFromSnapshot(conf, snapshot, slog.Default()).Generate(context.Background(), "testuser", "testrepo")
+	require.NoError(t, err)
+	assertEqualCmp(t, 1, count)
+	assert.FileExists(t, filepath.Join(dir, "content", "2024-01-01.md"))
+}
+
+func TestSnapshotIssueStore(t *testing.T) {
+	snapshot := NewSnapshot(t.TempDir())
+	recorder := &snapshotRecorder{issues: snapshotTestIssueStore(), snapshot: snapshot}
+	ctx := context.Background()
+	_, err := recorder.ListIssues(ctx, IssueListQuery{Username: "testuser", Repository: "testrepo"})
+	require.NoError(t, err)
+	_, err = recorder.ListComments(ctx, "testuser", "testrepo", 1)
+	require.NoError(t, err)
+	_, err = recorder.GetPermission(ctx, "testuser", "testrepo", "guest")
+	require.NoError(t, err)
+	store := NewSnapshotIssueStore(snapshot)
+
+	t.Run("lists issues matching the query", func(t *testing.T) {
+		issues, err := store.ListIssues(ctx, IssueListQuery{Username: "testuser", Repository: "testrepo", Since: parseTime("2024-01-04T00:00:00Z").Time})
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assertEqualCmp(t, 1, issues[0].GetNumber())
+
+		issues, err = store.ListIssues(ctx, IssueListQuery{Username: "testuser", Repository: "testrepo", Filter: IssueFilter{Authors: []string{"guest"}}})
+		require.NoError(t, err)
+		require.Len(t, issues, 1)
+		assertEqualCmp(t, 2, issues[0].GetNumber())
+	})
+
+	t.Run("gets issues", func(t *testing.T) {
+		issue, err := store.GetIssue(ctx, "testuser", "testrepo", 2)
+		require.NoError(t, err)
+		assertEqualCmp(t, "From a guest", issue.GetTitle())
+
+		_, err = store.GetIssue(ctx, "testuser", "testrepo", 3)
+		assert.ErrorIs(t, err, ErrIssueNotFound)
+	})
+
+	t.Run("serves recorded data only", func(t *testing.T) {
+		comments, err := store.ListComments(ctx, "testuser", "testrepo", 1)
+		require.NoError(t, err)
+		assert.Len(t, comments, 1)
+		permission, err := store.GetPermission(ctx, "testuser", "testrepo", "Guest")
+		require.NoError(t, err)
+		assertEqualCmp(t, PermissionRead, permission)
+
+		_, err = store.ListComments(ctx, "testuser", "testrepo", 2)
+		assert.ErrorContains(t, err, "not in the snapshot")
+		_, err = store.ListIssueEvents(ctx, "testuser", "testrepo", 1)
+		assert.ErrorContains(t, err, "not in the snapshot")
+		_, err = store.ListIssues(ctx, IssueListQuery{Username: "testuser", Repository: "other"})
+		assert.ErrorContains(t, err, "not in the snapshot")
+	})
+}
+
+func TestLoadSnapshot_RejectsUnknownVersion(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, snapshotFilename), []byte(`{"version": 99}`), 0o644))
+	_, err := LoadSnapshot(dir)
+	assert.ErrorContains(t, err, "unsupported snapshot version 99")
+}
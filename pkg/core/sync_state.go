@@ -0,0 +1,202 @@
+package core
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"gopkg.in/yaml.v3"
+)
+
+// SyncStateFilename is the name of the incremental sync state file inside the
+// configured state directory.
+const SyncStateFilename = "sync.json"
+
+// syncClockSkew is subtracted from the recorded sync time so that issues
+// updated while a run was in progress, or under a slightly skewed local clock,
+// are fetched again on the next run. Re-fetched issues whose updated_at did
+// not change are skipped by the per-issue check.
+const syncClockSkew = time.Minute
+
+// SyncState records what a previous generate run has already converted so
+// that later runs only process issues that changed since then.
+type SyncState struct {
+	// Fingerprint identifies the configuration used for the recorded run.
+	// A different configuration invalidates the state.
+	Fingerprint string `json:"fingerprint,omitempty"`
+	// LastSyncedAt is passed to the GitHub API as the since parameter.
+	LastSyncedAt time.Time `json:"lastSyncedAt,omitzero"`
+	// Cursors replaces LastSyncedAt when github.sources is set and maps each
+	// source repository ("owner/repository") to its last successful run.
+	Cursors map[string]time.Time `json:"cursors,omitempty"`
+	// Issues maps an issue key to the updated_at of its last saved version.
+	Issues map[string]time.Time `json:"issues"`
+	// Pending maps an issue key to the time its publishDate or expiryDate
+	// passes and changes what output.publish does with it. Such issues are
+	// processed again then, even though they were not updated.
+	Pending map[string]time.Time `json:"pending,omitempty"`
+}
+
+// NewSyncState creates an empty SyncState that makes the next run a full sync.
+func NewSyncState() *SyncState {
+	return &SyncState{Issues: map[string]time.Time{}}
+}
+
+// SyncStatePath returns the path of the sync state file for the configuration.
+func SyncStatePath(conf config.Config) string {
+	return filepath.Join(conf.Output.StateDirectory(), SyncStateFilename)
+}
+
+// LoadSyncState reads a sync state file. A missing file yields an empty state.
+func LoadSyncState(path string) (*SyncState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return NewSyncState(), nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read sync state %s: %w", path, err)
+	}
+
+	state := NewSyncState()
+	if err := json.Unmarshal(data, state); err != nil {
+		return nil, fmt.Errorf("parse sync state %s: %w", path, err)
+	}
+	if state.Issues == nil {
+		state.Issues = map[string]time.Time{}
+	}
+	return state, nil
+}
+
+// Save writes the sync state to path, creating parent directories as needed.
+func (s *SyncState) Save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode sync state: %w", err)
+	}
+	if err := createDirectoryIfNotExist(filepath.Dir(path)); err != nil {
+		return fmt.Errorf("create sync state directory: %w", err)
+	}
+	if err := createFileAndWrite(path, string(data)+"\n"); err != nil {
+		return fmt.Errorf("write sync state %s: %w", path, err)
+	}
+	return nil
+}
+
+// reconcile resets the state when it was recorded with another configuration.
+// It reports whether the state was reset.
+func (s *SyncState) reconcile(fingerprint string) bool {
+	if s.Fingerprint == fingerprint {
+		return false
+	}
+	reset := s.Fingerprint != "" || !s.LastSyncedAt.IsZero() || len(s.Cursors) > 0 || len(s.Issues) > 0 || len(s.Pending) > 0
+	s.Fingerprint = fingerprint
+	s.LastSyncedAt = time.Time{}
+	s.Cursors = nil
+	s.Issues = map[string]time.Time{}
+	s.Pending = nil
+	return reset
+}
+
+// since returns the value for the API's since parameter. An empty source
+// selects LastSyncedAt, any other the cursor of that source repository.
+func (s *SyncState) since(source string) time.Time {
+	last := s.LastSyncedAt
+	if source != "" {
+		last = s.Cursors[source]
+	}
+	if last.IsZero() {
+		return time.Time{}
+	}
+	return last.Add(-syncClockSkew)
+}
+
+// advance records a successful run that started at the given time. An empty
+// source sets LastSyncedAt, any other the cursor of that source repository.
+func (s *SyncState) advance(source string, startedAt time.Time) {
+	if source == "" {
+		s.LastSyncedAt = startedAt
+		return
+	}
+	if s.Cursors == nil {
+		s.Cursors = map[string]time.Time{}
+	}
+	s.Cursors[source] = startedAt
+}
+
+// isUpToDate reports whether the issue's current version was already saved
+// under key.
+func (s *SyncState) isUpToDate(key string, issue *github.Issue) bool {
+	synced, ok := s.Issues[key]
+	if !ok || issue.UpdatedAt == nil {
+		return false
+	}
+	return !issue.GetUpdatedAt().After(synced)
+}
+
+// markSynced records the issue's current version as saved under key.
+func (s *SyncState) markSynced(key string, issue *github.Issue) {
+	if issue.UpdatedAt == nil {
+		return
+	}
+	s.Issues[key] = issue.GetUpdatedAt().UTC()
+}
+
+// setPending records that the issue under key has to be processed again at
+// the given time. The zero time forgets the issue.
+func (s *SyncState) setPending(key string, at time.Time) {
+	if at.IsZero() {
+		delete(s.Pending, key)
+		return
+	}
+	if s.Pending == nil {
+		s.Pending = map[string]time.Time{}
+	}
+	s.Pending[key] = at.UTC()
+}
+
+// due returns the issue numbers of the source whose pending time is at or
+// before now.
+func (s *SyncState) due(src issueSource, now time.Time) []int {
+	var numbers []int
+	for key, at := range s.Pending {
+		if at.After(now) {
+			continue
+		}
+		if number, ok := src.numberOf(key); ok {
+			numbers = append(numbers, number)
+		}
+	}
+	slices.Sort(numbers)
+	return numbers
+}
+
+// numberKey is the key of an issue when issues are read from a single
+// repository.
+func numberKey(number int) string {
+	return strconv.Itoa(number)
+}
+
+// sourceKey is the key of an issue when github.sources is set, which keeps
+// issues with the same number in different repositories apart.
+func sourceKey(source string, number int) string {
+	return source + "#" + strconv.Itoa(number)
+}
+
+// configFingerprint hashes the configuration so that state recorded with
+// different output settings is not reused.
+func configFingerprint(conf config.Config) string {
+	data, err := yaml.Marshal(conf)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,88 @@
+package core
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-github/v86/github"
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadSyncState_MissingFileReturnsEmptyState(t *testing.T) {
+	state, err := LoadSyncState(filepath.Join(t.TempDir(), "missing.json"))
+	require.NoError(t, err)
+	assert.True(t, state.LastSyncedAt.IsZero())
+	assert.Empty(t, state.Issues)
+	assert.NotNil(t, state.Issues)
+}
+
+func TestSyncState_SaveAndLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), ".gic", SyncStateFilename)
+	state := NewSyncState()
+	state.Fingerprint = "abc"
+	state.LastSyncedAt = time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	state.markSynced(numberKey(7), &github.Issue{Number: github.Ptr(7), UpdatedAt: generatorParseTime("2024-01-01T00:00:00Z")})
+
+	require.NoError(t, state.Save(path))
+	loaded, err := LoadSyncState(path)
+	require.NoError(t, err)
+	assertEqualCmp(t, state, loaded)
+}
+
+func TestLoadSyncState_InvalidJSON(t *testing.T) {
+	path := filepath.Join(t.TempDir(), SyncStateFilename)
+	require.NoError(t, os.WriteFile(path, []byte("{"), 0o644))
+
+	_, err := LoadSyncState(path)
+	assert.ErrorContains(t, err, "parse sync state")
+}
+
+func TestSyncState_IsUpToDate(t *testing.T) {
+	state := NewSyncState()
+	issue := &github.Issue{Number: github.Ptr(1), UpdatedAt: generatorParseTime("2024-01-01T00:00:00Z")}
+	assert.False(t, state.isUpToDate(numberKey(issue.GetNumber()), issue))
+
+	state.markSynced(numberKey(issue.GetNumber()), issue)
+	assert.True(t, state.isUpToDate(numberKey(issue.GetNumber()), issue))
+
+	updated := &github.Issue{Number: github.Ptr(1), UpdatedAt: generatorParseTime("2024-01-02T00:00:00Z")}
+	assert.False(t, state.isUpToDate(numberKey(updated.GetNumber()), updated))
+
+	assert.False(t, state.isUpToDate(numberKey(1), &github.Issue{Number: github.Ptr(1)}), "issues without updated_at are always processed")
+}
+
+func TestSyncState_Reconcile(t *testing.T) {
+	state := NewSyncState()
+	assert.False(t, state.reconcile("first"), "an empty state is not reported as reset")
+
+	state.LastSyncedAt = time.Now()
+	state.Issues["1"] = time.Now()
+	assert.False(t, state.reconcile("first"))
+	assert.Len(t, state.Issues, 1)
+
+	assert.True(t, state.reconcile("second"))
+	assert.True(t, state.LastSyncedAt.IsZero())
+	assert.Empty(t, state.Issues)
+	assertEqualCmp(t, "second", state.Fingerprint)
+}
+
+func TestSyncState_SinceAppliesClockSkew(t *testing.T) {
+	state := NewSyncState()
+	assert.True(t, state.since("").IsZero())
+
+	state.LastSyncedAt = time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	assertEqualCmp(t, time.Date(2024, 1, 1, 11, 59, 0, 0, time.UTC), state.since(""))
+}
+
+func TestConfigFingerprint_ChangesWithOutputSettings(t *testing.T) {
+	conf := *config.NewConfig()
+	before := configFingerprint(conf)
+	assertEqualCmp(t, before, configFingerprint(*config.NewConfig()))
+
+	conf.Output.Articles.Filename = "index.md"
+	assert.NotEqual(t, before, configFingerprint(conf))
+}
@@ -0,0 +1,36 @@
+package core
+
+import (
+	"context"
+)
+
+// IssueWatcher reports cheaply whether the issues of a repository changed.
+// Issue stores implement it to support generate --watch.
+type IssueWatcher interface {
+	// IssuesChanged reports whether the issues matching query changed since
+	// the previous call with the same query. It may also report changes of
+	// issues that do not match query, such as an issue whose label was just
+	// removed. The first call reports a change.
+	IssuesChanged(ctx context.Context, query IssueListQuery) (bool, error)
+}
+
+// IssuesChanged reports whether the issues of the given repository that the
+// configuration selects changed since the previous call, or whether the
+// publishDate or expiryDate of one of them passed. Issue stores that cannot
+// tell always report a change, so that every poll falls back to an
+// incremental run.
+func (g *ArticleGenerator) IssuesChanged(ctx context.Context, username, repository string) (bool, error) {
+	src, err := g.source(username, repository)
+	if err != nil {
+		return false, err
+	}
+	watcher, ok := g.issueRepo.(IssueWatcher)
+	if !ok {
+		return true, nil
+	}
+	changed, err := watcher.IssuesChanged(ctx, src.listQuery())
+	if err != nil {
+		return false, err
+	}
+	return changed || (g.syncState != nil && len(g.syncState.due(src, g.currentTime())) > 0), nil
+}
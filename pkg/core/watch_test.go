@@ -0,0 +1,47 @@
+package core
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+
+	"github.com/rokuosan/github-issue-cms/pkg/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubIssueWatcher reports the configured change and records its queries.
+type stubIssueWatcher struct {
+	stubIssueStore
+	changed bool
+	queries []IssueListQuery
+}
+
+func (s *stubIssueWatcher) IssuesChanged(ctx context.Context, query IssueListQuery) (bool, error) {
+	s.queries = append(s.queries, query)
+	return s.changed, nil
+}
+
+func TestArticleGenerator_IssuesChanged(t *testing.T) {
+	conf := *config.NewConfig()
+	conf.GitHub.Labels = []string{"blog"}
+
+	t.Run("asks the issue store", func(t *testing.T) {
+		watcher := &stubIssueWatcher{}
+		gen := newArticleGenerator(conf, watcher, stubArticleStore{}, slog.Default())
+
+		changed, err := gen.IssuesChanged(context.Background(), "testuser", "testrepo")
+		require.NoError(t, err)
+		assert.False(t, changed)
+		require.Len(t, watcher.queries, 1)
+		assertEqualCmp(t, []string{"blog"}, watcher.queries[0].Labels)
+	})
+
+	t.Run("reports a change when the store cannot tell", func(t *testing.T) {
+		gen := newArticleGenerator(conf, &stubIssueStore{}, stubArticleStore{}, slog.Default())
+
+		changed, err := gen.IssuesChanged(context.Background(), "testuser", "testrepo")
+		require.NoError(t, err)
+		assert.True(t, changed)
+	})
+}